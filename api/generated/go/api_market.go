@@ -53,18 +53,42 @@ func (c *MarketApiController) Routes() Routes {
 			"/api/v1/trades",
 			c.ApiV1TradesGet,
 		},
+		{
+			"ApiV3AggTradesGet",
+			strings.ToUpper("Get"),
+			"/api/v3/aggTrades",
+			c.ApiV3AggTradesGet,
+		},
 		{
 			"ApiV3AvgPriceGet",
 			strings.ToUpper("Get"),
 			"/api/v3/avgPrice",
 			c.ApiV3AvgPriceGet,
 		},
+		{
+			"ApiV3HistoricalTradesGet",
+			strings.ToUpper("Get"),
+			"/api/v3/historicalTrades",
+			c.ApiV3HistoricalTradesGet,
+		},
+		{
+			"ApiV3KlinesGet",
+			strings.ToUpper("Get"),
+			"/api/v3/klines",
+			c.ApiV3KlinesGet,
+		},
 		{
 			"ApiV3Ticker24hrGet",
 			strings.ToUpper("Get"),
 			"/api/v3/ticker/24hr",
 			c.ApiV3Ticker24hrGet,
 		},
+		{
+			"ApiV3TickerGet",
+			strings.ToUpper("Get"),
+			"/api/v3/ticker",
+			c.ApiV3TickerGet,
+		},
 		{
 			"V1TradingStats24hAllGet",
 			strings.ToUpper("Get"),
@@ -94,6 +118,38 @@ func (c *MarketApiController) ApiV1TradesGet(w http.ResponseWriter, r *http.Requ
 
 }
 
+// ApiV3AggTradesGet - Compressed/Aggregate trades list
+func (c *MarketApiController) ApiV3AggTradesGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbolParam := query.Get("symbol")
+	limitParam, err := parseInt32Parameter(query.Get("limit"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	fromIdParam := query.Get("fromId")
+	startTimeParam, err := parseInt64Parameter(query.Get("startTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	endTimeParam, err := parseInt64Parameter(query.Get("endTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	cursorParam := query.Get("cursor")
+	result, err := c.service.ApiV3AggTradesGet(r.Context(), symbolParam, limitParam, fromIdParam, startTimeParam, endTimeParam, cursorParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w, r)
+
+}
+
 // ApiV3AvgPriceGet - Current average price for a symbol.
 func (c *MarketApiController) ApiV3AvgPriceGet(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
@@ -109,6 +165,69 @@ func (c *MarketApiController) ApiV3AvgPriceGet(w http.ResponseWriter, r *http.Re
 
 }
 
+// ApiV3HistoricalTradesGet - Old trade lookup
+func (c *MarketApiController) ApiV3HistoricalTradesGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbolParam := query.Get("symbol")
+	limitParam, err := parseInt32Parameter(query.Get("limit"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	fromIdParam := query.Get("fromId")
+	startTimeParam, err := parseInt64Parameter(query.Get("startTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	endTimeParam, err := parseInt64Parameter(query.Get("endTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	cursorParam := query.Get("cursor")
+	result, err := c.service.ApiV3HistoricalTradesGet(r.Context(), symbolParam, limitParam, fromIdParam, startTimeParam, endTimeParam, cursorParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w, r)
+
+}
+
+// ApiV3KlinesGet - Kline/Candlestick data
+func (c *MarketApiController) ApiV3KlinesGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbolParam := query.Get("symbol")
+	intervalParam := query.Get("interval")
+	startTimeParam, err := parseInt64Parameter(query.Get("startTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	endTimeParam, err := parseInt64Parameter(query.Get("endTime"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	limitParam, err := parseInt32Parameter(query.Get("limit"), false)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	result, err := c.service.ApiV3KlinesGet(r.Context(), symbolParam, intervalParam, startTimeParam, endTimeParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w, r)
+
+}
+
 // ApiV3Ticker24hrGet - 24hr Ticker Price Change Statistics
 func (c *MarketApiController) ApiV3Ticker24hrGet(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
@@ -124,6 +243,22 @@ func (c *MarketApiController) ApiV3Ticker24hrGet(w http.ResponseWriter, r *http.
 
 }
 
+// ApiV3TickerGet - Rolling window price change statistics
+func (c *MarketApiController) ApiV3TickerGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbolParam := query.Get("symbol")
+	windowSizeParam := query.Get("windowSize")
+	result, err := c.service.ApiV3TickerGet(r.Context(), symbolParam, windowSizeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w, r)
+
+}
+
 // V1TradingStats24hAllGet - 24hr Ticker Price Change Statistics With Market Info
 func (c *MarketApiController) V1TradingStats24hAllGet(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
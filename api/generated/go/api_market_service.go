@@ -11,9 +11,12 @@ package openapi
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"bitbucket.org/novatechnologies/ohlcv/internal/service"
 
@@ -28,12 +31,34 @@ import (
 // Include any external packages or services that will be required by this service.
 type MarketApiService struct {
 	dealService  *service.Deal
-	marketClient market.Client
+	klineService *service.Kline
+	markets      *domain.MarketRegistry
 }
 
 // NewMarketApiService creates a default api service
-func NewMarketApiService(dealService *service.Deal, marketClient market.Client) MarketApiServicer {
-	return &MarketApiService{dealService: dealService, marketClient: marketClient}
+func NewMarketApiService(
+	dealService *service.Deal,
+	klineService *service.Kline,
+	markets *domain.MarketRegistry,
+) MarketApiServicer {
+	return &MarketApiService{
+		dealService:  dealService,
+		klineService: klineService,
+		markets:      markets,
+	}
+}
+
+// resolveSymbol returns the market name of the symbol in any accepted format,
+// an empty symbol stays empty.
+func (s *MarketApiService) resolveSymbol(symbol string) (string, error) {
+	if strings.TrimSpace(symbol) == "" {
+		return "", nil
+	}
+	name, ok := s.markets.ResolveName(symbol)
+	if !ok {
+		return "", fmt.Errorf("invalid symbol %s", symbol)
+	}
+	return name, nil
 }
 
 // ApiV1TradesGet - Recent Trades List
@@ -45,16 +70,153 @@ func (s *MarketApiService) ApiV1TradesGet(
 	if strings.TrimSpace(symbol) == "" || limit <= 0 || limit >= 1000 {
 		return Response(400, RespError{}), nil
 	}
+	symbol, err := s.resolveSymbol(symbol)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
 
 	trades, err := s.dealService.GetLastTrades(ctx, symbol, limit)
 	if err != nil {
 		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetLastTrades error")
 		return Response(500, RespError{Msg: err.Error()}), nil
 	}
-	return Response(200, convertDeals(trades)), nil
+	result, err := convertDeals(trades)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("convertDeals error")
+		return Response(500, RespError{}), nil
+	}
+	return Response(200, result), nil
+}
+
+// ApiV3HistoricalTradesGet - Old trade lookup
+func (s *MarketApiService) ApiV3HistoricalTradesGet(
+	ctx context.Context,
+	symbol string,
+	limit int32,
+	fromId string,
+	startTime int64,
+	endTime int64,
+	cursor string,
+) (ImplResponse, error) {
+	symbol, err := s.resolveSymbol(symbol)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	q, err := makeTradesQuery(symbol, limit, fromId, startTime, endTime, cursor)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	trades, err := s.dealService.GetTrades(ctx, q, fromId)
+	if errors.Is(err, service.ErrTradeNotFound) {
+		return Response(404, RespError{Msg: err.Error()}), nil
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetTrades error")
+		return Response(500, RespError{}), nil
+	}
+	result, err := convertDeals(trades)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("convertDeals error")
+		return Response(500, RespError{}), nil
+	}
+	page := TradesPage{Trades: result, NextCursor: cursor}
+	if len(trades) > 0 {
+		page.NextCursor = trades[len(trades)-1].Cursor().String()
+	}
+	return Response(200, page), nil
+}
+
+// ApiV3AggTradesGet - Compressed/Aggregate trades list
+func (s *MarketApiService) ApiV3AggTradesGet(
+	ctx context.Context,
+	symbol string,
+	limit int32,
+	fromId string,
+	startTime int64,
+	endTime int64,
+	cursor string,
+) (ImplResponse, error) {
+	symbol, err := s.resolveSymbol(symbol)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	q, err := makeTradesQuery(symbol, limit, fromId, startTime, endTime, cursor)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	trades, err := s.dealService.GetAggTrades(ctx, q, fromId)
+	if errors.Is(err, service.ErrTradeNotFound) {
+		return Response(404, RespError{Msg: err.Error()}), nil
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetAggTrades error")
+		return Response(500, RespError{}), nil
+	}
+	page := AggTradesPage{Trades: make([]AggTrade, len(trades)), NextCursor: cursor}
+	for i, t := range trades {
+		page.Trades[i] = AggTrade{
+			A: t.FirstId,
+			P: t.Price.String(),
+			Q: decimal128.String(t.Qty),
+			F: t.FirstId,
+			L: t.LastId,
+			T: t.FirstTime.UnixMilli(),
+			M: t.IsBuyerMaker,
+		}
+	}
+	if len(trades) > 0 {
+		page.NextCursor = trades[len(trades)-1].Last.String()
+	}
+	return Response(200, page), nil
+}
+
+// makeTradesQuery validates the trades request parameters, fromId is resolved
+// by the deal service.
+func makeTradesQuery(
+	symbol string,
+	limit int32,
+	fromId string,
+	startTime int64,
+	endTime int64,
+	cursor string,
+) (model.TradesQuery, error) {
+	if strings.TrimSpace(symbol) == "" {
+		return model.TradesQuery{}, errors.New("specify symbol")
+	}
+	if limit == 0 {
+		limit = 500
+	}
+	if limit < 0 || limit > 1000 {
+		return model.TradesQuery{}, errors.New("limit should be in 1..1000")
+	}
+	if startTime < 0 || endTime < 0 || (endTime > 0 && startTime > endTime) {
+		return model.TradesQuery{}, errors.New("invalid time range")
+	}
+	if fromId != "" && cursor != "" {
+		return model.TradesQuery{}, errors.New("fromId and cursor can't be used together")
+	}
+	q := model.TradesQuery{Market: symbol, Limit: int64(limit)}
+	if startTime > 0 {
+		q.StartTime = time.UnixMilli(startTime)
+	}
+	if endTime > 0 {
+		q.EndTime = time.UnixMilli(endTime)
+	}
+	if cursor != "" {
+		after, err := model.ParseTradesCursor(cursor)
+		if err != nil {
+			return model.TradesQuery{}, err
+		}
+		q.After = &after
+	}
+	return q, nil
 }
 
 func (s *MarketApiService) ApiV3Ticker24hrGet(ctx context.Context, market string) (ImplResponse, error) {
+	market, err := s.resolveSymbol(market)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
 	statistics, err := s.dealService.GetTickerPriceChangeStatistics(ctx, market)
 	if err != nil {
 		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetTickerPriceChangeStatistics error")
@@ -63,6 +225,27 @@ func (s *MarketApiService) ApiV3Ticker24hrGet(ctx context.Context, market string
 	return Response(200, convertStatistics(statistics)), nil
 }
 
+// ApiV3TickerGet - Rolling window price change statistics
+func (s *MarketApiService) ApiV3TickerGet(ctx context.Context, market string, windowSize string) (ImplResponse, error) {
+	if windowSize == "" {
+		windowSize = "1d"
+	}
+	window, err := domain.ParseTickerWindow(windowSize)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	market, err = s.resolveSymbol(market)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	statistics, err := s.klineService.GetWindowTicker(ctx, market, window)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetWindowTicker error")
+		return Response(500, RespError{}), nil
+	}
+	return Response(200, convertStatistics(statistics)), nil
+}
+
 func convertStatistics(statistics []*domain.TickerPriceChangeStatistics) []Ticker {
 	tickers := make([]Ticker, len(statistics))
 	for i, s := range statistics {
@@ -91,31 +274,36 @@ func convertStatistics(statistics []*domain.TickerPriceChangeStatistics) []Ticke
 	return tickers
 }
 
-func convertDeals(tr []*model.Deal) []*Trade {
+func convertDeals(tr []*model.Deal) ([]*Trade, error) {
 	trades := make([]*Trade, len(tr))
 	for i := range tr {
+		quoteQty, err := tr[i].QuoteQty()
+		if err != nil {
+			return nil, err
+		}
 		trades[i] = &Trade{
 			Id:           tr[i].Data.DealId,
 			Price:        tr[i].Data.Price.String(),
 			Qty:          tr[i].Data.Volume.String(),
-			QuoteQty:     tr[i].Data.Volume.String(),
+			QuoteQty:     decimal128.String(quoteQty),
 			Time:         tr[i].T.Time().UnixMilli(),
 			IsBuyerMaker: tr[i].Data.IsBuyerMaker,
 		}
 	}
-	return trades
+	return trades, nil
 }
 
 func (s *MarketApiService) V1TradingStats24hAllGet(ctx context.Context, market string) (ImplResponse, error) {
+	market, err := s.resolveSymbol(market)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
 	statistics, err := s.dealService.GetTickerPriceChangeStatistics(ctx, market)
 	if err != nil {
 		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetTickerPriceChangeStatistics error")
 		return Response(500, RespError{}), nil
 	}
-	markets, err := s.marketClient.List(ctx)
-	if err != nil {
-		return Response(500, RespError{Msg: "marketClient.List:" + err.Error()}), nil
-	}
+	markets := s.markets.Markets()
 
 	return Response(
 		200,
@@ -169,6 +357,10 @@ func (s *MarketApiService) ApiV3AvgPriceGet(ctx context.Context, market string)
 	if strings.TrimSpace(market) == "" {
 		return Response(400, RespError{Msg: "specify symbol"}), nil
 	}
+	market, err := s.resolveSymbol(market)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
 	price, err := s.dealService.GetAvgPrice(ctx, time.Hour*24, market)
 	if err != nil {
 		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("ApiV3AvgPriceGet error")
@@ -176,3 +368,73 @@ func (s *MarketApiService) ApiV3AvgPriceGet(ctx context.Context, market string)
 	}
 	return Response(200, price), nil
 }
+
+// ApiV3KlinesGet - Kline/Candlestick data
+func (s *MarketApiService) ApiV3KlinesGet(
+	ctx context.Context,
+	market string,
+	interval string,
+	startTime int64,
+	endTime int64,
+	limit int32,
+) (ImplResponse, error) {
+	if strings.TrimSpace(market) == "" {
+		return Response(400, RespError{Msg: "specify symbol"}), nil
+	}
+	market, err := s.resolveSymbol(market)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	resolution, err := domain.ParseKlineInterval(interval)
+	if err != nil {
+		return Response(400, RespError{Msg: err.Error()}), nil
+	}
+	if limit == 0 {
+		limit = domain.KlinesDefaultLimit
+	}
+	if limit < 0 || limit > domain.KlinesMaxLimit {
+		return Response(400, RespError{Msg: "limit should be in 1..1000"}), nil
+	}
+	if startTime < 0 || endTime < 0 || (endTime > 0 && startTime > endTime) {
+		return Response(400, RespError{Msg: "invalid time range"}), nil
+	}
+	var start, end time.Time
+	if startTime > 0 {
+		start = time.UnixMilli(startTime)
+	}
+	if endTime > 0 {
+		end = time.UnixMilli(endTime)
+	}
+	klines, err := s.klineService.GetKlines(ctx, market, resolution, start, end, int(limit))
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err.Error()).Errorf("GetKlines error")
+		return Response(500, RespError{}), nil
+	}
+	result := make([]Kline, len(klines))
+	for i, k := range klines {
+		takerBuyAssets, err := k.TakerBuyAssets()
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err.Error()).Errorf("TakerBuyAssets error")
+			return Response(500, RespError{}), nil
+		}
+		takerBuyQuotes, err := k.TakerBuyQuotes()
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err.Error()).Errorf("TakerBuyQuotes error")
+			return Response(500, RespError{}), nil
+		}
+		result[i] = Kline{
+			OpenTime:                 k.OpenTime.UnixMilli(),
+			Open:                     decimal128.String(k.Open),
+			High:                     decimal128.String(k.High),
+			Low:                      decimal128.String(k.Low),
+			Close:                    decimal128.String(k.Close),
+			Volume:                   decimal128.String(k.Volume),
+			CloseTime:                k.CloseTime.UnixMilli() - 1,
+			QuoteAssetVolume:         decimal128.String(k.Quotes),
+			NumberOfTrades:           k.Trades,
+			TakerBuyBaseAssetVolume:  decimal128.String(takerBuyAssets),
+			TakerBuyQuoteAssetVolume: decimal128.String(takerBuyQuotes),
+		}
+	}
+	return Response(200, result), nil
+}
@@ -0,0 +1,53 @@
+/*
+ * PointPay.io Public Spot API (draft)
+ *
+ * OpenAPI Specifications for the PointPay.io Public Spot API
+ *
+ * API version: 0.1.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AggTrade struct {
+	// Aggregate tradeId, as same as the first tradeId
+	A string `json:"a"`
+
+	// Price
+	P string `json:"p"`
+
+	// Quantity
+	Q string `json:"q"`
+
+	// First tradeId
+	F string `json:"f"`
+
+	// Last tradeId
+	L string `json:"l"`
+
+	// Timestamp of the first trade
+	T int64 `json:"T"`
+
+	// Was the buyer the maker?
+	M bool `json:"m"`
+
+	// Was the trade the best price match?
+	BestMatch bool `json:"M"`
+}
+
+// AssertAggTradeRequired checks if the required fields are not zero-ed
+func AssertAggTradeRequired(obj AggTrade) error {
+	return nil
+}
+
+// AssertRecurseAggTradeRequired recursively checks if required fields are not zero-ed in a nested slice.
+// Accepts only nested slice of AggTrade (e.g. [][]AggTrade), otherwise ErrTypeAssertionError is thrown.
+func AssertRecurseAggTradeRequired(objSlice interface{}) error {
+	return AssertRecurseInterfaceRequired(objSlice, func(obj interface{}) error {
+		aAggTrade, ok := obj.(AggTrade)
+		if !ok {
+			return ErrTypeAssertionError
+		}
+		return AssertAggTradeRequired(aAggTrade)
+	})
+}
@@ -0,0 +1,39 @@
+/*
+ * PointPay.io Public Spot API (draft)
+ *
+ * OpenAPI Specifications for the PointPay.io Public Spot API
+ *
+ * API version: 0.1.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AggTradesPage struct {
+	Trades []AggTrade `json:"trades"`
+
+	// Cursor to get the trades after the page, as same as the request one if the page is empty
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// AssertAggTradesPageRequired checks if the required fields are not zero-ed
+func AssertAggTradesPageRequired(obj AggTradesPage) error {
+	for _, el := range obj.Trades {
+		if err := AssertAggTradeRequired(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertRecurseAggTradesPageRequired recursively checks if required fields are not zero-ed in a nested slice.
+// Accepts only nested slice of AggTradesPage (e.g. [][]AggTradesPage), otherwise ErrTypeAssertionError is thrown.
+func AssertRecurseAggTradesPageRequired(objSlice interface{}) error {
+	return AssertRecurseInterfaceRequired(objSlice, func(obj interface{}) error {
+		aAggTradesPage, ok := obj.(AggTradesPage)
+		if !ok {
+			return ErrTypeAssertionError
+		}
+		return AssertAggTradesPageRequired(aAggTradesPage)
+	})
+}
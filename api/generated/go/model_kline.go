@@ -0,0 +1,72 @@
+/*
+ * PointPay.io Public Spot API (draft)
+ *
+ * OpenAPI Specifications for the PointPay.io Public Spot API
+ *
+ * API version: 0.1.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import "encoding/json"
+
+// Kline is encoded as a Binance kline array.
+type Kline struct {
+	OpenTime int64
+
+	Open string
+
+	High string
+
+	Low string
+
+	Close string
+
+	Volume string
+
+	CloseTime int64
+
+	QuoteAssetVolume string
+
+	NumberOfTrades int
+
+	TakerBuyBaseAssetVolume string
+
+	TakerBuyQuoteAssetVolume string
+}
+
+func (k Kline) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{
+		k.OpenTime,
+		k.Open,
+		k.High,
+		k.Low,
+		k.Close,
+		k.Volume,
+		k.CloseTime,
+		k.QuoteAssetVolume,
+		k.NumberOfTrades,
+		k.TakerBuyBaseAssetVolume,
+		k.TakerBuyQuoteAssetVolume,
+		// the unused field
+		"0",
+	})
+}
+
+// AssertKlineRequired checks if the required fields are not zero-ed
+func AssertKlineRequired(obj Kline) error {
+	return nil
+}
+
+// AssertRecurseKlineRequired recursively checks if required fields are not zero-ed in a nested slice.
+// Accepts only nested slice of Kline (e.g. [][]Kline), otherwise ErrTypeAssertionError is thrown.
+func AssertRecurseKlineRequired(objSlice interface{}) error {
+	return AssertRecurseInterfaceRequired(objSlice, func(obj interface{}) error {
+		aKline, ok := obj.(Kline)
+		if !ok {
+			return ErrTypeAssertionError
+		}
+		return AssertKlineRequired(aKline)
+	})
+}
@@ -0,0 +1,39 @@
+/*
+ * PointPay.io Public Spot API (draft)
+ *
+ * OpenAPI Specifications for the PointPay.io Public Spot API
+ *
+ * API version: 0.1.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TradesPage struct {
+	Trades []*Trade `json:"trades"`
+
+	// Cursor to get the trades after the page, as same as the request one if the page is empty
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// AssertTradesPageRequired checks if the required fields are not zero-ed
+func AssertTradesPageRequired(obj TradesPage) error {
+	for _, el := range obj.Trades {
+		if err := AssertTradeRequired(*el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertRecurseTradesPageRequired recursively checks if required fields are not zero-ed in a nested slice.
+// Accepts only nested slice of TradesPage (e.g. [][]TradesPage), otherwise ErrTypeAssertionError is thrown.
+func AssertRecurseTradesPageRequired(objSlice interface{}) error {
+	return AssertRecurseInterfaceRequired(objSlice, func(obj interface{}) error {
+		aTradesPage, ok := obj.(TradesPage)
+		if !ok {
+			return ErrTypeAssertionError
+		}
+		return AssertTradesPageRequired(aTradesPage)
+	})
+}
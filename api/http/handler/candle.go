@@ -15,14 +15,15 @@ import (
 
 type CandleHandler struct {
 	CandleService *candle.Service
+	Markets       *domain.MarketRegistry
 }
 
 const defaultDuration = 1 * time.Minute
 
 const defaultBarsCount = 32
 
-func NewCandleHandler(candleService *candle.Service) *CandleHandler {
-	return &CandleHandler{candleService}
+func NewCandleHandler(candleService *candle.Service, markets *domain.MarketRegistry) *CandleHandler {
+	return &CandleHandler{candleService, markets}
 }
 
 func (h CandleHandler) GetCandleChart(
@@ -38,12 +39,18 @@ func (h CandleHandler) GetCandleChart(
 
 	ctx := req.Context()
 
-	market := domain.NormalizeMarketName(req.URL.Query().Get("market"))
-	if len(market) == 0 {
+	symbol := req.URL.Query().Get("market")
+	if len(symbol) == 0 {
 		http.Error(res, "market is required", http.StatusBadRequest)
 
 		return
 	}
+	market, ok := h.Markets.ResolveName(symbol)
+	if !ok {
+		http.Error(res, "invalid market value", http.StatusBadRequest)
+
+		return
+	}
 
 	interval := req.URL.Query().Get("interval")
 	resolution := model.Resolution(strings.ToUpper(interval))
@@ -83,7 +90,17 @@ func (h CandleHandler) GetCandleChart(
 		resolution,
 	)
 
-	chart := h.CandleService.GetChart(ctx, market, resolution, from, to)
+	opts := domain.ChartOptions{}
+	if decimals := req.URL.Query().Get("decimals"); decimals != "" {
+		if decimals != "string" && decimals != "number" {
+			http.Error(res, "invalid decimals value", http.StatusBadRequest)
+
+			return
+		}
+		opts.StringDecimals = decimals == "string"
+	}
+
+	chart := h.CandleService.GetChart(ctx, market, resolution, from, to, opts)
 
 	bytes, err := json.Marshal(chart)
 	if err != nil {
@@ -7,9 +7,14 @@ import (
 	"net"
 	"net/http"
 
-	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
 	"bitbucket.org/novatechnologies/ohlcv/infra"
+	"bitbucket.org/novatechnologies/ohlcv/infra/health"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	gorillamux "github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 
 	openapi "bitbucket.org/novatechnologies/ohlcv/api/generated/go"
 	"bitbucket.org/novatechnologies/ohlcv/api/http/handler"
@@ -20,30 +25,62 @@ type Server struct {
 	srv http.Server
 }
 
-func NewServer(candleService *candle.Service, dealService *service.Deal, conf infra.Config) *Server {
-	mux := http.NewServeMux()
-
-	marketClient, err := market.New(
-		market.Config{ServerURL: conf.ExchangeMarketsServerURL, ServerTLS: conf.ExchangeMarketsServerSSL},
-		market.NewErrorProcessor(map[string]string{}),
-		map[interface{}]market.Option{},
-		conf.ExchangeMarketsToken,
-	)
-	if err != nil {
-		log.Fatal("can't market.New:" + err.Error())
-	}
+func NewServer(
+	candleService *candle.Service,
+	dealService *service.Deal,
+	klineService *service.Kline,
+	markets *domain.MarketRegistry,
+	checker *health.Checker,
+	conf infra.Config,
+) *Server {
+	mux := newOpsMux(checker)
 
-	candleHandler := handler.NewCandleHandler(candleService)
-	MarketApiService := openapi.NewMarketApiService(dealService, market.NewCache(marketClient))
+	candleHandler := handler.NewCandleHandler(candleService, markets)
+	MarketApiService := openapi.NewMarketApiService(dealService, klineService, markets)
 	MarketApiController := openapi.NewMarketApiController(MarketApiService)
 
 	router := openapi.NewRouter(MarketApiController)
+	router.Use(metrics.HTTPMiddleware(routeName), nameSpan)
 	mux.Handle("/", router)
-	mux.HandleFunc("/api/candles", candleHandler.GetCandleChart)
+	mux.Handle("/api/candles", metrics.HTTPHandler("GetCandleChart", http.HandlerFunc(candleHandler.GetCandleChart)))
+
+	return newServer(mux, conf)
+}
+
+// NewOpsServer serves only the probes and the metrics, it's
+// the server of the instances without the API.
+func NewOpsServer(checker *health.Checker, conf infra.Config) *Server {
+	return newServer(newOpsMux(checker), conf)
+}
+
+func newOpsMux(checker *health.Checker) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", health.ReadinessHandler(checker))
+	return mux
+}
+
+func newServer(mux *http.ServeMux, conf infra.Config) *Server {
+	tracedMux := otelhttp.NewHandler(
+		mux,
+		"http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.URL.Path
+		}),
+		// the probes and the scrapes aren't traced
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			switch r.URL.Path {
+			case "/healthz", "/readyz", "/metrics":
+				return false
+			}
+			return true
+		}),
+	)
 
 	srv := http.Server{
 		Addr:    fmt.Sprintf(":%d", conf.HttpConfig.Port),
-		Handler: mux,
+		Handler: tracedMux,
 	}
 
 	serv := &Server{
@@ -53,20 +90,49 @@ func NewServer(candleService *candle.Service, dealService *service.Deal, conf in
 	return serv
 }
 
-func (s *Server) Start(ctx context.Context) {
+// Start listens on the port and serves the requests in the background.
+func (s *Server) Start(ctx context.Context) error {
 	s.srv.BaseContext = func(listener net.Listener) context.Context {
 		return ctx
 	}
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
 	go func() {
 		log.Info("[*] Http server is started")
-		for err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed; {
-			log.Info(err)
+		if err := s.srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error(err)
 		}
 	}()
+	return nil
 }
 
-func (s *Server) Stop(ctx context.Context) {
+// Stop waits for the active requests within the context, the unfinished
+// ones are cut off then.
+func (s *Server) Stop(ctx context.Context) error {
 	if err := s.srv.Shutdown(ctx); err != nil {
 		log.Info("shutdown")
+		_ = s.srv.Close()
+		return err
 	}
+	return nil
+}
+
+// routeName is the name of the matched route, the endpoint label of the
+// metrics.
+func routeName(r *http.Request) string {
+	if route := gorillamux.CurrentRoute(r); route != nil {
+		return route.GetName()
+	}
+	return "unknown"
+}
+
+// nameSpan names the request span after the matched route, the spans of the
+// other requests are named after the path.
+func nameSpan(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace.SpanFromContext(r.Context()).SetName(routeName(r))
+		next.ServeHTTP(w, r)
+	})
 }
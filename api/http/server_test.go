@@ -7,8 +7,8 @@ import (
 
 	"bitbucket.org/novatechnologies/ohlcv/infra"
 	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
+	"bitbucket.org/novatechnologies/ohlcv/infra/health"
 	"bitbucket.org/novatechnologies/ohlcv/infra/mongo"
-	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
 	"bitbucket.org/novatechnologies/ohlcv/tests"
 )
 
@@ -26,14 +26,16 @@ func Test_Server_manual(t *testing.T) {
 		conf.MongoDbConfig.DealCollectionName,
 	)
 
-	dealService := repository.NewDeal(dealCollection, tests.GetAvailableMarkets(), nil)
+	markets := tests.GetMarketRegistry()
+	dealService := tests.InitDealService(dealCollection, markets)
 	candleService := tests.InitCandleService(conf, dealCollection, eventsBroker)
+	klineService := tests.InitKlineService(ctx, conf, mongoDbClient, dealCollection, markets)
 
-	server := NewServer(candleService, dealService, conf)
+	server := NewServer(candleService, dealService, klineService, markets, health.NewChecker(conf.HealthConfig.CheckTimeout), conf)
 	server.Start(ctx)
 
 	// shutdown
-	signalCh := make(chan os.Signal)
+	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt)
 
 	_ = <-signalCh
@@ -6,10 +6,10 @@ import (
 	"time"
 
 	"bitbucket.org/novatechnologies/common/infra/logger"
-	"github.com/shopspring/decimal"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 )
 
 type Aggregator struct{}
@@ -129,18 +129,9 @@ func (s Aggregator) compare(
 		comparedCandle.Close = c.Close
 	}
 
-	cHight, _ := compareDecimal128(c.High, candle.High)
-	if cHight == -1 {
-		comparedCandle.High = candle.High
-	}
-	cLow, _ := compareDecimal128(c.Low, candle.Low)
-	if cLow == 1 {
-		comparedCandle.Low = candle.Low
-	}
-	dv1, _ := decimal.NewFromString(c.Volume.String())
-	dv2, _ := decimal.NewFromString(candle.Volume.String())
-	resultVolume, _ := primitive.ParseDecimal128(dv1.Add(dv2).String())
-	comparedCandle.Volume = resultVolume
+	comparedCandle.High, _ = decimal128.Max(c.High, candle.High)
+	comparedCandle.Low, _ = decimal128.Min(c.Low, candle.Low)
+	comparedCandle.Volume, _ = decimal128.Add(c.Volume, candle.Volume)
 	comparedCandle.OpenTime = candle.OpenTime
 
 	return comparedCandle
@@ -263,59 +254,6 @@ func (s *Aggregator) GenerateChart(result map[int64]*domain.Candle) *domain.Char
 	return chart
 }
 
-func compareDecimal128(d1, d2 primitive.Decimal128) (int, error) {
-	b1, exp1, err := d1.BigInt()
-	if err != nil {
-		return 0, err
-	}
-	b2, exp2, err := d2.BigInt()
-	if err != nil {
-		return 0, err
-	}
-
-	sign := b1.Sign()
-	if sign != b2.Sign() {
-		if b1.Sign() > 0 {
-			return 1, nil
-		} else {
-			return -1, nil
-		}
-	}
-
-	if exp1 == exp2 {
-		return b1.Cmp(b2), nil
-	}
-
-	if sign < 0 {
-		if exp1 < exp2 {
-			return 1, nil
-		}
-		return -1, nil
-	} else {
-		if exp1 < exp2 {
-			return -1, nil
-		}
-
-		return 1, nil
-	}
-}
-
-func addPrimitiveDecimal128(a, b primitive.Decimal128) (primitive.Decimal128, error) {
-	ad, err := decimal.NewFromString(a.String())
-	if err != nil {
-		return primitive.Decimal128{}, err
-	}
-	bd, err := decimal.NewFromString(b.String())
-	if err != nil {
-		return primitive.Decimal128{}, err
-	}
-	result, err := primitive.ParseDecimal128(ad.Add(bd).String())
-	if err != nil {
-		return primitive.Decimal128{}, err
-	}
-	return result, nil
-}
-
 func (s *Aggregator) GetResolutionStartTimestampByTime(resolution model.Resolution, time time.Time) int64 {
 	var ts int64
 	switch resolution {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
@@ -26,6 +27,18 @@ func TestService_AggregateCandleToChartByResolution(t *testing.T) {
 	})
 }
 
+func TestAggregator_compare(t *testing.T) {
+	first := generateCandle("0.00001", "0.000015", "0.000009", "0.000012", "100000000000000000.1", 60)
+	second := generateCandle("0.000012", "0.000014", "0.0000085", "0.000013", "0.2", 120)
+
+	c := Aggregator{}.compare(first, second)
+	assert.Equal(t, "0.00001", c.Open.String())
+	assert.Equal(t, "0.000013", c.Close.String())
+	assert.Equal(t, "0.000015", c.High.String())
+	assert.Equal(t, "0.0000085", c.Low.String())
+	assert.Equal(t, "100000000000000000.3", c.Volume.String())
+}
+
 func getCandles() []*domain.Candle {
 	return []*domain.Candle{
 		generateCandle("58,345", "58,615", "58,205", "58,245", "600", 165088495115),
@@ -149,17 +162,28 @@ func Test_compareDecimal128(t *testing.T) {
 				return err == nil
 			},
 		},
-		//{ TODO fix bug that fails this test
-		//	name: "first bigger both negative",
-		//	args: args{
-		//		d1: mustParseDecimal128(t, "-4"),
-		//		d2: mustParseDecimal128(t, "-130.6543"),
-		//	},
-		//	want: 1,
-		//	wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-		//		return err == nil
-		//	},
-		//},
+		{
+			name: "first bigger both negative",
+			args: args{
+				d1: mustParseDecimal128(t, "-4"),
+				d2: mustParseDecimal128(t, "-130.6543"),
+			},
+			want: 1,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return err == nil
+			},
+		},
+		{
+			name: "second bigger with bigger exponent",
+			args: args{
+				d1: mustParseDecimal128(t, "10E0"),
+				d2: mustParseDecimal128(t, "2E1"),
+			},
+			want: -1,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return err == nil
+			},
+		},
 		{
 			name: "second bigger",
 			args: args{
@@ -218,11 +242,11 @@ func Test_compareDecimal128(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := compareDecimal128(tt.args.d1, tt.args.d2)
-			if !tt.wantErr(t, err, fmt.Sprintf("compareDecimal128(%v, %v)", tt.args.d1, tt.args.d2)) {
+			got, err := decimal128.Compare(tt.args.d1, tt.args.d2)
+			if !tt.wantErr(t, err, fmt.Sprintf("decimal128.Compare(%v, %v)", tt.args.d1, tt.args.d2)) {
 				return
 			}
-			assert.Equalf(t, tt.want, got, "compareDecimal128(%v, %v)", tt.args.d1, tt.args.d2)
+			assert.Equalf(t, tt.want, got, "decimal128.Compare(%v, %v)", tt.args.d1, tt.args.d2)
 		})
 	}
 }
@@ -285,11 +309,11 @@ func Test_addPrimitiveDecimal128(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := addPrimitiveDecimal128(tt.args.a, tt.args.b)
-			if !tt.wantErr(t, err, fmt.Sprintf("addPrimitiveDecimal128(%v, %v)", tt.args.a, tt.args.b)) {
+			got, err := decimal128.Add(tt.args.a, tt.args.b)
+			if !tt.wantErr(t, err, fmt.Sprintf("decimal128.Add(%v, %v)", tt.args.a, tt.args.b)) {
 				return
 			}
-			assert.Equalf(t, tt.want, got, "addPrimitiveDecimal128(%v, %v)", tt.args.a, tt.args.b)
+			assert.Equalf(t, tt.want, got, "decimal128.Add(%v, %v)", tt.args.a, tt.args.b)
 		})
 	}
 }
@@ -28,12 +28,33 @@ func (s Service) GetCurrentCandle(
 	ctx context.Context,
 	market string,
 	resolution model.Resolution,
+) (*domain.Chart, error) {
+	now := time.Now()
+	return s.currentCandle(ctx, market, resolution, now, now)
+}
+
+// GetCurrentCandleUntil returns the candle of the current resolution period
+// aggregated from the deals made until to, it has no deals if to is before
+// the period.
+func (s Service) GetCurrentCandleUntil(
+	ctx context.Context,
+	market string,
+	resolution model.Resolution,
+	to time.Time,
+) (*domain.Chart, error) {
+	return s.currentCandle(ctx, market, resolution, time.Now(), to)
+}
+
+func (s Service) currentCandle(
+	ctx context.Context,
+	market string,
+	resolution model.Resolution,
+	now, to time.Time,
 ) (*domain.Chart, error) {
 	from := time.Unix(
-		s.Aggregator.GetResolutionStartTimestampByTime(resolution, time.Now()),
+		s.Aggregator.GetResolutionStartTimestampByTime(resolution, now),
 		0,
 	)
-	to := time.Now()
 
 	chart := s.GetCandleByResolution(ctx, market, resolution, from, to)
 
@@ -118,7 +139,8 @@ func (s *Service) GetChart(
 	resolution model.Resolution,
 	from time.Time,
 	to time.Time,
+	opts domain.ChartOptions,
 ) domain.ChartResponse {
 	chart := s.GetCandleByResolution(ctx, market, resolution, from, to)
-	return domain.MakeChartResponse(market, chart)
+	return domain.MakeChartResponse(market, chart, opts)
 }
@@ -0,0 +1,30 @@
+package candle
+
+import "time"
+
+// Clock tells the time and schedules the close of the current candles.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after the duration.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a call scheduled by the clock.
+type Timer interface {
+	// Stop prevents the call, it returns false if the call is already made
+	// or stopped.
+	Stop() bool
+}
+
+// SystemClock is the wall clock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,75 @@
+package candle
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock makes the scheduled calls when it's set to their time.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+	done  bool
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.done {
+		return false
+	}
+	t.done = true
+	return true
+}
+
+// Set moves the clock to the time, the calls due by then are made in the
+// order of their times with the clock set to each of them.
+func (c *fakeClock) Set(now time.Time) {
+	for {
+		c.mu.Lock()
+		var due *fakeTimer
+		for _, timer := range c.timers {
+			if !timer.done && !timer.at.After(now) && (due == nil || timer.at.Before(due.at)) {
+				due = timer
+			}
+		}
+		if due == nil {
+			c.now = now
+			c.mu.Unlock()
+			return
+		}
+		due.done = true
+		c.now = due.at
+		c.mu.Unlock()
+		due.f()
+	}
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
@@ -10,133 +10,459 @@ import (
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"bitbucket.org/novatechnologies/interfaces/matcher"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
-	"github.com/robfig/cron/v3"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CurrentCandles interface {
-	AddDeal(deal *matcher.Deal) error
+	// AddDeal updates the candles of the deal market, the updates carry the
+	// trace of the context.
+	AddDeal(ctx context.Context, deal *matcher.Deal) error
 	AddCandle(market string, resolution model.Resolution, candle domain.Candle) error
+	// Snapshot returns current candles of the given markets and resolutions,
+	// empty lists mean all of them.
+	Snapshot(markets []string, resolutions []model.Resolution) []domain.Candle
+	// RemoveMarket drops the candles of the market, they're not closed.
+	RemoveMarket(market string)
+	// State returns the candles of the market with the position of its last
+	// applied deal, it returns false if the market has no candles.
+	State(market string) (MarketCandles, bool)
+	// Restore sets the candles of the market, the fresh candles inheriting
+	// the close price replace the candles whose period is over. The deals
+	// made before the restore and covered by the position are skipped, so
+	// the replayed and the redelivered deals aren't applied twice.
+	Restore(market string, state MarketCandles)
 }
 
-var timeNow = func() time.Time {
-	return time.Now()
+// MarketCandles is the state of the current candles of a market.
+type MarketCandles struct {
+	Candles  []domain.Candle    `bson:"candles"`
+	Position model.DealPosition `bson:"position"`
+}
+
+// levels are the canonical resolutions of the current candles from the finest
+// to the coarsest. A deal is added to the finest candle containing it and the
+// coarser candles are derived from its change.
+var levels = [...]model.Resolution{
+	model.Candle1MResolution,
+	model.Candle3MResolution,
+	model.Candle5MResolution,
+	model.Candle15MResolution,
+	model.Candle30MResolution,
+	model.Candle1HResolution,
+	model.Candle2HResolution,
+	model.Candle4HResolution,
+	model.Candle6HResolution,
+	model.Candle12HResolution,
+	model.Candle1DResolution,
+	model.Candle1WResolution,
+	model.Candle1MHResolution,
+}
+
+// levelParents are the indexes of the finer levels whose periods nest into
+// the periods of levels, -1 for the finest one.
+var levelParents = func() []int {
+	parents := map[model.Resolution]model.Resolution{
+		model.Candle3MResolution:  model.Candle1MResolution,
+		model.Candle5MResolution:  model.Candle1MResolution,
+		model.Candle15MResolution: model.Candle5MResolution,
+		model.Candle30MResolution: model.Candle15MResolution,
+		model.Candle1HResolution:  model.Candle30MResolution,
+		model.Candle2HResolution:  model.Candle1HResolution,
+		model.Candle4HResolution:  model.Candle2HResolution,
+		model.Candle6HResolution:  model.Candle2HResolution,
+		model.Candle12HResolution: model.Candle6HResolution,
+		model.Candle1DResolution:  model.Candle12HResolution,
+		model.Candle1WResolution:  model.Candle1DResolution,
+		model.Candle1MHResolution: model.Candle1DResolution,
+	}
+	indexes := make([]int, len(levels))
+	for i, level := range levels {
+		indexes[i] = -1
+		for j := range levels[:i] {
+			if parents[level] == levels[j] {
+				indexes[i] = j
+			}
+		}
+	}
+	return indexes
+}()
+
+// levelIndex maps the resolutions and their aliases to the indexes of levels.
+var levelIndex = func() map[model.Resolution]int {
+	index := make(map[model.Resolution]int, len(levels))
+	for i, level := range levels {
+		for _, alias := range level.Aliases() {
+			index[alias] = i
+		}
+	}
+	return index
+}()
+
+// levelAliases are the resolutions the candles of levels are sent by.
+var levelAliases = func() [][]model.Resolution {
+	aliases := make([][]model.Resolution, len(levels))
+	for i, level := range levels {
+		aliases[i] = level.Aliases()
+	}
+	return aliases
+}()
+
+// marketCandles are the candles of a market by levels, nil for the untracked
+// ones.
+type marketCandles []*domain.Candle
+
+// update is a candle change waiting to be sent.
+type update struct {
+	ctx    context.Context
+	level  int
+	candle domain.Candle
 }
 
 type currentCandles struct {
 	updatesStream chan domain.Candle
 	candlesLock   sync.Mutex
-	candles       map[string]map[model.Resolution]*domain.Candle //market-resolution-Candle, invariant: Candle is always fresh (now in [openTime;closeTime)
+	candles       map[string]marketCandles //market-level-Candle, the aliases share the candle of their level
 	aggregator    Aggregator
 	lgr           logger.Logger
+	// closed is set when updatesStream is closed, the later changes aren't
+	// sent.
+	closed bool
+	// sendLock keeps the order of the updates sent after candlesLock is
+	// released, it's taken before the release.
+	sendLock sync.Mutex
+	// pending are the updates collected under candlesLock.
+	pending []update
+	// positions are the positions of the last applied deals by markets.
+	positions map[string]*model.DealPosition
+	// restoredAt are the times of the last restores by markets, the older
+	// deals covered by the positions are skipped.
+	restoredAt map[string]time.Time
+	clock      Clock
+	// closeAt are the times the periods of the levels are over at, the timer
+	// fires at the earliest one.
+	closeAt [len(levels)]time.Time
+	timer   Timer
 }
 
-func NewCurrentCandles(ctx context.Context, updatesStream chan domain.Candle) CurrentCandles {
+// NewCurrentCandles returns the current candles sending their updates into
+// the stream. Every candle is closed by the clock timer when its period is
+// over. The stream is closed when the context is done, after the running
+// close.
+func NewCurrentCandles(ctx context.Context, updatesStream chan domain.Candle, clock Clock) CurrentCandles {
 	cc := &currentCandles{
 		updatesStream: updatesStream,
-		candles:       map[string]map[model.Resolution]*domain.Candle{},
+		candles:       map[string]marketCandles{},
+		positions:     map[string]*model.DealPosition{},
+		restoredAt:    map[string]time.Time{},
 		aggregator:    Aggregator{},
 		lgr:           logger.FromContext(ctx),
+		clock:         clock,
 	}
+	cc.candlesLock.Lock()
+	cc.schedule(clock.Now())
+	cc.candlesLock.Unlock()
 	go func() {
 		<-ctx.Done()
-		close(cc.updatesStream)
+		cc.close()
 	}()
-	cro := cron.New(cron.WithLocation(time.UTC), cron.WithSeconds())
-	_, err := cro.AddFunc("0 * * * * *", func() {
-		cc.refreshAll()
-	})
-	if err != nil {
-		panic(fmt.Errorf("can't build NewCurrentCandles: '%w'", err))
-	}
-	cro.Start()
 	return cc
 }
 
-func (c *currentCandles) refreshAll() {
+func (c *currentCandles) close() {
 	c.candlesLock.Lock()
 	defer c.candlesLock.Unlock()
-	for market, resolutions := range c.candles {
-		for resolution := range resolutions {
-			oldCandle := c.getSafeCandle(market, resolution)
-			newCandle := c.getFreshCandle(market, resolution)
-			//inherit ohlc values from previous candle
-			newCandle.Open = oldCandle.Close
-			newCandle.High = oldCandle.Close
-			newCandle.Close = oldCandle.Close
-			newCandle.Low = oldCandle.Close
-			c.setCandle(market, resolution, newCandle, true)
+	c.closed = true
+	c.timer.Stop()
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+	close(c.updatesStream)
+}
+
+// unlockAndSend releases candlesLock and sends the collected updates, so the
+// candles are read while the updates stream is blocked.
+func (c *currentCandles) unlockAndSend() {
+	pending := c.pending
+	c.pending = nil
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+	c.candlesLock.Unlock()
+	for _, u := range pending {
+		c.send(u.ctx, u.level, u.candle)
+	}
+}
+
+// schedule sets the close times of the levels whose periods are over and arms
+// the timer at the earliest close time.
+func (c *currentCandles) schedule(now time.Time) {
+	next := time.Time{}
+	for level, resolution := range levels {
+		if !now.Before(c.closeAt[level]) {
+			c.closeAt[level] = model.CalculateCloseTime(c.openTime(resolution, now), resolution).Add(time.Nanosecond)
+		}
+		if next.IsZero() || c.closeAt[level].Before(next) {
+			next = c.closeAt[level]
+		}
+	}
+	c.timer = c.clock.AfterFunc(next.Sub(now), c.closeOver)
+}
+
+// closeOver closes the candles of the levels whose periods are over, from the
+// finest to the coarsest, and reschedules the timer. The closed and the fresh
+// candles are sent after the lock is released.
+func (c *currentCandles) closeOver() {
+	c.candlesLock.Lock()
+	defer c.unlockAndSend()
+	if c.closed {
+		return
+	}
+	now := c.clock.Now()
+	for level := range levels {
+		if now.Before(c.closeAt[level]) {
+			continue
 		}
+		for market, candles := range c.candles {
+			if candles[level] != nil {
+				c.rollOver(market, level, now)
+			}
+		}
+	}
+	c.schedule(now)
+}
+
+// rollOver sends the candle of the level as closed if its period is over and
+// replaces it with the fresh one inheriting the close price. The deals and the
+// timer roll over the candles, so a candle is closed once whichever comes
+// first.
+func (c *currentCandles) rollOver(market string, level int, now time.Time) {
+	oldCandle := c.getSafeCandle(market, level)
+	if oldCandle == nil || oldCandle.ContainsTs(now.UnixNano()) {
+		return
 	}
+	newCandle := c.buildFreshCandle(market, levels[level], now)
+	//inherit ohlc values from previous candle
+	newCandle.Open = oldCandle.Close
+	newCandle.High = oldCandle.Close
+	newCandle.Close = oldCandle.Close
+	newCandle.Low = oldCandle.Close
+	c.setCandle(context.Background(), market, level, newCandle, true)
 }
 
 func (c *currentCandles) AddCandle(market string, resolution model.Resolution, candle domain.Candle) error {
+	level, ok := levelIndex[resolution]
+	if !ok {
+		return fmt.Errorf("can't AddCandle to currentCandles: unknown resolution '%s'", resolution)
+	}
 	c.candlesLock.Lock()
-	defer c.candlesLock.Unlock()
+	defer c.unlockAndSend()
 	if candle == (domain.Candle{}) {
-		candle = c.buildFreshCandle(market, resolution)
+		candle = c.buildFreshCandle(market, levels[level], c.clock.Now())
 	}
-	c.setCandle(market, resolution, candle, false)
+	candle.Resolution = levels[level]
+	c.setCandle(context.Background(), market, level, candle, false)
 	//TODO check is it fresh
 	return nil
 }
 
-func (c *currentCandles) AddDeal(deal *matcher.Deal) error {
+func (c *currentCandles) AddDeal(ctx context.Context, deal *matcher.Deal) error {
+	ctx, span := tracing.Tracer().Start(ctx, "currentCandles.AddDeal")
+	defer span.End()
 	c.candlesLock.Lock()
-	defer c.candlesLock.Unlock()
-	resolutions := c.candles[deal.Market]
-	if len(resolutions) == 0 {
+	defer c.unlockAndSend()
+	candles := c.candles[deal.Market]
+	if len(candles) == 0 {
 		c.lgr.WithField("m", deal.Market).Infof("absent currentCandle")
+		return nil
+	}
+	t := time.Unix(0, deal.CreatedAt)
+	position := c.positions[deal.Market]
+	if position == nil {
+		position = &model.DealPosition{}
+		c.positions[deal.Market] = position
+	}
+	if !t.After(c.restoredAt[deal.Market]) && position.Covers(t, deal.Id) {
+		// the deal is applied already
+		return nil
+	}
+	position.Advance(t, deal.Id)
+	price, amount, err := parseDeal(deal)
+	if err != nil {
+		err = fmt.Errorf("can't AddDeal to currentCandles: '%w'", err)
+		span.RecordError(err)
+		return err
 	}
-	for resolution := range resolutions {
-		currentCandle := c.getFreshCandle(deal.Market, resolution)
+	// the deal is added to the finest candle containing it only, a coarser
+	// candle folds in the change of the candle of its parent level
+	var changes [len(levels)]candleChange
+	now := c.clock.Now()
+	for level := range candles {
+		if candles[level] == nil {
+			continue
+		}
+		// the timer could be late for the deal
+		c.rollOver(deal.Market, level, now)
+		currentCandle := *candles[level]
 		if !currentCandle.ContainsTs(deal.CreatedAt) {
 			continue
 		}
-		currentCandle, err := updateCandle(currentCandle, deal)
+		parent := levelParents[level]
+		for parent >= 0 && !changes[parent].applied {
+			parent = levelParents[parent]
+		}
+		change := &changes[level]
+		if parent >= 0 {
+			change.raisedHigh, change.loweredLow, err = rollUp(&currentCandle, *candles[parent], amount, changes[parent])
+		} else {
+			change.raisedHigh, change.loweredLow, err = addDeal(&currentCandle, price, amount)
+		}
 		if err != nil {
-			return fmt.Errorf("can't AddDeal to currentCandles: '%w'", err)
+			err = fmt.Errorf("can't AddDeal to currentCandles: '%w'", err)
+			span.RecordError(err)
+			return err
 		}
-		c.setCandle(deal.Market, resolution, currentCandle, false)
+		change.applied = true
+		c.setCandle(ctx, deal.Market, level, currentCandle, false)
 	}
 	return nil
 }
-func (c *currentCandles) setCandle(market string, resolution model.Resolution, candle domain.Candle, isRefresh bool) {
-	oldCandle := c.getSafeCandle(market, resolution)
+
+func (c *currentCandles) Snapshot(markets []string, resolutions []model.Resolution) []domain.Candle {
+	c.candlesLock.Lock()
+	defer c.candlesLock.Unlock()
+	if len(markets) == 0 {
+		markets = make([]string, 0, len(c.candles))
+		for market := range c.candles {
+			markets = append(markets, market)
+		}
+	}
+	if len(resolutions) == 0 {
+		resolutions = model.GetAvailableResolutions()
+	}
+	candles := make([]domain.Candle, 0, len(markets)*len(resolutions))
+	for _, market := range markets {
+		for _, resolution := range resolutions {
+			level, ok := levelIndex[resolution]
+			if !ok {
+				continue
+			}
+			if candle := c.getSafeCandle(market, level); candle != nil {
+				aliased := *candle
+				aliased.Resolution = resolution
+				candles = append(candles, aliased)
+			}
+		}
+	}
+	return candles
+}
+
+func (c *currentCandles) RemoveMarket(market string) {
+	c.candlesLock.Lock()
+	defer c.candlesLock.Unlock()
+	delete(c.candles, market)
+	delete(c.positions, market)
+	delete(c.restoredAt, market)
+}
+
+func (c *currentCandles) State(market string) (MarketCandles, bool) {
+	c.candlesLock.Lock()
+	defer c.candlesLock.Unlock()
+	candles := c.candles[market]
+	if len(candles) == 0 {
+		return MarketCandles{}, false
+	}
+	state := MarketCandles{Candles: make([]domain.Candle, 0, len(candles))}
+	for _, candle := range candles {
+		if candle != nil {
+			state.Candles = append(state.Candles, *candle)
+		}
+	}
+	if position := c.positions[market]; position != nil {
+		state.Position = model.DealPosition{T: position.T, IDs: append([]string(nil), position.IDs...)}
+	}
+	return state, true
+}
+
+func (c *currentCandles) Restore(market string, state MarketCandles) {
+	c.candlesLock.Lock()
+	defer c.unlockAndSend()
+	now := c.clock.Now()
+	for _, candle := range state.Candles {
+		level, ok := levelIndex[candle.Resolution]
+		if !ok {
+			continue
+		}
+		if !candle.ContainsTs(now.UnixNano()) {
+			fresh := c.buildFreshCandle(market, levels[level], now)
+			fresh.Open = candle.Close
+			fresh.High = candle.Close
+			fresh.Low = candle.Close
+			fresh.Close = candle.Close
+			candle = fresh
+		}
+		candle.Resolution = levels[level]
+		candle.Closed = false
+		candle.Ctx = nil
+		c.setCandle(context.Background(), market, level, candle, false)
+	}
+	position := state.Position
+	c.positions[market] = &position
+	c.restoredAt[market] = now
+}
+
+// setCandle stores the candle of the level and queues it for the updates
+// stream, it's sent by every alias of the level. The update of a traced
+// context carries a span which ends when the update is received.
+func (c *currentCandles) setCandle(ctx context.Context, market string, level int, candle domain.Candle, isRefresh bool) {
+	if c.closed {
+		return
+	}
+	oldCandle := c.getSafeCandle(market, level)
 	//nothing changed
 	if oldCandle != nil && *oldCandle == candle {
 		return
 	}
 	if oldCandle != nil && isRefresh { //send old candle only on refresh (because it is closed)
-		c.updatesStream <- *oldCandle
+		closedCandle := *oldCandle
+		closedCandle.Closed = true
+		c.pending = append(c.pending, update{ctx: context.Background(), level: level, candle: closedCandle})
 	}
-	c.setSafeCandle(market, resolution, candle)
-	c.updatesStream <- candle
+	c.setSafeCandle(market, level, candle)
+	c.pending = append(c.pending, update{ctx: ctx, level: level, candle: candle})
 }
 
-func (c *currentCandles) getSafeCandle(market string, resolution model.Resolution) *domain.Candle {
-	if c.candles[market] == nil || c.candles[market][resolution] == nil {
-		return nil
+// send fans the candle out to the aliases of the level.
+func (c *currentCandles) send(ctx context.Context, level int, candle domain.Candle) {
+	traced := trace.SpanContextFromContext(ctx).IsValid()
+	for _, resolution := range levelAliases[level] {
+		candle.Resolution = resolution
+		if traced {
+			candle.Ctx, _ = tracing.Tracer().Start(
+				ctx,
+				"currentCandles.updatesStream",
+				trace.WithAttributes(attribute.String("resolution", string(resolution))),
+			)
+		}
+		c.updatesStream <- candle
 	}
-	return c.candles[market][resolution]
 }
-func (c *currentCandles) setSafeCandle(market string, resolution model.Resolution, candle domain.Candle) {
+
+func (c *currentCandles) getSafeCandle(market string, level int) *domain.Candle {
 	if c.candles[market] == nil {
-		c.candles[market] = map[model.Resolution]*domain.Candle{}
+		return nil
 	}
-	c.candles[market][resolution] = &candle
+	return c.candles[market][level]
 }
-func (c *currentCandles) getFreshCandle(market string, resolution model.Resolution) domain.Candle {
-	now := timeNow()
-	candle := c.getSafeCandle(market, resolution)
-	if candle == nil || !candle.ContainsTs(now.UnixNano()) {
-		return c.buildFreshCandle(market, resolution)
+func (c *currentCandles) setSafeCandle(market string, level int, candle domain.Candle) {
+	if c.candles[market] == nil {
+		c.candles[market] = make(marketCandles, len(levels))
 	}
-	return *candle
+	c.candles[market][level] = &candle
 }
-
-func (c *currentCandles) buildFreshCandle(market string, resolution model.Resolution) domain.Candle {
-	openTime := time.Unix(c.aggregator.GetResolutionStartTimestampByTime(resolution, timeNow()), 0).UTC()
+func (c *currentCandles) buildFreshCandle(market string, resolution model.Resolution, now time.Time) domain.Candle {
+	openTime := c.openTime(resolution, now)
 	return domain.Candle{
 		Symbol:     market,
 		Resolution: resolution,
@@ -145,37 +471,99 @@ func (c *currentCandles) buildFreshCandle(market string, resolution model.Resolu
 	}
 }
 
+func (c *currentCandles) openTime(resolution model.Resolution, now time.Time) time.Time {
+	return time.Unix(c.aggregator.GetResolutionStartTimestampByTime(resolution, now), 0).UTC()
+}
+
 func updateCandle(candle domain.Candle, deal *matcher.Deal) (domain.Candle, error) {
-	dealPrice, err := primitive.ParseDecimal128(deal.Price)
+	price, amount, err := parseDeal(deal)
 	if err != nil {
 		return domain.Candle{}, err
 	}
-	if candle.Volume.IsZero() {
-		candle.Open = dealPrice
+	if _, _, err := addDeal(&candle, price, amount); err != nil {
+		return domain.Candle{}, err
 	}
-	dealAmount, err := primitive.ParseDecimal128(deal.Amount)
+	return candle, nil
+}
+
+func parseDeal(deal *matcher.Deal) (price, amount primitive.Decimal128, err error) {
+	price, err = primitive.ParseDecimal128(deal.Price)
 	if err != nil {
-		return domain.Candle{}, err
+		return price, amount, err
 	}
-	volume, err := addPrimitiveDecimal128(dealAmount, candle.Volume)
+	amount, err = primitive.ParseDecimal128(deal.Amount)
+	return price, amount, err
+}
+
+// addDeal adds the deal to the candle. It returns whether the high is raised
+// and the low is lowered.
+func addDeal(candle *domain.Candle, price, amount primitive.Decimal128) (raisedHigh, loweredLow bool, err error) {
+	if candle.Volume.IsZero() {
+		candle.Open = price
+	}
+	volume, err := decimal128.Add(amount, candle.Volume)
 	if err != nil {
-		return domain.Candle{}, err
+		return false, false, err
 	}
 	candle.Volume = volume
-	candle.Close = dealPrice
-	highCmp, err := compareDecimal128(dealPrice, candle.High)
+	candle.Close = price
+	highCmp, err := decimal128.Compare(price, candle.High)
 	if err != nil {
-		return domain.Candle{}, err
+		return false, false, err
 	}
 	if highCmp > 0 {
-		candle.High = dealPrice
+		candle.High = price
+		raisedHigh = true
 	}
-	lowCmp, err := compareDecimal128(dealPrice, candle.Low)
+	lowCmp, err := decimal128.Compare(price, candle.Low)
 	if err != nil {
-		return domain.Candle{}, err
+		return false, false, err
 	}
 	if lowCmp < 0 || candle.Low.IsZero() {
-		candle.Low = dealPrice
+		candle.Low = price
+		loweredLow = true
 	}
-	return candle, nil
+	return raisedHigh, loweredLow, nil
+}
+
+// candleChange is the change of a candle made by a deal.
+type candleChange struct {
+	applied, raisedHigh, loweredLow bool
+}
+
+// rollUp folds the change a deal of the amount made to the finer candle into
+// the candle containing it. The high and the low are compared with the finer
+// ones only if those have changed. It returns whether the high is raised and
+// the low is lowered.
+func rollUp(candle *domain.Candle, finer domain.Candle, amount primitive.Decimal128, finerChange candleChange) (raisedHigh, loweredLow bool, err error) {
+	if candle.Volume.IsZero() {
+		candle.Open = finer.Open
+	}
+	volume, err := decimal128.Add(amount, candle.Volume)
+	if err != nil {
+		return false, false, err
+	}
+	candle.Volume = volume
+	candle.Close = finer.Close
+	if finerChange.raisedHigh || candle.High.IsZero() {
+		highCmp, err := decimal128.Compare(finer.High, candle.High)
+		if err != nil {
+			return false, false, err
+		}
+		if highCmp > 0 {
+			candle.High = finer.High
+			raisedHigh = true
+		}
+	}
+	if finerChange.loweredLow || candle.Low.IsZero() {
+		lowCmp, err := decimal128.Compare(finer.Low, candle.Low)
+		if err != nil {
+			return false, false, err
+		}
+		if lowCmp < 0 || candle.Low.IsZero() {
+			candle.Low = finer.Low
+			loweredLow = true
+		}
+	}
+	return raisedHigh, loweredLow, nil
 }
@@ -14,6 +14,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Test_updateCandle(t *testing.T) {
@@ -40,15 +44,13 @@ func Test_updateCandle(t *testing.T) {
 func TestNewCurrentCandles_updates(t *testing.T) {
 	t.Run("get last ohlc on refresh", func(t *testing.T) {
 		now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
-		timeNow = func() time.Time {
-			return now
-		}
+		clock := newFakeClock(now)
 		updatesStream := make(chan domain.Candle, 512)
-		candles := NewCurrentCandles(context.Background(), updatesStream).(*currentCandles)
+		candles := NewCurrentCandles(context.Background(), updatesStream, clock).(*currentCandles)
 		//init with empty candles
 		for _, market := range []string{"ETH/BTC"} {
 			for _, resolution := range []model.Resolution{model.Candle1MResolution} {
-				openTime := time.Unix((&Aggregator{}).GetResolutionStartTimestampByTime(resolution, timeNow()), 0).UTC()
+				openTime := time.Unix((&Aggregator{}).GetResolutionStartTimestampByTime(resolution, clock.Now()), 0).UTC()
 				require.NoError(t, candles.AddCandle(market, resolution, domain.Candle{
 					Symbol:     "ETH/BTC",
 					Resolution: resolution,
@@ -67,8 +69,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 		_, ok := <-updatesStream
 		assert.True(t, ok)
 		//it's refresh time
-		now = time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC)
-		candles.refreshAll()
+		clock.Set(time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC))
 		require.Len(t, updatesStream, 2, "1 for old closed minute candle and 1 for the new empty minute candle")
 		candle, ok := <-updatesStream
 		assert.True(t, ok)
@@ -83,6 +84,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				Volume:     mustParseDecimal128(t, "159.39"),
 				OpenTime:   time.Date(2020, 4, 14, 15, 45, 0, 0, time.UTC),
 				CloseTime:  time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC),
+				Closed:     true,
 			}, candle)
 		//new minute candle
 		candle, ok = <-updatesStream
@@ -101,7 +103,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 			}, candle, "inherit ohlc values from previous candle")
 		require.Len(t, updatesStream, 0)
 		//when first deal arrives
-		require.NoError(t, candles.AddDeal(&matcher.Deal{
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 			Market:    "ETH/BTC",
 			CreatedAt: time.Date(2020, 4, 14, 15, 46, 53, 0, time.UTC).UnixNano(),
 			Price:     "0.013",
@@ -127,19 +129,17 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 	})
 	t.Run("1 market 1 deal 2 resolutions", func(t *testing.T) {
 		now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
-		timeNow = func() time.Time {
-			return now
-		}
+		clock := newFakeClock(now)
 		updatesStream := make(chan domain.Candle, 512)
-		candles := NewCurrentCandles(context.Background(), updatesStream).(*currentCandles)
+		candles := NewCurrentCandles(context.Background(), updatesStream, clock).(*currentCandles)
 		//init with empty candles
 		for _, market := range []string{"ETH/BTC"} {
 			for _, resolution := range []model.Resolution{model.Candle1MResolution, model.Candle1HResolution} {
 				require.NoError(t, candles.AddCandle(market, resolution, domain.Candle{}))
 			}
 		}
-		//2 new candles after init.
-		require.Len(t, updatesStream, 2)
+		//2 new candles after init, the hour one is sent by its alias too.
+		require.Len(t, updatesStream, 3)
 		candle, ok := <-updatesStream
 		assert.True(t, ok)
 		assert.Equal(t,
@@ -159,15 +159,18 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				OpenTime:   time.Date(2020, 4, 14, 15, 0, 0, 0, time.UTC),
 				CloseTime:  time.Date(2020, 4, 14, 16, 0, 0, 0, time.UTC),
 			}, candle)
+		candle, ok = <-updatesStream
+		assert.True(t, ok)
+		assert.Equal(t, model.Candle1H2Resolution, candle.Resolution)
 		//make a deal
-		require.NoError(t, candles.AddDeal(&matcher.Deal{
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 			Market:    "ETH/BTC",
 			CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
 			Price:     "0.019",
 			Amount:    "14.9",
 		}))
 		//both candles are updated
-		require.Len(t, updatesStream, 2, "two new with the deal")
+		require.Len(t, updatesStream, 3, "two new with the deal and the hour alias")
 		//new minute candle with the deal
 		candle, ok = <-updatesStream
 		assert.True(t, ok)
@@ -198,9 +201,13 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				OpenTime:   time.Date(2020, 4, 14, 15, 0, 0, 0, time.UTC),
 				CloseTime:  time.Date(2020, 4, 14, 16, 0, 0, 0, time.UTC),
 			}, candle)
+		alias, ok := <-updatesStream
+		assert.True(t, ok)
+		assert.Equal(t, model.Candle1H2Resolution, alias.Resolution)
+		alias.Resolution = candle.Resolution
+		assert.Equal(t, candle, alias, "the alias shares the candle")
 		//it's refresh time
-		now = time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC)
-		candles.refreshAll()
+		clock.Set(time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC))
 		//the minute candle is closed, but hour candle is not closed
 		require.Len(t, updatesStream, 2, "1 for old closed minute candle and 1 for the new empty minute candle")
 		candle, ok = <-updatesStream
@@ -216,6 +223,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				Volume:     mustParseDecimal128(t, "14.9"),
 				OpenTime:   time.Date(2020, 4, 14, 15, 45, 0, 0, time.UTC),
 				CloseTime:  time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC),
+				Closed:     true,
 			}, candle)
 		//new minute candle
 		candle, ok = <-updatesStream
@@ -235,11 +243,9 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 	})
 	t.Run("1 market 2 deal 1 resolutions", func(t *testing.T) {
 		now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
-		timeNow = func() time.Time {
-			return now
-		}
+		clock := newFakeClock(now)
 		updatesStream := make(chan domain.Candle, 512)
-		candles := NewCurrentCandles(context.Background(), updatesStream).(*currentCandles)
+		candles := NewCurrentCandles(context.Background(), updatesStream, clock).(*currentCandles)
 		//init with empty candles
 		for _, market := range []string{"ETH/BTC"} {
 			for _, resolution := range []model.Resolution{model.Candle1MResolution} {
@@ -258,7 +264,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				CloseTime:  time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC),
 			}, candle)
 		//make a deal
-		require.NoError(t, candles.AddDeal(&matcher.Deal{
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 			Market:    "ETH/BTC",
 			CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
 			Price:     "0.019",
@@ -282,7 +288,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 				CloseTime:  time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC),
 			}, candle)
 		//make another deal
-		require.NoError(t, candles.AddDeal(&matcher.Deal{
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 			Market:    "ETH/BTC",
 			CreatedAt: time.Date(2020, 4, 14, 15, 45, 53, 0, time.UTC).UnixNano(),
 			Price:     "0.013",
@@ -306,7 +312,7 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 			}, candle)
 		require.Len(t, updatesStream, 0)
 		//make miss deal with a non-existent market
-		require.NoError(t, candles.AddDeal(&matcher.Deal{
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 			Market:    "ETH/CRONA",
 			CreatedAt: time.Date(2020, 4, 14, 15, 45, 53, 0, time.UTC).UnixNano(),
 			Price:     "0.013",
@@ -318,26 +324,178 @@ func TestNewCurrentCandles_updates(t *testing.T) {
 
 }
 
-//add logs to refreshAll to ensure it runs every round minute
+//add logs to closeOver to ensure it runs every round minute
 /*
 Output:
-2022-06-02 11:32:00.000299 +0300 MSK m=+32.517239959 refreshAll
-2022-06-02 11:33:00.000162 +0300 MSK m=+92.518844209 refreshAll
-2022-06-02 11:34:00.011105 +0300 MSK m=+152.519722292 refreshAll
-2022-06-02 11:35:00.013223 +0300 MSK m=+212.509831459 refreshAll
-2022-06-02 11:36:00.013223 +0300 MSK m=+212.509831459 refreshAll
+2022-06-02 11:32:00.000299 +0300 MSK m=+32.517239959 closeOver
+2022-06-02 11:33:00.000162 +0300 MSK m=+92.518844209 closeOver
+2022-06-02 11:34:00.011105 +0300 MSK m=+152.519722292 closeOver
+2022-06-02 11:35:00.013223 +0300 MSK m=+212.509831459 closeOver
+2022-06-02 11:36:00.013223 +0300 MSK m=+212.509831459 closeOver
 */
 func Test_everyMinute_manual(t *testing.T) {
 	t.Skip()
 	t.Run("regular", func(t *testing.T) {
-		_ = NewCurrentCandles(context.Background(), nil)
+		_ = NewCurrentCandles(context.Background(), nil, SystemClock)
 		select {}
 	})
 }
 
+func TestCurrentCandles_Snapshot(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	for _, market := range []string{"ETH/BTC", "BTC/USDT"} {
+		for _, resolution := range []model.Resolution{model.Candle1MResolution, model.Candle1HResolution} {
+			require.NoError(t, candles.AddCandle(market, resolution, domain.Candle{}))
+		}
+	}
+	require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
+		Price:     "0.019",
+		Amount:    "14.9",
+	}))
+
+	t.Run("all", func(t *testing.T) {
+		assert.Len(t, candles.Snapshot(nil, nil), 6, "the hour candles by the alias too")
+	})
+	t.Run("filtered", func(t *testing.T) {
+		snapshot := candles.Snapshot([]string{"ETH/BTC"}, []model.Resolution{model.Candle1MResolution})
+		require.Len(t, snapshot, 1)
+		assert.Equal(t, "ETH/BTC", snapshot[0].Symbol)
+		assert.Equal(t, model.Candle1MResolution, snapshot[0].Resolution)
+		assert.Equal(t, mustParseDecimal128(t, "0.019"), snapshot[0].Close)
+		assert.Equal(t, mustParseDecimal128(t, "14.9"), snapshot[0].Volume)
+	})
+	t.Run("unknown market", func(t *testing.T) {
+		assert.Empty(t, candles.Snapshot([]string{"XRP/USDT"}, nil))
+	})
+}
+
+func TestCurrentCandles_Restore(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	for _, resolution := range []model.Resolution{model.Candle1MResolution, model.Candle1HResolution} {
+		require.NoError(t, candles.AddCandle("ETH/BTC", resolution, domain.Candle{}))
+	}
+	deal := &matcher.Deal{
+		Id:        "1",
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
+		Price:     "0.019",
+		Amount:    "14.9",
+	}
+	require.NoError(t, candles.AddDeal(context.Background(), deal))
+	state, ok := candles.State("ETH/BTC")
+	require.True(t, ok)
+	assert.Len(t, state.Candles, 2)
+	assert.Equal(t, model.DealPosition{T: time.Unix(0, deal.CreatedAt), IDs: []string{"1"}}, state.Position)
+	_, ok = candles.State("BTC/USDT")
+	assert.False(t, ok)
+
+	clock.Advance(time.Minute)
+	restored := NewCurrentCandles(context.Background(), make(chan domain.Candle, 512), clock)
+	restored.Restore("ETH/BTC", state)
+	require.NoError(t, restored.AddDeal(context.Background(), deal), "the redelivered deal is skipped")
+	hour := restored.Snapshot(nil, []model.Resolution{model.Candle1HResolution})
+	require.Len(t, hour, 1)
+	assert.Equal(t, mustParseDecimal128(t, "14.9"), hour[0].Volume, "the candle of the current period is kept")
+	minute := restored.Snapshot(nil, []model.Resolution{model.Candle1MResolution})
+	require.Len(t, minute, 1)
+	assert.Equal(t, time.Date(2020, 4, 14, 15, 46, 0, 0, time.UTC), minute[0].OpenTime, "the candle of the period over is replaced")
+	assert.Equal(t, mustParseDecimal128(t, "0.019"), minute[0].Open)
+	assert.True(t, minute[0].Volume.IsZero())
+
+	require.NoError(t, restored.AddDeal(context.Background(), &matcher.Deal{
+		Id:        "2",
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 46, 10, 0, time.UTC).UnixNano(),
+		Price:     "0.02",
+		Amount:    "1",
+	}))
+	hour = restored.Snapshot(nil, []model.Resolution{model.Candle1HResolution})
+	assert.Equal(t, mustParseDecimal128(t, "15.9"), hour[0].Volume)
+
+	// the restore of a market doesn't skip the deals of the others
+	require.NoError(t, restored.AddCandle("BTC/USDT", model.Candle1HResolution, domain.Candle{}))
+	for _, deal := range []*matcher.Deal{
+		{Id: "3", Market: "BTC/USDT", CreatedAt: time.Date(2020, 4, 14, 15, 46, 20, 0, time.UTC).UnixNano(), Price: "7000", Amount: "1"},
+		{Id: "4", Market: "BTC/USDT", CreatedAt: time.Date(2020, 4, 14, 15, 46, 15, 0, time.UTC).UnixNano(), Price: "7001", Amount: "1"},
+	} {
+		restored.Restore("ETH/BTC", state)
+		require.NoError(t, restored.AddDeal(context.Background(), deal))
+	}
+	btc := restored.Snapshot([]string{"BTC/USDT"}, []model.Resolution{model.Candle1HResolution})
+	require.Len(t, btc, 1)
+	assert.Equal(t, mustParseDecimal128(t, "2"), btc[0].Volume)
+}
+
+func TestCurrentCandles_AddDealTraced(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	require.NoError(t, candles.AddCandle("ETH/BTC", model.Candle1MResolution, domain.Candle{}))
+	assert.Nil(t, (<-updatesStream).Ctx, "the untraced updates don't carry a context")
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "deals.consume")
+	require.NoError(t, candles.AddDeal(ctx, &matcher.Deal{
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
+		Price:     "0.019",
+		Amount:    "14.9",
+	}))
+	span.End()
+	update := <-updatesStream
+	require.NotNil(t, update.Ctx)
+	assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(update.Ctx).TraceID())
+	trace.SpanFromContext(update.Ctx).End()
+
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.ElementsMatch(t, []string{"currentCandles.AddDeal", "deals.consume", "currentCandles.updatesStream"}, names)
+	assert.Equal(t, mustParseDecimal128(t, "0.019"), candles.Snapshot(nil, nil)[0].Close)
+	assert.Nil(t, candles.Snapshot(nil, nil)[0].Ctx, "the stored candles don't keep the trace")
+}
+
+func TestNewCurrentCandles_closed(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	updatesStream := make(chan domain.Candle, 512)
+	ctx, cancel := context.WithCancel(context.Background())
+	candles := NewCurrentCandles(ctx, updatesStream, clock)
+	require.NoError(t, candles.AddCandle("ETH/BTC", model.Candle1MResolution, domain.Candle{}))
+
+	cancel()
+	var updates []domain.Candle
+	for upd := range updatesStream {
+		updates = append(updates, upd)
+	}
+	assert.Len(t, updates, 1, "the stream is closed after the sent updates")
+	assert.NotPanics(t, func() {
+		require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
+			Market:    "ETH/BTC",
+			CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
+			Price:     "0.019",
+			Amount:    "14.9",
+		}))
+		require.NoError(t, candles.AddCandle("BTC/USDT", model.Candle1MResolution, domain.Candle{}))
+	}, "the changes after the close aren't sent")
+}
+
 func Test_concurrent(t *testing.T) {
+	clock := SystemClock
 	updatesStream := make(chan domain.Candle, 512)
-	candles := NewCurrentCandles(context.Background(), updatesStream)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
 	markets := []string{"market1", "market2", "market3"}
 	for _, market := range markets {
 		for _, resolution := range []model.Resolution{model.Candle1MResolution, model.Candle1HResolution, model.Candle15MResolution} {
@@ -361,7 +519,7 @@ func Test_concurrent(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			for i := 0; i < 100; i++ {
-				require.NoError(t, candles.AddDeal(&matcher.Deal{
+				require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
 					Market:    markets[rand.Intn(len(markets))],
 					CreatedAt: time.Now().UnixNano(),
 					Price:     strconv.FormatFloat(rand.Float64()*float64(rand.Intn(100)), 'f', 5, 64),
@@ -372,3 +530,234 @@ func Test_concurrent(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestCurrentCandles_derived(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock).(*currentCandles)
+	go func() {
+		for range updatesStream {
+		}
+	}()
+	expected := map[model.Resolution]domain.Candle{}
+	for _, resolution := range levels {
+		require.NoError(t, candles.AddCandle("ETH/BTC", resolution, domain.Candle{}))
+		expected[resolution] = candles.buildFreshCandle("ETH/BTC", resolution, now)
+	}
+	for i := 0; i < 1000; i++ {
+		deal := &matcher.Deal{
+			Market: "ETH/BTC",
+			// some deals are late for the minute candle
+			CreatedAt: now.Add(-time.Duration(rand.Intn(120)) * time.Second).UnixNano(),
+			Price:     strconv.FormatFloat(rand.Float64()*100, 'f', 5, 64),
+			Amount:    strconv.FormatFloat(rand.Float64()*10, 'f', 5, 64),
+		}
+		require.NoError(t, candles.AddDeal(context.Background(), deal))
+		for resolution, candle := range expected {
+			if !candle.ContainsTs(deal.CreatedAt) {
+				continue
+			}
+			candle, err := updateCandle(candle, deal)
+			require.NoError(t, err)
+			expected[resolution] = candle
+		}
+	}
+	for _, candle := range candles.Snapshot(nil, nil) {
+		want := expected[candle.Resolution.Canonical()]
+		want.Resolution = candle.Resolution
+		assert.Equal(t, want, candle, "the candle %s is the same as the one tracked independently", candle.Resolution)
+	}
+}
+
+func TestCurrentCandles_aliases(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	require.NoError(t, candles.AddCandle("ETH/BTC", model.Candle1MH2Resolution, domain.Candle{}))
+	require.NoError(t, candles.AddCandle("ETH/BTC", model.Candle1MHResolution, domain.Candle{}))
+	require.Len(t, updatesStream, 2, "the same candle added by the alias isn't sent again")
+	<-updatesStream
+	<-updatesStream
+
+	require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 45, 50, 0, time.UTC).UnixNano(),
+		Price:     "0.019",
+		Amount:    "14.9",
+	}))
+	require.Len(t, updatesStream, 2)
+	assert.Equal(t, model.Candle1MHResolution, (<-updatesStream).Resolution)
+	assert.Equal(t, model.Candle1MH2Resolution, (<-updatesStream).Resolution)
+	state, ok := candles.State("ETH/BTC")
+	require.True(t, ok)
+	assert.Len(t, state.Candles, 1, "the aliases share the state")
+}
+
+func BenchmarkCurrentCandles_AddDeal(b *testing.B) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	clock := newFakeClock(now)
+	benchmarks := []struct {
+		name        string
+		resolutions []model.Resolution
+	}{
+		{name: "1 resolution", resolutions: []model.Resolution{model.Candle1MResolution}},
+		{name: "all resolutions", resolutions: model.GetAvailableResolutions()},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			updatesStream := make(chan domain.Candle, 512)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			candles := NewCurrentCandles(ctx, updatesStream, clock)
+			go func() {
+				for range updatesStream {
+				}
+			}()
+			for _, resolution := range bm.resolutions {
+				require.NoError(b, candles.AddCandle("ETH/BTC", resolution, domain.Candle{}))
+			}
+			deals := benchmarkDeals(now, 1024)
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				if err := candles.AddDeal(context.Background(), deals[i%len(deals)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "deals/s")
+		})
+	}
+	// every resolution tracked independently, as the aliases were before
+	b.Run("independent resolutions", func(b *testing.B) {
+		resolutions := model.GetAvailableResolutions()
+		candles := make([]domain.Candle, len(resolutions))
+		for i, resolution := range resolutions {
+			candles[i] = (&currentCandles{}).buildFreshCandle("ETH/BTC", resolution, now)
+		}
+		deals := benchmarkDeals(now, 1024)
+		b.ReportAllocs()
+		b.ResetTimer()
+		start := time.Now()
+		for i := 0; i < b.N; i++ {
+			deal := deals[i%len(deals)]
+			for j := range candles {
+				if !candles[j].ContainsTs(deal.CreatedAt) {
+					continue
+				}
+				candle, err := updateCandle(candles[j], deal)
+				if err != nil {
+					b.Fatal(err)
+				}
+				candles[j] = candle
+			}
+		}
+		b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "deals/s")
+	})
+}
+
+func benchmarkDeals(now time.Time, n int) []*matcher.Deal {
+	deals := make([]*matcher.Deal, n)
+	for i := range deals {
+		deals[i] = &matcher.Deal{
+			Id:        strconv.Itoa(i),
+			Market:    "ETH/BTC",
+			CreatedAt: now.Add(-time.Duration(n-i) * time.Millisecond).UnixNano(),
+			Price:     strconv.FormatFloat(100+rand.Float64(), 'f', 5, 64),
+			Amount:    strconv.FormatFloat(rand.Float64()*10, 'f', 5, 64),
+		}
+	}
+	return deals
+}
+
+func TestCurrentCandles_closeOver(t *testing.T) {
+	clock := newFakeClock(time.Date(2020, 4, 14, 15, 58, 30, 0, time.UTC))
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	for _, resolution := range []model.Resolution{model.Candle1MResolution, model.Candle1HResolution} {
+		require.NoError(t, candles.AddCandle("ETH/BTC", resolution, domain.Candle{}))
+	}
+	require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 58, 40, 0, time.UTC).UnixNano(),
+		Price:     "0.019",
+		Amount:    "14.9",
+	}))
+	for len(updatesStream) > 0 {
+		<-updatesStream
+	}
+
+	clock.Set(time.Date(2020, 4, 14, 15, 58, 59, 999999999, time.UTC))
+	assert.Empty(t, updatesStream, "the minute isn't over yet")
+
+	clock.Set(time.Date(2020, 4, 14, 16, 0, 0, 500, time.UTC))
+	var closed []domain.Candle
+	for len(updatesStream) > 0 {
+		if upd := <-updatesStream; upd.Closed {
+			closed = append(closed, upd)
+		}
+	}
+	require.Len(t, closed, 4, "2 minutes, the hour and its alias")
+	assert.Equal(t, model.Candle1MResolution, closed[0].Resolution)
+	assert.Equal(t, time.Date(2020, 4, 14, 15, 58, 0, 0, time.UTC), closed[0].OpenTime)
+	assert.Equal(t, mustParseDecimal128(t, "14.9"), closed[0].Volume)
+	assert.Equal(t, time.Date(2020, 4, 14, 15, 59, 0, 0, time.UTC), closed[1].OpenTime)
+	assert.True(t, closed[1].Volume.IsZero())
+	assert.Equal(t, mustParseDecimal128(t, "0.019"), closed[1].Close, "the empty minute inherits the close")
+	assert.Equal(t, model.Candle1HResolution, closed[2].Resolution, "the finer candles are closed first")
+	assert.Equal(t, model.Candle1H2Resolution, closed[3].Resolution)
+	assert.Equal(t, mustParseDecimal128(t, "14.9"), closed[2].Volume)
+
+	require.NoError(t, candles.AddDeal(context.Background(), &matcher.Deal{
+		Market:    "ETH/BTC",
+		CreatedAt: time.Date(2020, 4, 14, 15, 59, 59, 0, time.UTC).UnixNano(),
+		Price:     "0.02",
+		Amount:    "1",
+	}))
+	assert.Empty(t, updatesStream, "the closed candles are final")
+	hour := candles.Snapshot(nil, []model.Resolution{model.Candle1HResolution})
+	require.Len(t, hour, 1)
+	assert.Equal(t, time.Date(2020, 4, 14, 16, 0, 0, 0, time.UTC), hour[0].OpenTime)
+}
+
+func TestCurrentCandles_closeOverSendsUnlocked(t *testing.T) {
+	clock := newFakeClock(time.Date(2020, 4, 14, 15, 58, 30, 0, time.UTC))
+	updatesStream := make(chan domain.Candle, 512)
+	candles := NewCurrentCandles(context.Background(), updatesStream, clock)
+	require.NoError(t, candles.AddCandle("ETH/BTC", model.Candle1MResolution, domain.Candle{}))
+	for len(updatesStream) > 0 {
+		<-updatesStream
+	}
+	for len(updatesStream) < cap(updatesStream) {
+		updatesStream <- domain.Candle{}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		clock.Set(time.Date(2020, 4, 14, 15, 59, 0, 0, time.UTC))
+	}()
+	snapshot := make(chan []domain.Candle)
+	go func() {
+		snapshot <- candles.Snapshot(nil, []model.Resolution{model.Candle1MResolution})
+	}()
+	select {
+	case minute := <-snapshot:
+		require.Len(t, minute, 1)
+	case <-time.After(time.Second):
+		t.Fatal("the candles are locked while the updates are sent")
+	}
+	for {
+		select {
+		case <-updatesStream:
+			continue
+		case <-closed:
+		}
+		break
+	}
+	minute := candles.Snapshot(nil, []model.Resolution{model.Candle1MResolution})
+	require.Len(t, minute, 1)
+	assert.Equal(t, time.Date(2020, 4, 14, 15, 59, 0, 0, time.UTC), minute[0].OpenTime)
+}
@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
 )
 
 type Storage struct {
@@ -24,6 +25,7 @@ func (s Storage) GetCandles(
 	unitSize int,
 	period ...time.Time,
 ) *domain.Chart {
+	defer metrics.ObserveSince(metrics.MongoAggregationDuration.WithLabelValues("GetCandles"), time.Now())
 	logger.FromContext(ctx).WithField(
 		"market",
 		market,
@@ -2,39 +2,159 @@ package market
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
 
-type cache struct {
-	sync.Mutex
-	cli Client
-	//last response
-	markets []Market
-	err     error
+var (
+	// ErrNotLoaded is returned by the cache which has never got a list.
+	ErrNotLoaded = errors.New("market list is not loaded")
+	// ErrEmptyList is returned by a refresh which got no markets.
+	ErrEmptyList = errors.New("market list is empty")
+	// ErrNoSnapshot is returned by the following cache without a snapshot.
+	ErrNoSnapshot = errors.New("market snapshot is not configured")
+)
+
+var timeNow = func() time.Time {
+	return time.Now()
+}
+
+// CacheConfig is the config of the market cache, the snapshot is optional.
+type CacheConfig struct {
+	Timeout  time.Duration
+	Snapshot Snapshot
 }
 
-func NewCache(cli Client) Client {
-	c := &cache{cli: cli}
-	go func() {
-		c.load()
-		for range time.Tick(time.Second * 30) {
-			c.load()
+// Cache keeps the last good market list, the failed refreshes don't replace
+// it, so the markets stay stale while the markets service is down.
+type Cache struct {
+	mu          sync.RWMutex
+	cli         Client
+	config      CacheConfig
+	markets     []Market
+	refreshedAt time.Time
+	err         error
+}
+
+func NewCache(cli Client, config CacheConfig) *Cache {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &Cache{cli: cli, config: config}
+}
+
+// List returns the last good list, it fails only if no list is loaded.
+func (c *Cache) List(_ context.Context) ([]Market, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.markets == nil {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, ErrNotLoaded
+	}
+	return c.markets, nil
+}
+
+// Refresh lists the markets and saves them into the snapshot. The last good
+// list is kept if the client fails or returns no markets.
+func (c *Cache) Refresh(ctx context.Context) error {
+	listCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	markets, err := c.cli.List(listCtx)
+	if err == nil && len(markets) == 0 {
+		err = ErrEmptyList
+	}
+	now := timeNow()
+
+	c.mu.Lock()
+	c.err = err
+	if err == nil {
+		c.markets = markets
+		c.refreshedAt = now
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if c.config.Snapshot != nil {
+		if err = c.config.Snapshot.Save(ctx, markets, now); err != nil {
+			return fmt.Errorf("can't save market snapshot: %w", err)
 		}
-	}()
-	return c
+	}
+	return nil
 }
 
-func (c *cache) List(_ context.Context) (markets []Market, err error) {
-	return c.markets, c.err
+// Load refreshes the markets and falls back to the snapshot if it fails, the
+// refresh time of the snapshot list is the time it was saved at.
+func (c *Cache) Load(ctx context.Context) error {
+	err := c.Refresh(ctx)
+	if c.Loaded() || c.config.Snapshot == nil {
+		return err
+	}
+	markets, savedAt, snapshotErr := c.config.Snapshot.Load(ctx)
+	if snapshotErr != nil {
+		return fmt.Errorf("%v, can't load market snapshot: %w", err, snapshotErr)
+	}
+	if len(markets) == 0 {
+		return fmt.Errorf("%w, market snapshot is empty", err)
+	}
+
+	c.mu.Lock()
+	c.markets = markets
+	c.refreshedAt = savedAt
+	c.mu.Unlock()
+	return nil
 }
 
-func (c *cache) load() {
-	c.Lock()
-	defer c.Unlock()
-	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancelFunc()
-	markets, err := c.cli.List(ctx)
+// Follow loads the list saved into the snapshot by the instance refreshing
+// the markets, it replaces the list only if the snapshot is newer.
+func (c *Cache) Follow(ctx context.Context) error {
+	if c.config.Snapshot == nil {
+		return ErrNoSnapshot
+	}
+	markets, savedAt, err := c.config.Snapshot.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("can't load market snapshot: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(markets) == 0 || !savedAt.After(c.refreshedAt) {
+		return nil
+	}
 	c.markets = markets
-	c.err = err
+	c.refreshedAt = savedAt
+	c.err = nil
+	return nil
+}
+
+// Loaded tells if the cache has a list.
+func (c *Cache) Loaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.markets != nil
+}
+
+// RefreshedAt returns the time the list was got from the client, it's zero if
+// no list is loaded.
+func (c *Cache) RefreshedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.refreshedAt
+}
+
+// Age returns how old the list is.
+func (c *Cache) Age(now time.Time) time.Duration {
+	return now.Sub(c.RefreshedAt())
+}
+
+// LastError returns the error of the last refresh or nil if it succeeded.
+func (c *Cache) LastError() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.err
 }
@@ -0,0 +1,180 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// marketsStandIn serves the market list, the failures are answered with the
+// status until they're exhausted.
+type marketsStandIn struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	body     string
+	requests int
+}
+
+func (s *marketsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if r.URL.Path != uriPathClientList || r.Header.Get("Authorization") != "token" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(s.status)
+		return
+	}
+	_, _ = w.Write([]byte(s.body))
+}
+
+func (s *marketsStandIn) set(failures, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures, s.status, s.body, s.requests = failures, status, body, 0
+}
+
+func (s *marketsStandIn) requestsCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func newStandInClient(t *testing.T, standIn *marketsStandIn) Client {
+	srv := httptest.NewServer(standIn)
+	t.Cleanup(srv.Close)
+	cli, err := New(Config{ServerURL: srv.URL}, NewErrorProcessor(map[string]string{}), map[interface{}]Option{}, "token")
+	require.NoError(t, err)
+	return NewRetry(cli, RetryConfig{Attempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+}
+
+func TestRetry_List(t *testing.T) {
+	standIn := &marketsStandIn{}
+	cli := newStandInClient(t, standIn)
+	ctx := context.Background()
+
+	standIn.set(2, http.StatusServiceUnavailable, `[{"id":"1","name":"BTC/USDT"}]`)
+	markets, err := cli.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Market{{ID: "1", Name: "USDT_BTC"}}, markets)
+	assert.Equal(t, 3, standIn.requestsCount())
+
+	standIn.set(3, http.StatusInternalServerError, `[]`)
+	_, err = cli.List(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 3, standIn.requestsCount())
+
+	// the client errors aren't retried
+	standIn.set(1, http.StatusUnauthorized, `[]`)
+	_, err = cli.List(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, standIn.requestsCount())
+}
+
+func TestCache_StaleWhileError(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+	standIn := &marketsStandIn{}
+	snapshot := NewFileSnapshot(filepath.Join(t.TempDir(), "markets.json"))
+	cache := NewCache(newStandInClient(t, standIn), CacheConfig{Snapshot: snapshot})
+	ctx := context.Background()
+
+	_, err := cache.List(ctx)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+
+	standIn.set(0, 0, `[{"id":"1","name":"BTC/USDT"}]`)
+	require.NoError(t, cache.Load(ctx))
+	assert.Equal(t, now, cache.RefreshedAt())
+
+	now = now.Add(time.Minute)
+	standIn.set(3, http.StatusBadGateway, `[]`)
+	assert.Error(t, cache.Refresh(ctx))
+	assert.Error(t, cache.LastError())
+	markets, err := cache.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Market{{ID: "1", Name: "USDT_BTC"}}, markets)
+	assert.Equal(t, time.Minute, cache.Age(now))
+
+	// an empty list doesn't replace the good one
+	standIn.set(0, 0, `[]`)
+	assert.ErrorIs(t, cache.Refresh(ctx), ErrEmptyList)
+	markets, err = cache.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, markets, 1)
+
+	standIn.set(0, 0, `[{"id":"1","name":"BTC/USDT"},{"id":"2","name":"BTC/ETH"}]`)
+	require.NoError(t, cache.Refresh(ctx))
+	assert.NoError(t, cache.LastError())
+	markets, err = cache.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, markets, 2)
+	assert.Equal(t, now, cache.RefreshedAt())
+}
+
+func TestCache_LoadSnapshot(t *testing.T) {
+	savedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	snapshot := NewFileSnapshot(filepath.Join(t.TempDir(), "markets.json"))
+	standIn := &marketsStandIn{}
+	standIn.set(3, http.StatusServiceUnavailable, `[]`)
+	ctx := context.Background()
+
+	// nothing is saved yet
+	cache := NewCache(newStandInClient(t, standIn), CacheConfig{Snapshot: snapshot})
+	assert.Error(t, cache.Load(ctx))
+	assert.False(t, cache.Loaded())
+
+	require.NoError(t, snapshot.Save(ctx, []Market{{ID: "1", Name: "USDT_BTC"}}, savedAt))
+	standIn.set(3, http.StatusServiceUnavailable, `[]`)
+	cache = NewCache(newStandInClient(t, standIn), CacheConfig{Snapshot: snapshot})
+	require.NoError(t, cache.Load(ctx))
+	markets, err := cache.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Market{{ID: "1", Name: "USDT_BTC"}}, markets)
+	assert.True(t, savedAt.Equal(cache.RefreshedAt()))
+	assert.Error(t, cache.LastError())
+}
+
+func TestCache_Follow(t *testing.T) {
+	savedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	snapshot := NewFileSnapshot(filepath.Join(t.TempDir(), "markets.json"))
+	standIn := &marketsStandIn{}
+	ctx := context.Background()
+
+	assert.ErrorIs(t, NewCache(newStandInClient(t, standIn), CacheConfig{}).Follow(ctx), ErrNoSnapshot)
+
+	cache := NewCache(newStandInClient(t, standIn), CacheConfig{Snapshot: snapshot})
+	require.NoError(t, snapshot.Save(ctx, []Market{{ID: "1", Name: "USDT_BTC"}}, savedAt))
+	require.NoError(t, cache.Follow(ctx))
+	markets, err := cache.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, markets, 1)
+	assert.True(t, savedAt.Equal(cache.RefreshedAt()))
+
+	// the older snapshot doesn't replace the list
+	require.NoError(t, snapshot.Save(ctx, []Market{{ID: "1", Name: "USDT_BTC"}, {ID: "2", Name: "ETH_BTC"}}, savedAt.Add(-time.Minute)))
+	require.NoError(t, cache.Follow(ctx))
+	markets, _ = cache.List(ctx)
+	assert.Len(t, markets, 1)
+
+	require.NoError(t, snapshot.Save(ctx, []Market{{ID: "1", Name: "USDT_BTC"}, {ID: "2", Name: "ETH_BTC"}}, savedAt.Add(time.Minute)))
+	require.NoError(t, cache.Follow(ctx))
+	markets, _ = cache.List(ctx)
+	assert.Len(t, markets, 2)
+	assert.Equal(t, 0, standIn.requestsCount(), "the markets service isn't requested")
+}
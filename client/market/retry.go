@@ -0,0 +1,73 @@
+package market
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryConfig is the policy of the list retries, the backoff doubles from
+// MinBackoff up to MaxBackoff with a random jitter of up to a half of it.
+type RetryConfig struct {
+	Attempts   int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryConfig retries for about half a minute.
+var DefaultRetryConfig = RetryConfig{
+	Attempts:   6,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+type retry struct {
+	cli    Client
+	config RetryConfig
+}
+
+// NewRetry retries the failed lists of the client until the attempts are
+// exhausted or the context is done. The client errors except 429 aren't
+// retried.
+func NewRetry(cli Client, config RetryConfig) Client {
+	if config.Attempts < 1 {
+		config.Attempts = 1
+	}
+	return &retry{cli: cli, config: config}
+}
+
+func (r *retry) List(ctx context.Context) (markets []Market, err error) {
+	backoff := r.config.MinBackoff
+	for attempt := 1; ; attempt++ {
+		markets, err = r.cli.List(ctx)
+		if err == nil || attempt >= r.config.Attempts || !isRetryable(err) {
+			return markets, err
+		}
+		timer := time.NewTimer(jitter(backoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+func isRetryable(err error) bool {
+	if e, ok := err.(errorCode); ok {
+		code := e.StatusCode()
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
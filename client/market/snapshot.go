@@ -0,0 +1,70 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot keeps the last good market list for the cold starts when the
+// markets service is down.
+type Snapshot interface {
+	Save(ctx context.Context, markets []Market, at time.Time) error
+	// Load returns the saved list and the time it was listed at, the list is
+	// empty if nothing is saved.
+	Load(ctx context.Context) ([]Market, time.Time, error)
+}
+
+type snapshotFile struct {
+	SavedAt time.Time `json:"saved_at"`
+	Markets []Market  `json:"markets"`
+}
+
+type fileSnapshot struct {
+	path string
+}
+
+// NewFileSnapshot stores the snapshot as a JSON file at the path.
+func NewFileSnapshot(path string) Snapshot {
+	return &fileSnapshot{path: path}
+}
+
+// Save writes a temporary file and renames it, so the snapshot is never
+// partially written.
+func (s *fileSnapshot) Save(_ context.Context, markets []Market, at time.Time) error {
+	data, err := json.Marshal(snapshotFile{SavedAt: at, Markets: markets})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileSnapshot) Load(_ context.Context) ([]Market, time.Time, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var f snapshotFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, time.Time{}, err
+	}
+	return f.Markets, f.SavedAt, nil
+}
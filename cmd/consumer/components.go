@@ -0,0 +1,716 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/events/topics"
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"bitbucket.org/novatechnologies/ohlcv/api/http"
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra"
+	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
+	"bitbucket.org/novatechnologies/ohlcv/infra/centrifuge"
+	"bitbucket.org/novatechnologies/ohlcv/infra/health"
+	"bitbucket.org/novatechnologies/ohlcv/infra/lifecycle"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/infra/mongo"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/lease"
+	"bitbucket.org/novatechnologies/ohlcv/internal/livestate"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
+	"bitbucket.org/novatechnologies/ohlcv/internal/server"
+	"bitbucket.org/novatechnologies/ohlcv/internal/service"
+	"bitbucket.org/novatechnologies/ohlcv/protocol/ohlcv"
+)
+
+const (
+	roleIngest = "ingest"
+	roleAPI    = "api"
+	roleAll    = "all"
+)
+
+// roles are the components run by the roles, their requirements are run too.
+// The ingesting instance saves the deals, materializes the klines and
+// broadcasts the updates. The API replicas keep the live state from the deals
+// of their own consumer groups.
+var roles = map[string][]string{
+	roleIngest: {"broadcaster", "http", "consumption", "sharding", "materializer"},
+	roleAPI:    {"http", "consumption", "grpc"},
+	roleAll:    {"broadcaster", "http", "consumption", "sharding", "materializer", "grpc"},
+}
+
+// errShuttingDown fails the readiness as soon as the shutdown begins.
+var errShuttingDown = errors.New("shutting down")
+
+func roleNames() []string {
+	names := make([]string, 0, len(roles))
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkRole fails for the all role with the sharding enabled: its API would
+// serve the current candles and the tickers of the owned markets only. The
+// sharded deployments run the ingest and the api roles apart.
+func checkRole(role string, conf infra.Config) error {
+	if role == roleAll && conf.ShardingConfig.Enabled {
+		return fmt.Errorf("the %s role can't be sharded, run the %s and the %s roles apart", roleAll, roleIngest, roleAPI)
+	}
+	return nil
+}
+
+// components wires the parts of the service. A component sets its fields on
+// start, they're used by the components requiring it.
+type components struct {
+	conf infra.Config
+	role string
+	// instance is the unique name of the instance in the leases.
+	instance string
+	// stopping fails the readiness as soon as the shutdown begins.
+	stopping *health.Flag
+
+	mongoDbClient   *mongoDriver.Client
+	dealsCollection *mongoDriver.Collection
+	marketCache     *market.Cache
+	markets         *domain.MarketRegistry
+	eventsBroker    *broker.EventsInMemory
+	tickerCache     *consumer.Ticker
+	dealChannel     chan *model.Deal
+	dealService     *service.Deal
+	dealConsumer    *consumer.Deal
+	candleService   *candle.Service
+	klineService    *service.Kline
+	candlesReady    *health.Flag
+	tickersReady    *health.Flag
+	checker         *health.Checker
+	currentCandles  candle.CurrentCandles
+	candleConsumer  *consumer.Candle
+	// kafkaConsumer consumes the deals for the live state.
+	kafkaConsumer *infra.Consumer
+	// savingConsumer saves the deals in the shared consumer group if the
+	// live state is sharded, nil if kafkaConsumer saves them.
+	savingConsumer *infra.Consumer
+	// ownership is nil if the instance keeps the live state of all the
+	// markets.
+	ownership *lease.Markets
+	// liveState is nil if the live state snapshots are disabled.
+	liveState *service.LiveState
+	// dealsConsumed is closed when the deals aren't consumed anymore.
+	dealsConsumed <-chan struct{}
+}
+
+func newApp(conf infra.Config, role, instance string, stopping *health.Flag) *lifecycle.App {
+	c := &components{conf: conf, role: role, instance: instance, stopping: stopping, candlesReady: new(health.Flag), tickersReady: new(health.Flag)}
+	app := lifecycle.NewApp(conf.ShutdownConfig.Timeout)
+	app.Add(lifecycle.Component{Name: "tracing", Start: c.startTracing})
+	app.Add(lifecycle.Component{Name: "mongo", Requires: []string{"tracing"}, Start: c.startMongo})
+	app.Add(lifecycle.Component{Name: "markets", Requires: []string{"mongo"}, Start: c.startMarkets})
+	app.Add(lifecycle.Component{Name: "broker", Start: c.startBroker})
+	app.Add(lifecycle.Component{Name: "broadcaster", Requires: []string{"markets", "broker"}, Start: c.startBroadcaster})
+	app.Add(lifecycle.Component{Name: "ownership", Requires: []string{"mongo", "markets"}, Start: c.startOwnership})
+	app.Add(lifecycle.Component{Name: "deals", Requires: []string{"mongo", "markets", "broker", "ownership"}, Start: c.startDeals})
+	app.Add(lifecycle.Component{Name: "health", Requires: []string{"mongo", "markets", "deals"}, Start: c.startHealth})
+	app.Add(lifecycle.Component{Name: "history", Requires: []string{"mongo", "broker"}, Start: c.startHistory})
+	app.Add(lifecycle.Component{Name: "klines", Requires: []string{"mongo", "deals"}, Start: c.startKlines})
+	app.Add(lifecycle.Component{Name: "materializer", Requires: []string{"klines"}, Start: c.startMaterializer})
+	// the probes are answered while the current candles are initialized
+	httpRequires := []string{"health"}
+	if role != roleIngest {
+		httpRequires = append(httpRequires, "deals", "history", "klines")
+	}
+	app.Add(lifecycle.Component{Name: "http", Requires: httpRequires, Start: c.startHTTP})
+	app.Add(lifecycle.Component{Name: "candles", Requires: []string{"history", "markets", "broker", "ownership", "deals"}, Start: c.startCandles})
+	// the last live state is saved after the consumption is stopped
+	app.Add(lifecycle.Component{Name: "livestate", Requires: []string{"deals", "candles"}, Start: c.startLiveState})
+	app.Add(lifecycle.Component{Name: "kafka", Start: c.startKafka})
+	app.Add(lifecycle.Component{Name: "consumption", Requires: []string{"kafka", "deals", "candles", "livestate"}, Start: c.startConsumption})
+	// the markets are acquired when their state is ready to be rebuilt
+	app.Add(lifecycle.Component{Name: "sharding", Requires: []string{"ownership", "deals", "candles"}, Start: c.startSharding})
+	app.Add(lifecycle.Component{Name: "grpc", Requires: []string{"deals", "history", "candles", "klines", "health"}, Start: c.startGRPC})
+	return app
+}
+
+func (c *components) startTracing(ctx context.Context) (lifecycle.Hook, error) {
+	return tracing.Init(ctx, c.conf.TracingConfig)
+}
+
+func (c *components) startMongo(ctx context.Context) (lifecycle.Hook, error) {
+	c.mongoDbClient = mongo.NewMongoClient(ctx, c.conf.MongoDbConfig)
+	if c.mongoDbClient == nil {
+		return nil, errors.New("can't connect to MongoDb")
+	}
+	dealsCollection, err := mongo.GetOrCreateDealsCollection(ctx, c.mongoDbClient, c.conf.MongoDbConfig)
+	if err != nil {
+		_ = c.mongoDbClient.Disconnect(ctx)
+		return nil, err
+	}
+	c.dealsCollection = dealsCollection
+	return c.mongoDbClient.Disconnect, nil
+}
+
+func (c *components) startMarkets(ctx context.Context) (lifecycle.Hook, error) {
+	marketCache, marketsInfo, err := buildAvailableMarkets(ctx, c.conf, c.mongoDbClient)
+	if err != nil {
+		return nil, err
+	}
+	symbolConfig, err := domain.ParseSymbolConfig(c.conf.MarketSymbolFormats, c.conf.MarketSymbolAliases)
+	if err != nil {
+		return nil, err
+	}
+	c.marketCache = marketCache
+	c.markets = domain.NewMarketRegistry(marketsInfo)
+	c.markets.SetSymbolConfig(symbolConfig)
+	leader := c.newLeader(ctx, "markets")
+	return lifecycle.Hooks(
+		lifecycle.Run(ctx, func(ctx context.Context) {
+			service.RunMarketSync(ctx, c.markets, c.marketCache, service.MarketSyncInterval, leader.IsLeader)
+		}),
+		lifecycle.Run(ctx, leader.Run),
+		leader.Resign,
+	), nil
+}
+
+// newLeader returns the election of the instance running the singleton jobs,
+// the instance always leads if the election is disabled.
+func (c *components) newLeader(ctx context.Context, election string) *lease.Leader {
+	var store lease.Store = lease.NewMemory()
+	if c.conf.LeaderConfig.Enabled {
+		store = repository.NewLease(
+			mongo.GetCollection(ctx, c.mongoDbClient, c.conf.MongoDbConfig, c.conf.MongoDbConfig.LeaseCollectionName),
+		)
+	}
+	return lease.NewLeader(store, election, c.instance, c.conf.LeaderConfig.LeaseTTL)
+}
+
+// startOwnership shares the markets if the sharding is enabled for the
+// ingesting instances, the sharded all role is rejected by checkRole.
+func (c *components) startOwnership(ctx context.Context) (lifecycle.Hook, error) {
+	conf := c.conf.ShardingConfig
+	if !conf.Enabled || c.role == roleAPI {
+		return nil, nil
+	}
+	store := repository.NewLease(
+		mongo.GetCollection(ctx, c.mongoDbClient, c.conf.MongoDbConfig, c.conf.MongoDbConfig.LeaseCollectionName),
+	)
+	c.ownership = lease.NewMarkets(store, c.instance, conf.LeaseTTL, func() []string {
+		markets := c.markets.Markets()
+		ids := make([]string, 0, len(markets))
+		for _, m := range markets {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	})
+	return nil, nil
+}
+
+// startSharding balances the markets with the other instances, its stop
+// hands them over at once. The state of an acquired market is rebuilt while
+// its live deals are held, they're applied once the market is owned.
+func (c *components) startSharding(ctx context.Context) (lifecycle.Hook, error) {
+	if c.ownership == nil {
+		return nil, nil
+	}
+	c.ownership.Subscribe(func(ctx context.Context, e lease.Event) {
+		switch e.Type {
+		case lease.Acquired:
+			c.dealService.Hold(e.Market)
+			m, ok := c.markets.Market(e.Market)
+			if !ok {
+				return
+			}
+			if err := c.loadTickers(ctx, m.Name); err != nil {
+				logger.FromContext(ctx).WithField("err", err).WithField("market", m.Name).Errorf("can't load ticker")
+			}
+			if err := c.rebuildCandles(ctx, m); err != nil {
+				logger.FromContext(ctx).
+					WithField("err", err).
+					WithField("market", m.Name).
+					Errorf("can't init current candles of the acquired market")
+			}
+		case lease.Taken:
+			c.dealService.Release(ctx, c.currentCandles, e.Market)
+		case lease.Released:
+			if name, ok := c.markets.Name(e.Market); ok {
+				c.tickerCache.RemoveMarket(name)
+			}
+			c.currentCandles.RemoveMarket(e.Market)
+		}
+	})
+	return lifecycle.Hooks(
+		lifecycle.Run(ctx, c.ownership.Run),
+		c.ownership.ReleaseAll,
+	), nil
+}
+
+// owns returns true if the instance keeps the live state of the market id.
+func (c *components) owns(market string) bool {
+	return c.ownership == nil || c.ownership.Owns(market)
+}
+
+// startBroker starts the events broker, its stop waits for the last
+// broadcasts.
+func (c *components) startBroker(context.Context) (lifecycle.Hook, error) {
+	c.eventsBroker = broker.NewInMemory()
+	return c.eventsBroker.Wait, nil
+}
+
+func (c *components) startBroadcaster(context.Context) (lifecycle.Hook, error) {
+	broadcaster := centrifuge.NewBroadcaster(
+		centrifuge.NewPublisher(c.conf.CentrifugeConfig),
+		c.eventsBroker,
+		c.markets,
+	)
+	broadcaster.SubscribeForCharts()
+	broadcaster.SubscribeForTickers()
+	return nil, nil
+}
+
+// startDeals starts the tickers and the delivery of the deals to the streams,
+// its stop delivers the consumed deals.
+func (c *components) startDeals(ctx context.Context) (lifecycle.Hook, error) {
+	c.dealChannel = make(chan *model.Deal, 1024)
+	metrics.RegisterChannel("deal", func() int { return len(c.dealChannel) }, func() int { return cap(c.dealChannel) })
+	c.tickerCache = consumer.NewTicker(c.markets, c.eventsBroker)
+	c.markets.Subscribe(c.tickerCache.HandleMarketEvent)
+	c.dealService = service.NewDeal(repository.NewDeal(c.dealsCollection, c.markets), c.tickerCache, c.markets, c.dealChannel)
+	if c.role == roleAPI || c.ownership != nil {
+		// the deals are saved by RunSaving in the shared consumer group
+		c.dealService.KeepLiveOnly()
+	}
+	if conf := c.conf.LiveStateConfig; conf.Interval > 0 {
+		var snapshot livestate.Snapshot
+		if conf.Path != "" {
+			snapshot = livestate.NewFileSnapshot(conf.Path)
+		} else {
+			snapshot = repository.NewLiveState(
+				mongo.GetCollection(ctx, c.mongoDbClient, c.conf.MongoDbConfig, c.conf.MongoDbConfig.LiveStateCollectionName),
+			)
+		}
+		c.liveState = service.NewLiveState(snapshot, c.dealService, c.markets, conf.MaxAge)
+	}
+	if c.ownership != nil {
+		// the tickers are loaded when the markets are acquired
+		c.dealService.OwnMarkets(c.ownership.Owns)
+	}
+	c.dealConsumer = consumer.NewDeal(c.dealChannel)
+	dealsDelivered := lifecycle.Go(func() {
+		c.dealConsumer.Consume(ctx)
+	})
+	return lifecycle.Hooks(
+		func(ctx context.Context) error {
+			// the deals are still sent if the consumption isn't finished
+			if c.dealsConsumed != nil {
+				if err := lifecycle.WaitDone(ctx, c.dealsConsumed); err != nil {
+					return err
+				}
+			}
+			close(c.dealChannel)
+			return lifecycle.WaitDone(ctx, dealsDelivered)
+		},
+		lifecycle.Run(ctx, c.tickerCache.ConsumeNewDeals),
+		lifecycle.Run(ctx, c.tickerCache.PublishUpdates),
+	), nil
+}
+
+// loadTickers restores the tickers of the market names from the live state,
+// the tickers which aren't restored are loaded from the storage.
+func (c *components) loadTickers(ctx context.Context, names ...string) error {
+	if c.liveState != nil {
+		rest, err := c.liveState.RestoreTickers(ctx, names)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't restore tickers")
+		}
+		logger.FromContext(ctx).
+			WithField("restored", len(names)-len(rest)).
+			WithField("loaded", len(rest)).
+			Infof("restored tickers from live state")
+		names = rest
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return c.dealService.LoadTickers(ctx, names...)
+}
+
+func (c *components) startHealth(context.Context) (lifecycle.Hook, error) {
+	c.checker = newChecker(c.conf.HealthConfig, c.mongoDbClient, c.dealService, c.marketCache, c.candlesReady, c.tickersReady, c.stopping)
+	return nil, nil
+}
+
+func (c *components) startHistory(context.Context) (lifecycle.Hook, error) {
+	c.candleService = candle.NewService(&candle.Storage{DealsDbCollection: c.dealsCollection}, new(candle.Aggregator), c.eventsBroker)
+	return nil, nil
+}
+
+func (c *components) startKlines(ctx context.Context) (lifecycle.Hook, error) {
+	conf := c.conf.MongoDbConfig
+	minutes, err := mongo.GetOrCreateKlinesCollection(ctx, c.mongoDbClient, conf, conf.MinuteKlineCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := mongo.GetOrCreateKlinesCollection(ctx, c.mongoDbClient, conf, conf.HourKlineCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	materializedKlineRepository := repository.NewMaterializedKline(
+		minutes,
+		hours,
+		mongo.GetCollection(ctx, c.mongoDbClient, conf, conf.KlineStateCollectionName),
+	)
+	c.klineService = service.NewKline(repository.NewKline(c.dealsCollection), materializedKlineRepository, c.tickerCache)
+	return nil, nil
+}
+
+// startMaterializer materializes the klines on the leader, the new leader
+// continues from the state saved by the previous one.
+func (c *components) startMaterializer(ctx context.Context) (lifecycle.Hook, error) {
+	leader := c.newLeader(ctx, "materializer")
+	leader.Go(c.klineService.RunMaterializer)
+	return lifecycle.Hooks(lifecycle.Run(ctx, leader.Run), leader.Resign), nil
+}
+
+func (c *components) startHTTP(ctx context.Context) (lifecycle.Hook, error) {
+	var httpServer *http.Server
+	if c.role == roleIngest {
+		httpServer = http.NewOpsServer(c.checker, c.conf)
+	} else {
+		httpServer = http.NewServer(c.candleService, c.dealService, c.klineService, c.markets, c.checker, c.conf)
+	}
+	if err := httpServer.Start(ctx); err != nil {
+		return nil, err
+	}
+	return httpServer.Stop, nil
+}
+
+// startCandles initializes the current candles, its stop broadcasts the last
+// updates.
+func (c *components) startCandles(ctx context.Context) (lifecycle.Hook, error) {
+	updatesStream := make(chan domain.Candle, 512)
+	candleChannel := make(chan domain.Candle, 1024)
+	metrics.RegisterChannel("updates", func() int { return len(updatesStream) }, func() int { return cap(updatesStream) })
+	metrics.RegisterChannel("candle", func() int { return len(candleChannel) }, func() int { return cap(candleChannel) })
+	candlesFlushed := lifecycle.Go(func() {
+		listenCurrentCandlesUpdates(ctx, updatesStream, c.eventsBroker, c.markets, candleChannel)
+	})
+	candlesCtx, stopCandles := context.WithCancel(ctx)
+	var owned []market.Market
+	for _, m := range c.markets.Markets() {
+		if c.owns(m.ID) {
+			owned = append(owned, m)
+		}
+	}
+	currentCandles := candle.NewCurrentCandles(candlesCtx, updatesStream, candle.SystemClock)
+	if err := c.initCandles(candlesCtx, currentCandles, owned); err != nil {
+		stopCandles()
+		return nil, err
+	}
+	c.currentCandles = currentCandles
+	c.candlesReady.Done()
+	c.markets.Subscribe(func(e domain.MarketEvent) {
+		switch e.Type {
+		case domain.MarketAdded:
+			if c.ownership != nil {
+				// the added market is acquired by one of the instances
+				return
+			}
+			// the history is loaded aside not to block the other subscribers
+			go func() {
+				if err := initMarketCurrentCandles(ctx, c.candleService, currentCandles, e.Market); err != nil {
+					logger.FromContext(ctx).
+						WithField("err", err).
+						WithField("market", e.Market.Name).
+						Errorf("can't init current candles of the added market")
+				}
+			}()
+		case domain.MarketRemoved:
+			currentCandles.RemoveMarket(e.Market.ID)
+		}
+	})
+	c.candleConsumer = consumer.NewCandle(candleChannel)
+	candlesDelivered := lifecycle.Go(func() {
+		c.candleConsumer.Consume(ctx)
+	})
+	return func(ctx context.Context) error {
+		if err := lifecycle.Cancel(stopCandles, candlesFlushed)(ctx); err != nil {
+			return err
+		}
+		close(candleChannel)
+		return lifecycle.WaitDone(ctx, candlesDelivered)
+	}, nil
+}
+
+// initCandles restores the current candles of the markets from the live
+// state, the candles which aren't restored are built from the storage.
+func (c *components) initCandles(ctx context.Context, currentCandles candle.CurrentCandles, markets []market.Market) error {
+	if c.liveState != nil {
+		rest, err := c.liveState.RestoreCandles(ctx, currentCandles, markets)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't restore current candles")
+		}
+		logger.FromContext(ctx).
+			WithField("restored", len(markets)-len(rest)).
+			WithField("rebuilt", len(rest)).
+			Infof("restored current candles from live state")
+		markets = rest
+	}
+	return initCurrentCandles(ctx, c.candleService, currentCandles, markets)
+}
+
+// rebuildCandles restores the current candles of the acquired market from the
+// live state, they're rebuilt from the storage if it isn't restored.
+func (c *components) rebuildCandles(ctx context.Context, m market.Market) error {
+	if c.liveState != nil {
+		rest, err := c.liveState.RestoreCandles(ctx, c.currentCandles, []market.Market{m})
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't restore current candles")
+		}
+		if len(rest) == 0 {
+			return nil
+		}
+	}
+	return rebuildMarketCurrentCandles(ctx, c.candleService, c.dealService, c.currentCandles, m)
+}
+
+// startLiveState saves the live state periodically, its stop saves the last
+// one.
+func (c *components) startLiveState(ctx context.Context) (lifecycle.Hook, error) {
+	if c.liveState == nil {
+		return nil, nil
+	}
+	return lifecycle.Hooks(
+		lifecycle.Run(ctx, func(ctx context.Context) {
+			c.liveState.Run(ctx, c.currentCandles, c.conf.LiveStateConfig.Interval)
+		}),
+		func(ctx context.Context) error {
+			return c.liveState.Save(ctx, c.currentCandles)
+		},
+	), nil
+}
+
+// startKafka joins the consumer groups, its stop commits the offsets.
+func (c *components) startKafka(ctx context.Context) (lifecycle.Hook, error) {
+	conf := c.conf.KafkaConfig
+	sharded := c.conf.ShardingConfig.Enabled && c.role != roleAPI
+	instance := c.instance
+	if sharded {
+		// the host name changes with every restart of a pod, a new group
+		// would be made each time
+		instance = c.conf.Instance
+	}
+	group, err := liveConsumerGroup(conf, c.role, instance, sharded)
+	if err != nil {
+		return nil, err
+	}
+	var savingConsumer *infra.Consumer
+	if sharded {
+		if savingConsumer, err = infra.NewConsumer(ctx, conf); err != nil {
+			return nil, err
+		}
+	}
+	conf.ConsumerGroup = group
+	kafkaConsumer, err := infra.NewConsumer(ctx, conf)
+	if err != nil {
+		if savingConsumer != nil {
+			_ = savingConsumer.Close()
+		}
+		return nil, err
+	}
+	c.kafkaConsumer = kafkaConsumer
+	c.savingConsumer = savingConsumer
+	return func(context.Context) error {
+		err := kafkaConsumer.Close()
+		if savingConsumer != nil {
+			if closeErr := savingConsumer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// liveConsumerGroup returns the consumer group of the deals for the live
+// state. The ingesting instances share ConsumerGroup, each deal is saved once.
+// The API replicas and the sharded ingesting instances need all the deals, so
+// each of them reads them in a group of its own: joining the shared group
+// would take its partitions, the deals of them would be lost for the saving
+// or for the owners of their markets. The sharded instances still save the
+// deals in the shared group apart. Their groups are named by the stable
+// INSTANCE_NAME, such as the StatefulSet pod name, so a restarted instance
+// resumes its group from the committed offsets.
+func liveConsumerGroup(conf infra.KafkaConfig, role, instance string, sharded bool) (string, error) {
+	var suffix string
+	switch {
+	case role == roleAPI:
+		suffix = "-api-"
+	case sharded:
+		suffix = "-live-"
+	default:
+		return conf.ConsumerGroup, nil
+	}
+	if instance == "" {
+		return "", fmt.Errorf("the %s instance has no INSTANCE_NAME for its consumer group", role)
+	}
+	group := conf.ConsumerGroup + suffix + instance
+	if group == conf.ConsumerGroup {
+		return "", fmt.Errorf("the %s instance would join the shared consumer group %s", role, group)
+	}
+	return group, nil
+}
+
+// startConsumption consumes the deals while the tickers are loaded in the
+// background, its stop waits for the consumed deals to be handled.
+func (c *components) startConsumption(ctx context.Context) (lifecycle.Hook, error) {
+	tickersLoaded := c.startTickers(ctx)
+	consumeCtx, stopConsuming := context.WithCancel(ctx)
+	kafkaConf := c.conf.KafkaConfig
+	dealsTopic := kafkaConf.TopicPrefix + "_" + topics.MatcherMDDeals
+	c.dealsConsumed = c.dealService.RunConsuming(consumeCtx, c.kafkaConsumer, dealsTopic, c.currentCandles)
+	consumed := []<-chan struct{}{c.dealsConsumed}
+	if c.savingConsumer != nil {
+		consumed = append(consumed, c.dealService.RunSaving(consumeCtx, c.savingConsumer, dealsTopic))
+	}
+	return lifecycle.Hooks(tickersLoaded, lifecycle.Cancel(stopConsuming, consumed...)), nil
+}
+
+// startTickers loads the tickers of all the markets in the background, their
+// live deals are held until the load is finished. The tickers are loaded when
+// the markets are acquired if the live state is sharded.
+func (c *components) startTickers(ctx context.Context) lifecycle.Hook {
+	if c.ownership != nil {
+		c.tickersReady.Done()
+		return func(context.Context) error { return nil }
+	}
+	markets := c.markets.Markets()
+	ids := make([]string, 0, len(markets))
+	names := make([]string, 0, len(markets))
+	for _, m := range markets {
+		ids = append(ids, m.ID)
+		names = append(names, m.Name)
+	}
+	c.dealService.Hold(ids...)
+	return lifecycle.Run(ctx, func(ctx context.Context) {
+		started := time.Now()
+		if err := c.loadTickers(ctx, names...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't load tickers")
+		}
+		c.dealService.Release(ctx, c.currentCandles, ids...)
+		c.tickersReady.Done()
+		logger.FromContext(ctx).
+			WithField("count", len(names)).
+			WithField("elapsed", time.Since(started).String()).
+			Infof("loaded tickers")
+	})
+}
+
+func (c *components) startGRPC(ctx context.Context) (lifecycle.Hook, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", c.conf.GRPCConfig.Port))
+	if err != nil {
+		return nil, err
+	}
+	backpressure, err := consumer.ParseBackpressure(c.conf.DealSubscriberConfig.Backpressure)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	ohlcvSrv := server.NewOhlcv(
+		service.NewCandle(repository.NewCandle(c.dealsCollection)),
+		c.klineService,
+		c.dealService,
+		c.dealConsumer,
+		c.candleConsumer,
+		c.tickerCache,
+		c.currentCandles,
+		c.markets,
+		consumer.SubscriberPolicy{
+			Backpressure: backpressure,
+			BufferSize:   c.conf.DealSubscriberConfig.BufferSize,
+			MaxDrops:     c.conf.DealSubscriberConfig.MaxDrops,
+			BlockTimeout: c.conf.DealSubscriberConfig.BlockTimeout,
+		},
+	)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), metrics.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), metrics.StreamServerInterceptor),
+	)
+	ohlcv.RegisterOHLCVServiceServer(s, ohlcvSrv)
+	healthSrv := grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, healthSrv)
+	reflection.Register(s)
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("gRPC server failed")
+		}
+	}()
+	return lifecycle.Hooks(
+		stopGRPC(s, c.conf.ShutdownConfig.GRPCGracePeriod),
+		lifecycle.Run(ctx, func(ctx context.Context) {
+			health.RunGRPC(ctx, c.checker, healthSrv, healthCheckInterval, ohlcv.OHLCVService_ServiceDesc.ServiceName)
+		}),
+	), nil
+}
+
+// stopGRPC waits for the active calls within the grace period, the streams
+// are cut off then.
+func stopGRPC(s *grpc.Server, gracePeriod time.Duration) lifecycle.Hook {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, gracePeriod)
+		defer cancel()
+		if err := lifecycle.WaitDone(ctx, lifecycle.Go(s.GracefulStop)); err != nil {
+			s.Stop()
+		}
+		return nil
+	}
+}
+
+// healthCheckInterval is the period of the gRPC health status updates.
+const healthCheckInterval = 10 * time.Second
+
+// newChecker registers the readiness checks of the dependencies.
+func newChecker(
+	conf infra.HealthConfig,
+	mongoDbClient *mongoDriver.Client,
+	dealService *service.Deal,
+	marketCache *market.Cache,
+	candlesReady *health.Flag,
+	tickersReady *health.Flag,
+	stopping *health.Flag,
+) *health.Checker {
+	checker := health.NewChecker(conf.CheckTimeout)
+	checker.Register("mongo", func(ctx context.Context) error {
+		return mongoDbClient.Ping(ctx, readpref.Primary())
+	})
+	if conf.MaxDealAge > 0 {
+		checker.Register("deals", health.MaxAge(dealService.LastConsumedAt, conf.MaxDealAge))
+	}
+	if conf.MaxMarketsAge > 0 {
+		checker.Register("markets", health.MaxAge(marketCache.RefreshedAt, conf.MaxMarketsAge))
+	}
+	checker.Register("current_candles", candlesReady.Check)
+	checker.Register("tickers", tickersReady.Check)
+	checker.Register("shutdown", func(context.Context) error {
+		if stopping.IsDone() {
+			return errShuttingDown
+		}
+		return nil
+	})
+	return checker
+}
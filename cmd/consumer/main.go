@@ -2,128 +2,103 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-
-	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
-
-	"bitbucket.org/novatechnologies/ohlcv/internal/model"
-	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
-	"bitbucket.org/novatechnologies/ohlcv/internal/service"
-	"bitbucket.org/novatechnologies/ohlcv/protocol/ohlcv"
-
-	"log"
-	"net"
 	"os"
-	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
-
-	"bitbucket.org/novatechnologies/common/events/topics"
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"bitbucket.org/novatechnologies/ohlcv/client/market"
-
-	"bitbucket.org/novatechnologies/ohlcv/api/http"
 	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
 	"bitbucket.org/novatechnologies/ohlcv/infra"
 	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
-	"bitbucket.org/novatechnologies/ohlcv/infra/centrifuge"
+	"bitbucket.org/novatechnologies/ohlcv/infra/health"
+	"bitbucket.org/novatechnologies/ohlcv/infra/lifecycle"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
 	"bitbucket.org/novatechnologies/ohlcv/infra/mongo"
-	"bitbucket.org/novatechnologies/ohlcv/internal/server"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
+	"bitbucket.org/novatechnologies/ohlcv/internal/service"
 )
 
 func main() {
+	role := flag.String("role", roleAll, "components to run: "+strings.Join(roleNames(), ", "))
+	flag.Parse()
 	ctx := infra.GetContext()
+	components, ok := roles[*role]
+	if !ok {
+		logger.FromContext(ctx).WithField("role", *role).Errorf("unknown role")
+		os.Exit(2)
+	}
 	conf := infra.SetConfig("./config/.env")
-
-	csmr := infra.NewConsumer(ctx, conf.KafkaConfig)
-	eventsBroker := broker.NewInMemory()
-	fmt.Println(model.GetAvailableResolutions())
-	marketsMap, marketsInfo := buildAvailableMarkets(conf)
-	broadcaster := centrifuge.NewBroadcaster(
-		centrifuge.NewPublisher(conf.CentrifugeConfig),
-		eventsBroker,
-		marketsMap,
-	)
-	broadcaster.SubscribeForCharts()
-	mongoDbClient := mongo.NewMongoClient(ctx, conf.MongoDbConfig)
-	dealsCollection := mongo.GetOrCreateDealsCollection(
-		ctx,
-		mongoDbClient,
-		conf.MongoDbConfig,
-	)
-
-	dealChannel := make(chan *model.Deal, 1024)
-	dealRepository := repository.NewDeal(dealsCollection, marketsMap, marketsInfo)
-	tickerCache := consumer.NewTicker(marketsMap)
-	dealService := service.NewDeal(dealRepository, tickerCache, marketsMap, dealChannel)
-
-	go dealService.LoadCache(ctx)
-
-	go tickerCache.ConsumeNewDeals(ctx)
-
-	// Start consuming, preparing, savFApiV3Ticker24hrGeting deals into DB and notifying others.
-	dealsTopic := conf.KafkaConfig.TopicPrefix + "_" + topics.MatcherMDDeals
-
-	candleService := candle.NewService(&candle.Storage{DealsDbCollection: dealsCollection}, new(candle.Aggregator), eventsBroker)
-	klineRepository := repository.NewKline(dealsCollection)
-	klineService := service.NewKline(klineRepository)
-	updatesStream := make(chan domain.Candle, 512)
-	go listenCurrentCandlesUpdates(ctx, updatesStream, eventsBroker, marketsMap)
-	currentCandles := initCurrentCandles(ctx, candleService, marketsMap, updatesStream)
-	dealService.RunConsuming(ctx, csmr, dealsTopic, currentCandles)
-
-	httpServer := http.NewServer(candleService, dealService, conf)
-	httpServer.Start(ctx)
-
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", conf.GRPCConfig.Port))
-	if err != nil {
-		log.Fatal(err)
+	if err := checkRole(*role, conf); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Errorf("invalid role")
+		os.Exit(2)
 	}
-	dealConsumer := consumer.NewDeal(dealChannel)
-	go dealConsumer.Consume(ctx)
-	ohlcvSrv := server.NewOhlcv(service.NewCandle(repository.NewCandle(dealsCollection)), klineService, dealService, dealConsumer)
-	s := grpc.NewServer()
-	ohlcv.RegisterOHLCVServiceServer(s, ohlcvSrv)
 
-	reflection.Register(s)
-	go func() {
-		err = s.Serve(listener)
+	instance := conf.Instance
+	if instance == "" {
+		hostname, err := os.Hostname()
 		if err != nil {
-			log.Fatal(err)
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't name the instance")
+			os.Exit(1)
 		}
-	}()
+		instance = hostname
+	}
 
-	//shutdown
-	signalCh := make(chan os.Signal)
-	signal.Notify(signalCh, os.Interrupt)
-	_ = <-signalCh
-	httpServer.Stop(ctx)
-	s.GracefulStop()
-	return
+	stopping := new(health.Flag)
+	app := newApp(conf, *role, instance, stopping)
+	if err := app.Start(ctx, components...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Errorf("can't start")
+		os.Exit(1)
+	}
+	logger.FromContext(ctx).WithField("role", *role).Infof("started")
+
+	lifecycle.WaitSignal(ctx, os.Interrupt, syscall.SIGTERM)
+	stopping.Done()
+	if err := app.Stop(ctx); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Errorf("unclean shutdown")
+		os.Exit(1)
+	}
 }
 
-func listenCurrentCandlesUpdates(ctx context.Context, updates <-chan domain.Candle, eventsBroker *broker.EventsInMemory, marketsMap map[string]string) {
+// listenCurrentCandlesUpdates broadcasts current candles updates as charts and
+// forwards them with market names as symbols into candleChannel.
+func listenCurrentCandlesUpdates(
+	ctx context.Context,
+	updates <-chan domain.Candle,
+	eventsBroker *broker.EventsInMemory,
+	markets *domain.MarketRegistry,
+	candleChannel chan<- domain.Candle,
+) {
 	chartStream := make(chan *domain.Chart)
-	defer close(chartStream)
 	batchStream := domain.Microbatching(ctx, chartStream, 10)
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case batch := <-batchStream:
-				eventsBroker.Publish(domain.EvTypeCharts, domain.NewEvent(ctx, batch))
-			}
+	published := lifecycle.Go(func() {
+		for batch := range batchStream {
+			metrics.MicrobatchSize.Observe(float64(len(batch)))
+			publishCharts(ctx, eventsBroker, batch)
 		}
+	})
+	// the last batch is flushed when the updates stream is closed
+	defer func() {
+		close(chartStream)
+		<-published
 	}()
 	for upd := range updates {
-		symbol := marketsMap[upd.Symbol]
-		if symbol == "" {
+		// ends the wait of the traced update in the stream
+		trace.SpanFromContext(upd.Ctx).End()
+		symbol, ok := markets.Name(upd.Symbol)
+		if !ok {
 			symbol = upd.Symbol
 		}
 		chart := domain.Chart{
@@ -135,6 +110,15 @@ func listenCurrentCandlesUpdates(ctx context.Context, updates <-chan domain.Cand
 			C:          []primitive.Decimal128{upd.Close},
 			V:          []primitive.Decimal128{upd.Volume},
 			T:          []int64{upd.OpenTime.Unix()},
+			Final:      upd.Closed,
+			Ctx:        upd.Ctx,
+		}
+		upd.Symbol = symbol
+		select {
+		case candleChannel <- upd:
+		default:
+			metrics.ChannelDropped.WithLabelValues("candle").Inc()
+			logger.FromContext(ctx).Errorf("candle channel overloaded")
 		}
 		select {
 		case <-ctx.Done():
@@ -144,35 +128,106 @@ func listenCurrentCandlesUpdates(ctx context.Context, updates <-chan domain.Cand
 	}
 }
 
-func initCurrentCandles(ctx context.Context, service *candle.Service, marketsMap map[string]string, updatesStream chan domain.Candle) candle.CurrentCandles {
-	candles := candle.NewCurrentCandles(ctx, updatesStream)
+// publishCharts publishes the batch of charts. The batch of traced charts is
+// published in a span which continues the trace of the first chart and links
+// the others.
+func publishCharts(ctx context.Context, eventsBroker *broker.EventsInMemory, batch []*domain.Chart) {
+	ctxs := make([]context.Context, 0, len(batch))
+	for _, chart := range batch {
+		ctxs = append(ctxs, chart.Ctx)
+	}
+	links := tracing.Links(ctxs...)
+	if len(links) == 0 {
+		eventsBroker.Publish(domain.EvTypeCharts, domain.NewEvent(ctx, batch))
+		return
+	}
+	ctx, span := tracing.Tracer().Start(
+		trace.ContextWithSpanContext(ctx, links[0].SpanContext),
+		"charts.publish",
+		trace.WithLinks(links[1:]...),
+		trace.WithAttributes(attribute.Int("charts", len(batch))),
+	)
+	defer span.End()
+	eventsBroker.Publish(domain.EvTypeCharts, domain.NewEvent(ctx, batch))
+}
+
+func initCurrentCandles(ctx context.Context, service *candle.Service, candles candle.CurrentCandles, markets []market.Market) error {
 	count := 0
 	started := time.Now()
-	for marketId, marketName := range marketsMap {
-		for _, resolution := range model.GetAvailableResolutions() {
-			chart, err := service.GetCurrentCandle(ctx, marketName, resolution)
-			if err != nil {
-				log.Fatal("can't GetCurrentCandle to initCurrentCandles:" + err.Error())
-			}
-			currentCandle, err := domain.ChartToCurrentCandle(chart, resolution)
-			if err != nil {
-				log.Fatalf("can't chartToCurrentCandle to initCurrentCandles: %s, chart: %+v", err, chart)
-			}
-			err = candles.AddCandle(marketId, resolution, currentCandle)
-			if err != nil {
-				log.Fatal("can't AddCandle to initCurrentCandles:" + err.Error())
-			}
-			count++
+	for _, m := range markets {
+		if err := initMarketCurrentCandles(ctx, service, candles, m); err != nil {
+			return fmt.Errorf("can't initCurrentCandles: %w", err)
 		}
+		count += len(model.GetAvailableResolutions())
 	}
 	logger.FromContext(ctx).
 		WithField("count", count).
 		WithField("elapsed", time.Since(started).String()).
 		Infof("initiated candles from MongoDb")
-	return candles
+	return nil
+}
+
+// initMarketCurrentCandles loads the current candles of every resolution of
+// the market, the aliases share the candle of their canonical resolution.
+func initMarketCurrentCandles(ctx context.Context, service *candle.Service, candles candle.CurrentCandles, m market.Market) error {
+	for _, resolution := range model.GetAvailableResolutions() {
+		if resolution.Canonical() != resolution {
+			continue
+		}
+		chart, err := service.GetCurrentCandle(ctx, m.Name, resolution)
+		if err != nil {
+			return fmt.Errorf("can't GetCurrentCandle: %w", err)
+		}
+		currentCandle, err := domain.ChartToCurrentCandle(chart, resolution)
+		if err != nil {
+			return fmt.Errorf("can't chartToCurrentCandle: %w, chart: %+v", err, chart)
+		}
+		if err = candles.AddCandle(m.ID, resolution, currentCandle); err != nil {
+			return fmt.Errorf("can't AddCandle: %w", err)
+		}
+	}
+	return nil
 }
 
-func buildAvailableMarkets(conf infra.Config) (map[string]string, []market.Market) {
+// rebuildMarketCurrentCandles rebuilds the current candles of the acquired
+// market from the stored deals until the last one. The saving of the deals
+// may lag behind, so the held deals made after the last stored one are
+// applied on release, the stored ones are skipped.
+func rebuildMarketCurrentCandles(
+	ctx context.Context,
+	service *candle.Service,
+	deals *service.Deal,
+	candles candle.CurrentCandles,
+	m market.Market,
+) error {
+	position, err := deals.GetLastPosition(ctx, m.Name)
+	if err != nil {
+		return fmt.Errorf("can't GetLastPosition: %w", err)
+	}
+	state := candle.MarketCandles{Position: position}
+	for _, resolution := range model.GetAvailableResolutions() {
+		if resolution.Canonical() != resolution {
+			continue
+		}
+		chart, err := service.GetCurrentCandleUntil(ctx, m.Name, resolution, position.T)
+		if err != nil {
+			return fmt.Errorf("can't GetCurrentCandleUntil: %w", err)
+		}
+		currentCandle, err := domain.ChartToCurrentCandle(chart, resolution)
+		if err != nil {
+			return fmt.Errorf("can't chartToCurrentCandle: %w, chart: %+v", err, chart)
+		}
+		// the candle without deals is replaced with the fresh one
+		currentCandle.Resolution = resolution
+		state.Candles = append(state.Candles, currentCandle)
+	}
+	candles.Restore(m.ID, state)
+	return nil
+}
+
+// buildAvailableMarkets loads the markets with retries, the last good list is
+// loaded from the snapshot if the markets service is down.
+func buildAvailableMarkets(ctx context.Context, conf infra.Config, mongoDbClient *mongoDriver.Client) (*market.Cache, []market.Market, error) {
 	marketClient, err := market.New(
 		market.Config{ServerURL: conf.ExchangeMarketsServerURL, ServerTLS: conf.ExchangeMarketsServerSSL},
 		market.NewErrorProcessor(map[string]string{}),
@@ -180,11 +235,35 @@ func buildAvailableMarkets(conf infra.Config) (map[string]string, []market.Marke
 		conf.ExchangeMarketsToken,
 	)
 	if err != nil {
-		log.Fatal("can't market.NewKline:" + err.Error())
+		return nil, nil, fmt.Errorf("can't market.New: %w", err)
 	}
-	markets, err := marketClient.List(context.Background())
-	if err != nil {
-		log.Fatal("can't marketClient.List:" + err.Error())
+	var snapshot market.Snapshot
+	if conf.ExchangeMarketsSnapshotPath != "" {
+		if conf.LeaderConfig.Enabled {
+			// the followers would read the file written by the leader only
+			return nil, nil, errors.New("the market snapshot path is set with the leader election enabled, the snapshot should be kept in MongoDb")
+		}
+		snapshot = market.NewFileSnapshot(conf.ExchangeMarketsSnapshotPath)
+	} else {
+		snapshot = repository.NewMarketSnapshot(
+			mongo.GetCollection(ctx, mongoDbClient, conf.MongoDbConfig, conf.MongoDbConfig.MarketSnapshotCollectionName),
+		)
+	}
+	cache := market.NewCache(
+		market.NewRetry(marketClient, market.DefaultRetryConfig),
+		market.CacheConfig{Snapshot: snapshot},
+	)
+	if err = cache.Load(ctx); err != nil {
+		if !cache.Loaded() {
+			return nil, nil, fmt.Errorf("can't load markets: %w", err)
+		}
+		logger.FromContext(ctx).WithField("err", err).Errorf("markets are loaded with an error")
+	}
+	if age := cache.Age(time.Now()); age > service.MarketSyncInterval {
+		logger.FromContext(ctx).
+			WithField("age", age.String()).
+			Errorf("markets are loaded from the snapshot")
 	}
-	return domain.GetAvailableMarketsMap(markets), markets
+	markets, _ := cache.List(ctx)
+	return cache, markets, nil
 }
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AggTradeWindow is the longest time between the first and the last deal of
+// an aggregate trade.
+const AggTradeWindow = 100 * time.Millisecond
+
+// AggTrade is a sequence of the deals of a market at the same price with the
+// same taker side.
+type AggTrade struct {
+	Symbol       string
+	Price        primitive.Decimal128
+	Qty          primitive.Decimal128
+	FirstId      string
+	LastId       string
+	FirstTime    time.Time
+	LastTime     time.Time
+	IsBuyerMaker bool
+	Count        int
+	// Last is the position of the last deal in the storage.
+	Last model.TradesCursor
+}
+
+func newAggTrade(d *model.Deal) *AggTrade {
+	return &AggTrade{
+		Symbol:       d.Data.Market,
+		Price:        d.Data.Price,
+		Qty:          d.Data.Volume,
+		FirstId:      d.Data.DealId,
+		LastId:       d.Data.DealId,
+		FirstTime:    d.T.Time(),
+		LastTime:     d.T.Time(),
+		IsBuyerMaker: d.Data.IsBuyerMaker,
+		Count:        1,
+		Last:         d.Cursor(),
+	}
+}
+
+// add adds the deal if it continues the aggregate trade.
+func (a *AggTrade) add(d *model.Deal, window time.Duration) (bool, error) {
+	t := d.T.Time()
+	if d.Data.Market != a.Symbol || d.Data.IsBuyerMaker != a.IsBuyerMaker ||
+		t.Before(a.LastTime) || t.Sub(a.FirstTime) > window {
+		return false, nil
+	}
+	if c, err := decimal128.Compare(d.Data.Price, a.Price); err != nil || c != 0 {
+		return false, err
+	}
+	qty, err := decimal128.Add(a.Qty, d.Data.Volume)
+	if err != nil {
+		return false, err
+	}
+	a.Qty = qty
+	a.LastId = d.Data.DealId
+	a.LastTime = t
+	a.Last = d.Cursor()
+	a.Count++
+	return true, nil
+}
+
+// AggTrades collapses the consecutive deals of every market into aggregate
+// trades.
+type AggTrades struct {
+	window time.Duration
+	open   map[string]*AggTrade
+}
+
+func NewAggTrades(window time.Duration) *AggTrades {
+	return &AggTrades{
+		window: window,
+		open:   make(map[string]*AggTrade),
+	}
+}
+
+// Add adds the deal and returns the aggregate trade of its market which the
+// deal doesn't continue.
+func (a *AggTrades) Add(d *model.Deal) (*AggTrade, error) {
+	open, ok := a.open[d.Data.Market]
+	if !ok {
+		a.open[d.Data.Market] = newAggTrade(d)
+		return nil, nil
+	}
+	added, err := open.add(d, a.window)
+	if err != nil || added {
+		return nil, err
+	}
+	a.open[d.Data.Market] = newAggTrade(d)
+	return open, nil
+}
+
+// Expire returns the aggregate trades which can't be continued by the deals
+// made after now, oldest first.
+func (a *AggTrades) Expire(now time.Time) []*AggTrade {
+	return a.take(func(t *AggTrade) bool {
+		return now.Sub(t.FirstTime) > a.window
+	})
+}
+
+// Flush returns all the aggregate trades, oldest first.
+func (a *AggTrades) Flush() []*AggTrade {
+	return a.take(func(*AggTrade) bool {
+		return true
+	})
+}
+
+func (a *AggTrades) take(match func(*AggTrade) bool) []*AggTrade {
+	var taken []*AggTrade
+	for market, t := range a.open {
+		if match(t) {
+			taken = append(taken, t)
+			delete(a.open, market)
+		}
+	}
+	sort.Slice(taken, func(i, j int) bool {
+		return taken[i].FirstTime.Before(taken[j].FirstTime)
+	})
+	return taken
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAggTrades(t *testing.T) {
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	deal := func(id, market string, ms int, price, qty string, isBuyerMaker bool) *model.Deal {
+		return &model.Deal{
+			T: primitive.NewDateTimeFromTime(start.Add(time.Duration(ms) * time.Millisecond)),
+			Data: model.DealData{
+				Price:        model.MustParseDecimal(price),
+				Volume:       model.MustParseDecimal(qty),
+				Market:       market,
+				DealId:       id,
+				IsBuyerMaker: isBuyerMaker,
+			},
+		}
+	}
+	a := NewAggTrades(AggTradeWindow)
+	var closed []*AggTrade
+	for _, d := range []*model.Deal{
+		deal("1", "ETH_BTC", 0, "10", "1", false),
+		deal("2", "ETH_BTC", 10, "10.0", "0.5", false),
+		// other market doesn't break the sequence
+		deal("3", "BTC_USDT", 20, "30000", "1", true),
+		deal("4", "ETH_BTC", 30, "10", "2", false),
+		// other side
+		deal("5", "ETH_BTC", 40, "10", "1", true),
+		// other price
+		deal("6", "ETH_BTC", 50, "11", "1", true),
+		// out of the window
+		deal("7", "ETH_BTC", 200, "11", "1", true),
+	} {
+		trade, err := a.Add(d)
+		require.NoError(t, err)
+		if trade != nil {
+			closed = append(closed, trade)
+		}
+	}
+	require.Len(t, closed, 3)
+	assert.Equal(t, "1", closed[0].FirstId)
+	assert.Equal(t, "4", closed[0].LastId)
+	assert.Equal(t, 3, closed[0].Count)
+	assert.Equal(t, "3.5", closed[0].Qty.String())
+	assert.False(t, closed[0].IsBuyerMaker)
+	assert.True(t, start.Add(30*time.Millisecond).Equal(closed[0].LastTime))
+	assert.Equal(t, "5", closed[1].FirstId)
+	assert.Equal(t, "5", closed[1].LastId)
+	assert.Equal(t, "6", closed[2].FirstId)
+
+	expired := a.Expire(start.Add(150 * time.Millisecond))
+	require.Len(t, expired, 1)
+	assert.Equal(t, "3", expired[0].FirstId)
+
+	flushed := a.Flush()
+	require.Len(t, flushed, 1)
+	assert.Equal(t, "7", flushed[0].FirstId)
+	assert.Empty(t, a.Flush())
+}
@@ -11,8 +11,14 @@ type ChartChannel struct {
 	Resolution model.Resolution
 }
 
-const CandleChartChannelPrefix = "candle_chart"
+const (
+	CandleChartChannelPrefix = "candle_chart"
+	TickerChannelPrefix      = "ticker"
+	// TickerAllChannel receives updates of all market tickers.
+	TickerAllChannel = "ticker_all"
+)
 
 type Broadcaster interface {
 	BroadcastCandleCharts(ctx context.Context, cht []*Chart)
+	BroadcastTickers(ctx context.Context, tickers []*TickerPriceChangeStatistics)
 }
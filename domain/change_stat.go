@@ -1,25 +1,25 @@
 package domain
 
 type TickerPriceChangeStatistics struct {
-	Symbol             string
-	PriceChange        string
-	PriceChangePercent string
-	WeightedAvgPrice   string
-	PrevClosePrice     string
-	LastPrice          string
-	LastQty            string
-	BidPrice           string
-	BidQty             string
-	AskPrice           string
-	AskQty             string
-	OpenPrice          string
-	HighPrice          string
-	LowPrice           string
-	Volume             string
-	QuoteVolume        string
-	OpenTime           int64
-	CloseTime          int64
-	FirstId            string
-	LastId             string
-	Count              int
+	Symbol             string `json:"symbol"`
+	PriceChange        string `json:"priceChange"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	WeightedAvgPrice   string `json:"weightedAvgPrice"`
+	PrevClosePrice     string `json:"prevClosePrice"`
+	LastPrice          string `json:"lastPrice"`
+	LastQty            string `json:"lastQty"`
+	BidPrice           string `json:"bidPrice"`
+	BidQty             string `json:"bidQty"`
+	AskPrice           string `json:"askPrice"`
+	AskQty             string `json:"askQty"`
+	OpenPrice          string `json:"openPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+	OpenTime           int64  `json:"openTime"`
+	CloseTime          int64  `json:"closeTime"`
+	FirstId            string `json:"firstId"`
+	LastId             string `json:"lastId"`
+	Count              int    `json:"count"`
 }
@@ -8,7 +8,8 @@ import (
 type EventType = string
 
 const (
-	EvTypeCharts = "charts"
+	EvTypeCharts  = "charts"
+	EvTypeTickers = "tickers"
 )
 
 type EventHandler = func(m *Event) error
@@ -76,6 +77,10 @@ func (m *Event) MustGetCharts() []*Chart {
 	return m.payload.([]*Chart)
 }
 
+func (m *Event) MustGetTickers() []*TickerPriceChangeStatistics {
+	return m.payload.([]*TickerPriceChangeStatistics)
+}
+
 func (m *Event) MustGetDeals() []*model.Deal {
 	return m.payload.([]*model.Deal)
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+)
+
+const (
+	// KlinesDefaultLimit is the number of klines returned by default.
+	KlinesDefaultLimit = 500
+	// KlinesMaxLimit is the maximal number of klines of a request.
+	KlinesMaxLimit = 1000
+)
+
+var klineIntervals = map[string]model.Resolution{
+	"1m":  model.Candle1MResolution,
+	"3m":  model.Candle3MResolution,
+	"5m":  model.Candle5MResolution,
+	"15m": model.Candle15MResolution,
+	"30m": model.Candle30MResolution,
+	"1h":  model.Candle1HResolution,
+	"2h":  model.Candle2HResolution,
+	"4h":  model.Candle4HResolution,
+	"6h":  model.Candle6HResolution,
+	"12h": model.Candle12HResolution,
+	"1d":  model.Candle1DResolution,
+	"1w":  model.Candle1WResolution,
+	"1M":  model.Candle1MHResolution,
+}
+
+// ParseKlineInterval parses a Binance kline interval, e.g. 1m, 4h, 1d, 1w
+// or 1M for a month.
+func ParseKlineInterval(s string) (model.Resolution, error) {
+	resolution, ok := klineIntervals[s]
+	if !ok {
+		return "", fmt.Errorf("invalid interval %q", s)
+	}
+	return resolution, nil
+}
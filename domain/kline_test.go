@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKlineInterval(t *testing.T) {
+	for s, r := range map[string]model.Resolution{
+		"1m":  model.Candle1MResolution,
+		"30m": model.Candle30MResolution,
+		"1h":  model.Candle1HResolution,
+		"12h": model.Candle12HResolution,
+		"1d":  model.Candle1DResolution,
+		"1w":  model.Candle1WResolution,
+		"1M":  model.Candle1MHResolution,
+	} {
+		got, err := ParseKlineInterval(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, r, got, s)
+		unit, size := model.GetResolution(got)
+		assert.NotEmpty(t, unit, s)
+		assert.Positive(t, size, s)
+	}
+	for _, s := range []string{"", "1", "60", "1D", "1s", "2m", "1H", "1mo"} {
+		_, err := ParseKlineInterval(s)
+		assert.Error(t, err, s)
+	}
+	unit, size := model.GetResolution(model.Candle30MResolution)
+	assert.Equal(t, model.MinuteUnit, unit)
+	assert.Equal(t, 30, size)
+}
@@ -1,11 +1,31 @@
 package domain
 
 import (
-	"strings"
-
 	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DefaultMarketPrecision is used for the markets with unknown precision.
+var DefaultMarketPrecision = MarketPrecision{Price: 4, Quote: 8}
+
+// MarketPrecision is the number of decimal places of the prices and of the
+// quoted currency amounts of a market.
+type MarketPrecision struct {
+	Price int32
+	Quote int32
+}
+
+// FormatPrice rounds half away from zero to the price precision.
+func (p MarketPrecision) FormatPrice(d primitive.Decimal128) string {
+	return decimal128.StringFixed(d, p.Price)
+}
+
+// FormatQuote rounds half away from zero to the quoted currency precision.
+func (p MarketPrecision) FormatQuote(d primitive.Decimal128) string {
+	return decimal128.StringFixed(d, p.Quote)
+}
+
 func GetAvailableMarketsMap(markets []market.Market) map[string]string {
 	m := map[string]string{}
 	for _, v := range markets {
@@ -14,8 +34,14 @@ func GetAvailableMarketsMap(markets []market.Market) map[string]string {
 	return m
 }
 
-func NormalizeMarketName(market string) string {
-	market = strings.Replace(market, "%2F", "_", -1)
-	market = strings.Replace(market, "/", "_", -1)
-	return market
+// GetMarketsPrecision maps the market names to their precision.
+func GetMarketsPrecision(markets []market.Market) map[string]MarketPrecision {
+	m := map[string]MarketPrecision{}
+	for _, v := range markets {
+		m[v.Name] = MarketPrecision{
+			Price: int32(v.Precision),
+			Quote: int32(v.QuotedPrecision),
+		}
+	}
+	return m
 }
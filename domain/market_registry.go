@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"sort"
+	"sync"
+
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+)
+
+type MarketEventType int
+
+const (
+	MarketAdded MarketEventType = iota
+	MarketRemoved
+	MarketRenamed
+)
+
+func (t MarketEventType) String() string {
+	switch t {
+	case MarketAdded:
+		return "added"
+	case MarketRemoved:
+		return "removed"
+	case MarketRenamed:
+		return "renamed"
+	}
+	return "unknown"
+}
+
+// MarketEvent is a change of the market list, OldName is set on rename.
+type MarketEvent struct {
+	Type    MarketEventType
+	Market  market.Market
+	OldName string
+}
+
+// MarketRegistry keeps the markets by their ids and names and notifies the
+// subscribers about the changes of the list.
+type MarketRegistry struct {
+	mu           sync.RWMutex
+	byID         map[string]market.Market
+	idsByName    map[string]string
+	symbolConfig SymbolConfig
+	symbols      symbolIndex
+	subscribers  []func(MarketEvent)
+}
+
+func NewMarketRegistry(markets []market.Market) *MarketRegistry {
+	r := &MarketRegistry{
+		byID:         make(map[string]market.Market, len(markets)),
+		idsByName:    make(map[string]string, len(markets)),
+		symbolConfig: DefaultSymbolConfig,
+	}
+	for _, m := range markets {
+		r.byID[m.ID] = m
+		r.idsByName[m.Name] = m.ID
+	}
+	r.symbols = newSymbolIndex(r.byID, r.idsByName, r.symbolConfig)
+	return r
+}
+
+// SetSymbolConfig changes the symbols resolved to the markets.
+func (r *MarketRegistry) SetSymbolConfig(config SymbolConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.symbolConfig = config
+	r.symbols = newSymbolIndex(r.byID, r.idsByName, config)
+}
+
+// Resolve returns the market of the symbol in any of the configured formats.
+func (r *MarketRegistry) Resolve(symbol string) (market.Market, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.symbols.resolve(symbol)
+	if !ok {
+		return market.Market{}, false
+	}
+	return r.byID[id], true
+}
+
+// ResolveName returns the market name of the symbol.
+func (r *MarketRegistry) ResolveName(symbol string) (string, bool) {
+	m, ok := r.Resolve(symbol)
+	return m.Name, ok
+}
+
+// Subscribe registers a function called on every change after it's applied.
+// It's called synchronously, so it shouldn't block.
+func (r *MarketRegistry) Subscribe(f func(MarketEvent)) {
+	r.mu.Lock()
+	r.subscribers = append(r.subscribers, f)
+	r.mu.Unlock()
+}
+
+// Market returns the market of the id.
+func (r *MarketRegistry) Market(id string) (market.Market, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.byID[id]
+	return m, ok
+}
+
+// Name returns the name of the market id.
+func (r *MarketRegistry) Name(id string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.byID[id]
+	return m.Name, ok
+}
+
+// ID returns the id of the market name.
+func (r *MarketRegistry) ID(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.idsByName[name]
+	return id, ok
+}
+
+// Names returns the sorted market names.
+func (r *MarketRegistry) Names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.idsByName))
+	for name := range r.idsByName {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Map returns a copy of the market names by ids.
+func (r *MarketRegistry) Map() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m := make(map[string]string, len(r.byID))
+	for id, v := range r.byID {
+		m[id] = v.Name
+	}
+	return m
+}
+
+// Markets returns the markets sorted by names.
+func (r *MarketRegistry) Markets() []market.Market {
+	r.mu.RLock()
+	markets := make([]market.Market, 0, len(r.byID))
+	for _, m := range r.byID {
+		markets = append(markets, m)
+	}
+	r.mu.RUnlock()
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].Name < markets[j].Name
+	})
+	return markets
+}
+
+// Precision returns the precision of the market name or the default one.
+func (r *MarketRegistry) Precision(name string) MarketPrecision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.idsByName[name]
+	if !ok {
+		return DefaultMarketPrecision
+	}
+	m := r.byID[id]
+	return MarketPrecision{
+		Price: int32(m.Precision),
+		Quote: int32(m.QuotedPrecision),
+	}
+}
+
+// Update replaces the markets with the list and notifies the subscribers
+// about the added, removed and renamed markets.
+func (r *MarketRegistry) Update(markets []market.Market) []MarketEvent {
+	byID := make(map[string]market.Market, len(markets))
+	idsByName := make(map[string]string, len(markets))
+	for _, m := range markets {
+		byID[m.ID] = m
+		idsByName[m.Name] = m.ID
+	}
+
+	r.mu.Lock()
+	var events []MarketEvent
+	for id, m := range byID {
+		old, ok := r.byID[id]
+		switch {
+		case !ok:
+			events = append(events, MarketEvent{Type: MarketAdded, Market: m})
+		case old.Name != m.Name:
+			events = append(events, MarketEvent{Type: MarketRenamed, Market: m, OldName: old.Name})
+		}
+	}
+	for id, m := range r.byID {
+		if _, ok := byID[id]; !ok {
+			events = append(events, MarketEvent{Type: MarketRemoved, Market: m})
+		}
+	}
+	r.byID, r.idsByName = byID, idsByName
+	r.symbols = newSymbolIndex(byID, idsByName, r.symbolConfig)
+	subscribers := r.subscribers
+	r.mu.Unlock()
+
+	// a name of a removed market can be taken by a renamed or an added one
+	order := map[MarketEventType]int{MarketRemoved: 0, MarketRenamed: 1, MarketAdded: 2}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return order[events[i].Type] < order[events[j].Type]
+		}
+		return events[i].Market.Name < events[j].Market.Name
+	})
+	for _, e := range events {
+		for _, f := range subscribers {
+			f(e)
+		}
+	}
+	return events
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketRegistry_Update(t *testing.T) {
+	r := NewMarketRegistry([]market.Market{
+		{ID: "1", Name: "ETH_BTC", Precision: 6, QuotedPrecision: 8},
+		{ID: "2", Name: "BTC_USDT"},
+		{ID: "3", Name: "LTC_BTC"},
+	})
+	var notified []MarketEvent
+	r.Subscribe(func(e MarketEvent) {
+		notified = append(notified, e)
+	})
+
+	// the name of the removed market is taken by the renamed one
+	events := r.Update([]market.Market{
+		{ID: "1", Name: "ETH_BTC", Precision: 6, QuotedPrecision: 8},
+		{ID: "3", Name: "BTC_USDT"},
+		{ID: "4", Name: "XRP_BTC"},
+	})
+
+	assert.Equal(t, []MarketEvent{
+		{Type: MarketRemoved, Market: market.Market{ID: "2", Name: "BTC_USDT"}},
+		{Type: MarketRenamed, Market: market.Market{ID: "3", Name: "BTC_USDT"}, OldName: "LTC_BTC"},
+		{Type: MarketAdded, Market: market.Market{ID: "4", Name: "XRP_BTC"}},
+	}, events)
+	assert.Equal(t, events, notified)
+	assert.Equal(t, []string{"BTC_USDT", "ETH_BTC", "XRP_BTC"}, r.Names())
+	assert.Equal(t, map[string]string{"1": "ETH_BTC", "3": "BTC_USDT", "4": "XRP_BTC"}, r.Map())
+	id, ok := r.ID("BTC_USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "3", id)
+	_, ok = r.Name("2")
+	assert.False(t, ok)
+	assert.Equal(t, MarketPrecision{Price: 6, Quote: 8}, r.Precision("ETH_BTC"))
+	assert.Equal(t, DefaultMarketPrecision, r.Precision("LTC_BTC"))
+
+	notified = nil
+	assert.Empty(t, r.Update(r.Markets()))
+	assert.Empty(t, notified)
+}
@@ -47,7 +47,10 @@ func mergeSameChart(batch []*Chart) []*Chart {
 	var ans []*Chart
 
 	for i := 0; i < len(batch); i++ {
+		// the final flag is of the last bar, so the closed one isn't merged
+		// with the next
 		if i+1 < len(batch) &&
+			!batch[i].Final &&
 			batch[i].Symbol == batch[i+1].Symbol &&
 			batch[i].Resolution == batch[i+1].Resolution &&
 			len(batch[i].T) == 1 &&
@@ -62,6 +65,8 @@ func mergeSameChart(batch []*Chart) []*Chart {
 				C:          append(batch[i].C, batch[i+1].C...),
 				V:          append(batch[i].V, batch[i+1].V...),
 				T:          append(batch[i].T, batch[i+1].T...),
+				Final:      batch[i+1].Final,
+				Ctx:        batch[i+1].Ctx,
 			})
 			i++
 		} else {
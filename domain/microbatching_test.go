@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
 	"time"
@@ -174,4 +175,16 @@ func Test_mergeSameChart(t *testing.T) {
 			batch,
 		)
 	})
+	t.Run("closed chart", func(t *testing.T) {
+		closed := &Chart{Symbol: "BTC", Resolution: "1min", T: []int64{time.Date(2020, 1, 20, 0, 00, 0, 0, time.Local).Unix()}, Final: true}
+		fresh := &Chart{Symbol: "BTC", Resolution: "1min", T: []int64{time.Date(2020, 1, 20, 0, 01, 0, 0, time.Local).Unix()}}
+		assert.True(t, mergeSameChart([]*Chart{closed})[0].Final)
+		merged := mergeSameChart([]*Chart{closed, fresh})
+		require.Len(t, merged, 2, "the closed candle isn't merged")
+		assert.True(t, merged[0].Final)
+		assert.Equal(t, closed.T, merged[0].T)
+		assert.False(t, merged[1].Final)
+		assert.Equal(t, fresh.T, merged[1].T)
+
+	})
 }
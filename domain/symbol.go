@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+)
+
+// SymbolFormat is a format of the market symbols accepted by the APIs.
+type SymbolFormat string
+
+const (
+	// SymbolFormatID is the market id, e.g. 352656ec-4ad4-4e8b-8dc4-2ddd3e7643b1.
+	SymbolFormatID SymbolFormat = "id"
+	// SymbolFormatName is the market name with any separator in any case,
+	// e.g. USDT_BTC, usdt/btc or USDT%2FBTC.
+	SymbolFormatName SymbolFormat = "name"
+	// SymbolFormatPair is the base and the quoted currencies with any
+	// separator in any case, e.g. BTC/USDT or btc-usdt.
+	SymbolFormatPair SymbolFormat = "pair"
+	// SymbolFormatConcat is the base and the quoted currencies without a
+	// separator, e.g. BTCUSDT.
+	SymbolFormatConcat SymbolFormat = "concat"
+)
+
+// DefaultSymbolFormats are all the formats, a symbol matching several
+// markets resolves to the one of the earlier format.
+var DefaultSymbolFormats = []SymbolFormat{SymbolFormatID, SymbolFormatName, SymbolFormatPair, SymbolFormatConcat}
+
+// SymbolConfig tells which symbols resolve to the markets. The aliases map
+// the extra symbols to the market names, they take precedence over the
+// formats.
+type SymbolConfig struct {
+	Formats []SymbolFormat
+	Aliases map[string]string
+}
+
+// DefaultSymbolConfig accepts all the formats without aliases.
+var DefaultSymbolConfig = SymbolConfig{Formats: DefaultSymbolFormats}
+
+// ParseSymbolConfig validates the format names, all the formats are used if
+// there are none.
+func ParseSymbolConfig(formats []string, aliases map[string]string) (SymbolConfig, error) {
+	config := SymbolConfig{Aliases: aliases}
+	if len(formats) == 0 {
+		config.Formats = DefaultSymbolFormats
+		return config, nil
+	}
+	for _, f := range formats {
+		format := SymbolFormat(strings.ToLower(strings.TrimSpace(f)))
+		switch format {
+		case SymbolFormatID, SymbolFormatName, SymbolFormatPair, SymbolFormatConcat:
+			config.Formats = append(config.Formats, format)
+		default:
+			return SymbolConfig{}, fmt.Errorf("unknown symbol format %q", f)
+		}
+	}
+	return config, nil
+}
+
+// symbolSeparators are replaced by the one of the market names.
+var symbolSeparators = strings.NewReplacer("/", "_", "-", "_", ":", "_", " ", "_")
+
+// symbolKey is the case and separator insensitive form of a symbol.
+func symbolKey(symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if unescaped, err := url.PathUnescape(symbol); err == nil {
+		symbol = unescaped
+	}
+	return symbolSeparators.Replace(strings.ToUpper(symbol))
+}
+
+// marketCurrencies returns the base and the quoted currencies of the market,
+// they're taken from the name QUOTED_BASE if the metadata is absent.
+func marketCurrencies(m market.Market) (string, string, bool) {
+	if m.BaseCurrency.Symbol != "" && m.QuotedCurrency.Symbol != "" {
+		return m.BaseCurrency.Symbol, m.QuotedCurrency.Symbol, true
+	}
+	parts := strings.Split(m.Name, "_")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[0], true
+}
+
+// symbolKeys returns the keys of the market in the format.
+func symbolKeys(m market.Market, format SymbolFormat) []string {
+	switch format {
+	case SymbolFormatID:
+		return []string{strings.ToLower(m.ID)}
+	case SymbolFormatName:
+		return []string{symbolKey(m.Name)}
+	case SymbolFormatPair:
+		if base, quote, ok := marketCurrencies(m); ok {
+			return []string{symbolKey(base + "_" + quote)}
+		}
+	case SymbolFormatConcat:
+		if base, quote, ok := marketCurrencies(m); ok {
+			return []string{strings.ToUpper(base + quote)}
+		}
+	}
+	return nil
+}
+
+// symbolIndex maps the symbol keys to the market ids, the ids are looked up
+// by lower case, the other symbols by symbolKey.
+type symbolIndex struct {
+	ids     map[string]string
+	symbols map[string]string
+}
+
+// newSymbolIndex indexes the markets by ids. The keys matching several
+// markets of one format are ambiguous, they don't resolve.
+func newSymbolIndex(byID map[string]market.Market, idsByName map[string]string, config SymbolConfig) symbolIndex {
+	index := symbolIndex{ids: map[string]string{}, symbols: map[string]string{}}
+	for alias, name := range config.Aliases {
+		if id, ok := idsByName[name]; ok {
+			index.symbols[symbolKey(alias)] = id
+		}
+	}
+	for _, format := range config.Formats {
+		keys := index.symbols
+		if format == SymbolFormatID {
+			keys = index.ids
+		}
+		found := map[string]string{}
+		for id, m := range byID {
+			for _, key := range symbolKeys(m, format) {
+				if other, ok := found[key]; ok && other != id {
+					found[key] = ""
+					continue
+				}
+				found[key] = id
+			}
+		}
+		for key, id := range found {
+			if _, ok := keys[key]; !ok {
+				keys[key] = id
+			}
+		}
+	}
+	return index
+}
+
+func (i symbolIndex) resolve(symbol string) (string, bool) {
+	if id := i.ids[strings.ToLower(strings.TrimSpace(symbol))]; id != "" {
+		return id, true
+	}
+	id := i.symbols[symbolKey(symbol)]
+	return id, id != ""
+}
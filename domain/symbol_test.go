@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketRegistry_Resolve(t *testing.T) {
+	r := NewMarketRegistry([]market.Market{
+		{
+			ID:             "352656ec-4ad4-4e8b-8dc4-2ddd3e7643b1",
+			Name:           "USDT_BTC",
+			BaseCurrency:   market.Currency{Symbol: "BTC"},
+			QuotedCurrency: market.Currency{Symbol: "USDT"},
+		},
+		// the currencies are taken from the name
+		{ID: "2", Name: "USDT_1INCH"},
+		{ID: "3", Name: "USDT_SHIB"},
+		// the pair of BTC_ETH is the name of ETH_BTC
+		{ID: "4", Name: "ETH_BTC"},
+		{ID: "5", Name: "BTC_ETH"},
+	})
+
+	for symbol, want := range map[string]string{
+		"USDT_BTC":                             "USDT_BTC",
+		"usdt/btc":                             "USDT_BTC",
+		"USDT%2FBTC":                           "USDT_BTC",
+		"BTC/USDT":                             "USDT_BTC",
+		"btc-usdt":                             "USDT_BTC",
+		"BTCUSDT":                              "USDT_BTC",
+		" btcusdt ":                            "USDT_BTC",
+		"352656EC-4AD4-4E8B-8DC4-2DDD3E7643B1": "USDT_BTC",
+		"1INCH_USDT":                           "USDT_1INCH",
+		"1inchusdt":                            "USDT_1INCH",
+		"SHIB/USDT":                            "USDT_SHIB",
+		"ETH_BTC":                              "ETH_BTC",
+		"BTC_ETH":                              "BTC_ETH",
+		"5":                                    "BTC_ETH",
+		"BTCETH":                               "ETH_BTC",
+		"ETHBTC":                               "BTC_ETH",
+	} {
+		name, ok := r.ResolveName(symbol)
+		if assert.True(t, ok, symbol) {
+			assert.Equal(t, want, name, symbol)
+		}
+	}
+	for _, symbol := range []string{"", "BTC", "USDT_XRP", "XRPUSDT", "6"} {
+		_, ok := r.ResolveName(symbol)
+		assert.False(t, ok, symbol)
+	}
+
+	config, err := ParseSymbolConfig([]string{"name", "concat"}, map[string]string{"XBT-USDT": "USDT_BTC"})
+	require.NoError(t, err)
+	r.SetSymbolConfig(config)
+	name, ok := r.ResolveName("xbt/usdt")
+	assert.True(t, ok)
+	assert.Equal(t, "USDT_BTC", name)
+	_, ok = r.ResolveName("BTC/USDT")
+	assert.False(t, ok)
+	_, ok = r.ResolveName("352656ec-4ad4-4e8b-8dc4-2ddd3e7643b1")
+	assert.False(t, ok)
+
+	// the symbols follow the renames
+	r.Update([]market.Market{{ID: "2", Name: "USDC_1INCH"}})
+	name, ok = r.ResolveName("1INCHUSDC")
+	assert.True(t, ok)
+	assert.Equal(t, "USDC_1INCH", name)
+	_, ok = r.ResolveName("1INCHUSDT")
+	assert.False(t, ok)
+
+	_, err = ParseSymbolConfig([]string{"ticker"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,329 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TickerWindow = 24 * time.Hour
+
+// MaxTickerWindow is the longest window size of a ticker.
+const MaxTickerWindow = 7 * 24 * time.Hour
+
+// ParseTickerWindow parses a ticker window size, 1m..59m, 1h..23h or 1d..7d.
+func ParseTickerWindow(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid window size %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid window size %q", s)
+	}
+	var unit time.Duration
+	var max int
+	switch s[len(s)-1] {
+	case 'm':
+		unit, max = time.Minute, 59
+	case 'h':
+		unit, max = time.Hour, 23
+	case 'd':
+		unit, max = 24*time.Hour, 7
+	default:
+		return 0, fmt.Errorf("invalid window size %q", s)
+	}
+	if n > max {
+		return 0, fmt.Errorf("invalid window size %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+type tickerBucket struct {
+	openTime    time.Time
+	open        primitive.Decimal128
+	high        primitive.Decimal128
+	low         primitive.Decimal128
+	close       primitive.Decimal128
+	lastQty     primitive.Decimal128
+	volume      primitive.Decimal128
+	quoteVolume primitive.Decimal128
+	count       int
+	firstId     string
+	lastId      string
+	firstTime   time.Time
+	lastTime    time.Time
+}
+
+func (b *tickerBucket) add(t time.Time, id string, price, qty primitive.Decimal128) error {
+	quote, err := decimal128.Mul(price, qty)
+	if err != nil {
+		return err
+	}
+	return b.merge(tickerBucket{
+		open:        price,
+		high:        price,
+		low:         price,
+		close:       price,
+		lastQty:     qty,
+		volume:      qty,
+		quoteVolume: quote,
+		count:       1,
+		firstId:     id,
+		lastId:      id,
+		firstTime:   t,
+		lastTime:    t,
+	})
+}
+
+// merge leaves the bucket unchanged on error.
+func (b *tickerBucket) merge(o tickerBucket) error {
+	if o.count == 0 {
+		return nil
+	}
+	if b.count == 0 {
+		openTime := b.openTime
+		*b = o
+		b.openTime = openTime
+		return nil
+	}
+	high, err := decimal128.Max(b.high, o.high)
+	if err != nil {
+		return err
+	}
+	low, err := decimal128.Min(b.low, o.low)
+	if err != nil {
+		return err
+	}
+	volume, err := decimal128.Add(b.volume, o.volume)
+	if err != nil {
+		return err
+	}
+	quoteVolume, err := decimal128.Add(b.quoteVolume, o.quoteVolume)
+	if err != nil {
+		return err
+	}
+	if o.firstTime.Before(b.firstTime) {
+		b.open, b.firstId, b.firstTime = o.open, o.firstId, o.firstTime
+	}
+	if !o.lastTime.Before(b.lastTime) {
+		b.close, b.lastQty, b.lastId, b.lastTime = o.close, o.lastQty, o.lastId, o.lastTime
+	}
+	b.high, b.low = high, low
+	b.volume, b.quoteVolume = volume, quoteVolume
+	b.count += o.count
+	return nil
+}
+
+// RollingTicker keeps the deals of a market for the last window in minute
+// buckets, so the window slides with a minute precision.
+type RollingTicker struct {
+	Symbol        string
+	window        time.Duration
+	precision     MarketPrecision
+	buckets       []tickerBucket
+	prevClose     primitive.Decimal128
+	prevCloseTime time.Time
+	hasPrevClose  bool
+}
+
+func NewRollingTicker(symbol string, window time.Duration, precision MarketPrecision) *RollingTicker {
+	return &RollingTicker{
+		Symbol:    symbol,
+		window:    window,
+		precision: precision,
+	}
+}
+
+func (r *RollingTicker) AddDeal(t time.Time, id string, price, qty primitive.Decimal128) error {
+	if err := r.bucket(t.Truncate(time.Minute)).add(t, id, price, qty); err != nil {
+		return fmt.Errorf("deal %s: %w", id, err)
+	}
+	return nil
+}
+
+// AddKline adds the deals of a closed kline as a single bucket, so a kline of
+// a longer period than a minute is evicted as a whole.
+func (r *RollingTicker) AddKline(k *model.Kline) error {
+	err := r.bucket(k.OpenTime).merge(tickerBucket{
+		open:        k.Open,
+		high:        k.High,
+		low:         k.Low,
+		close:       k.Close,
+		lastQty:     k.LastQty,
+		volume:      k.Volume,
+		quoteVolume: k.Quotes,
+		count:       k.Trades,
+		firstId:     k.FirstId,
+		lastId:      k.LastId,
+		firstTime:   k.First,
+		lastTime:    k.Last,
+	})
+	if err != nil {
+		return fmt.Errorf("kline %s %s: %w", k.Symbol, k.OpenTime, err)
+	}
+	return nil
+}
+
+func (r *RollingTicker) bucket(openTime time.Time) *tickerBucket {
+	i := sort.Search(len(r.buckets), func(i int) bool {
+		return !r.buckets[i].openTime.Before(openTime)
+	})
+	if i == len(r.buckets) || !r.buckets[i].openTime.Equal(openTime) {
+		r.buckets = append(r.buckets, tickerBucket{})
+		copy(r.buckets[i+1:], r.buckets[i:])
+		r.buckets[i] = tickerBucket{openTime: openTime}
+	}
+	return &r.buckets[i]
+}
+
+// TickerState is the state of a rolling ticker, its minute buckets are kept
+// as the klines.
+type TickerState struct {
+	Symbol        string               `bson:"symbol"`
+	Buckets       []*model.Kline       `bson:"buckets"`
+	PrevClose     primitive.Decimal128 `bson:"prevClose"`
+	PrevCloseTime time.Time            `bson:"prevCloseTime"`
+	HasPrevClose  bool                 `bson:"hasPrevClose"`
+}
+
+func (r *RollingTicker) State() TickerState {
+	state := TickerState{
+		Symbol:        r.Symbol,
+		Buckets:       make([]*model.Kline, 0, len(r.buckets)),
+		PrevClose:     r.prevClose,
+		PrevCloseTime: r.prevCloseTime,
+		HasPrevClose:  r.hasPrevClose,
+	}
+	for _, b := range r.buckets {
+		state.Buckets = append(state.Buckets, &model.Kline{
+			OpenTime:  b.openTime,
+			CloseTime: b.openTime.Add(time.Minute),
+			Open:      b.open,
+			High:      b.high,
+			Low:       b.low,
+			Close:     b.close,
+			LastQty:   b.lastQty,
+			Volume:    b.volume,
+			Quotes:    b.quoteVolume,
+			Trades:    b.count,
+			FirstId:   b.firstId,
+			LastId:    b.lastId,
+			First:     b.firstTime,
+			Last:      b.lastTime,
+			Symbol:    r.Symbol,
+		})
+	}
+	return state
+}
+
+// Restore adds the buckets and the previous close of the state.
+func (r *RollingTicker) Restore(state TickerState) error {
+	for _, k := range state.Buckets {
+		if err := r.AddKline(k); err != nil {
+			return err
+		}
+	}
+	if state.HasPrevClose {
+		r.SetPrevClose(state.PrevCloseTime, state.PrevClose)
+	}
+	return nil
+}
+
+// SetPrevClose sets the price of the last deal before the window if it's
+// newer than the known one.
+func (r *RollingTicker) SetPrevClose(t time.Time, price primitive.Decimal128) {
+	if r.hasPrevClose && t.Before(r.prevCloseTime) {
+		return
+	}
+	r.prevClose, r.prevCloseTime, r.hasPrevClose = price, t, true
+}
+
+// Evict removes the buckets which are out of the window and reports if there
+// were any.
+func (r *RollingTicker) Evict(now time.Time) bool {
+	from := now.Add(-r.window)
+	i := 0
+	for ; i < len(r.buckets); i++ {
+		if r.buckets[i].openTime.Add(time.Minute).After(from) {
+			break
+		}
+		r.SetPrevClose(r.buckets[i].lastTime, r.buckets[i].close)
+	}
+	if i == 0 {
+		return false
+	}
+	r.buckets = append(r.buckets[:0], r.buckets[i:]...)
+	return true
+}
+
+// Statistics returns false if there were no deals ever.
+func (r *RollingTicker) Statistics(now time.Time) (*TickerPriceChangeStatistics, bool) {
+	r.Evict(now)
+	zero := decimal128.StringFixed(decimal128.Zero, 8)
+	if len(r.buckets) == 0 {
+		if !r.hasPrevClose {
+			return nil, false
+		}
+		price := decimal128.String(r.prevClose)
+		return &TickerPriceChangeStatistics{
+			Symbol:             r.Symbol,
+			PriceChange:        zero,
+			PriceChangePercent: zero,
+			WeightedAvgPrice:   r.precision.FormatPrice(r.prevClose),
+			PrevClosePrice:     price,
+			LastPrice:          price,
+			LastQty:            decimal128.String(decimal128.Zero),
+			OpenPrice:          price,
+			HighPrice:          price,
+			LowPrice:           price,
+			Volume:             decimal128.String(decimal128.Zero),
+			QuoteVolume:        r.precision.FormatQuote(decimal128.Zero),
+			OpenTime:           r.prevCloseTime.UnixMilli(),
+			CloseTime:          r.prevCloseTime.UnixMilli(),
+		}, true
+	}
+
+	// the buckets are merged into one
+	total := tickerBucket{}
+	for _, b := range r.buckets {
+		if err := total.merge(b); err != nil {
+			return nil, false
+		}
+	}
+	change, err := decimal128.Sub(total.close, total.open)
+	if err != nil {
+		return nil, false
+	}
+	stat := &TickerPriceChangeStatistics{
+		Symbol:             r.Symbol,
+		PriceChange:        decimal128.StringFixed(change, 8),
+		PriceChangePercent: zero,
+		LastPrice:          decimal128.String(total.close),
+		LastQty:            decimal128.String(total.lastQty),
+		OpenPrice:          decimal128.String(total.open),
+		HighPrice:          decimal128.String(total.high),
+		LowPrice:           decimal128.String(total.low),
+		Volume:             decimal128.String(total.volume),
+		QuoteVolume:        r.precision.FormatQuote(total.quoteVolume),
+		OpenTime:           total.firstTime.UnixMilli(),
+		CloseTime:          total.lastTime.UnixMilli(),
+		FirstId:            total.firstId,
+		LastId:             total.lastId,
+		Count:              total.count,
+	}
+	if percent, err := decimal128.Div(change, total.open, 8, decimal128.RoundHalfUp); err == nil {
+		stat.PriceChangePercent = decimal128.StringFixed(percent, 8)
+	}
+	if vwap, err := decimal128.Div(total.quoteVolume, total.volume, r.precision.Price, decimal128.RoundHalfUp); err == nil {
+		stat.WeightedAvgPrice = r.precision.FormatPrice(vwap)
+	}
+	if r.hasPrevClose {
+		stat.PrevClosePrice = decimal128.String(r.prevClose)
+	}
+	return stat, true
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingTicker(t *testing.T) {
+	start := time.Date(2020, 4, 14, 15, 45, 0, 0, time.UTC)
+	r := NewRollingTicker("ETH_BTC", time.Hour, DefaultMarketPrecision)
+	_, ok := r.Statistics(start)
+	assert.False(t, ok)
+
+	require.NoError(t, r.AddDeal(start.Add(10*time.Second), "1", model.MustParseDecimal("10"), model.MustParseDecimal("1")))
+	require.NoError(t, r.AddDeal(start.Add(30*time.Minute), "3", model.MustParseDecimal("12.5"), model.MustParseDecimal("2")))
+	// out of order deal
+	require.NoError(t, r.AddDeal(start.Add(20*time.Second), "2", model.MustParseDecimal("8"), model.MustParseDecimal("0.5")))
+
+	stat, ok := r.Statistics(start.Add(40 * time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, &TickerPriceChangeStatistics{
+		Symbol:             "ETH_BTC",
+		PriceChange:        "2.50000000",
+		PriceChangePercent: "0.25000000",
+		WeightedAvgPrice:   "11.1429",
+		LastPrice:          "12.5",
+		LastQty:            "2",
+		OpenPrice:          "10",
+		HighPrice:          "12.5",
+		LowPrice:           "8",
+		Volume:             "3.5",
+		QuoteVolume:        "39.00000000",
+		OpenTime:           start.Add(10 * time.Second).UnixMilli(),
+		CloseTime:          start.Add(30 * time.Minute).UnixMilli(),
+		FirstId:            "1",
+		LastId:             "3",
+		Count:              3,
+	}, stat)
+
+	t.Run("first minute evicted", func(t *testing.T) {
+		assert.False(t, r.Evict(start.Add(time.Hour)))
+		assert.True(t, r.Evict(start.Add(time.Hour+time.Minute)))
+		stat, ok := r.Statistics(start.Add(time.Hour + time.Minute))
+		require.True(t, ok)
+		assert.Equal(t, "8", stat.PrevClosePrice)
+		assert.Equal(t, "12.5", stat.OpenPrice)
+		assert.Equal(t, "2", stat.Volume)
+		assert.Equal(t, 1, stat.Count)
+	})
+	t.Run("all evicted", func(t *testing.T) {
+		stat, ok := r.Statistics(start.Add(2 * time.Hour))
+		require.True(t, ok)
+		assert.Equal(t, "12.5", stat.LastPrice)
+		assert.Equal(t, "12.5", stat.PrevClosePrice)
+		assert.Equal(t, "0", stat.Volume)
+		assert.Equal(t, 0, stat.Count)
+	})
+}
+
+func TestRollingTicker_AddKline(t *testing.T) {
+	start := time.Date(2020, 4, 14, 15, 0, 0, 0, time.UTC)
+	r := NewRollingTicker("ETH_BTC", 2*time.Hour, MarketPrecision{Price: 2, Quote: 4})
+	r.SetPrevClose(start.Add(-time.Second), model.MustParseDecimal("9"))
+	require.NoError(t, r.AddKline(&model.Kline{
+		Symbol:   "ETH_BTC",
+		OpenTime: start,
+		Open:     model.MustParseDecimal("10"),
+		High:     model.MustParseDecimal("12"),
+		Low:      model.MustParseDecimal("8"),
+		Close:    model.MustParseDecimal("11"),
+		LastQty:  model.MustParseDecimal("1"),
+		Volume:   model.MustParseDecimal("3"),
+		Quotes:   model.MustParseDecimal("30"),
+		Trades:   2,
+		First:    start.Add(time.Minute),
+		Last:     start.Add(50 * time.Minute),
+		FirstId:  "1",
+		LastId:   "2",
+	}))
+	// the deals after the last materialized minute
+	require.NoError(t, r.AddDeal(start.Add(time.Hour+time.Minute), "3", model.MustParseDecimal("13"), model.MustParseDecimal("1")))
+
+	stat, ok := r.Statistics(start.Add(2 * time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, &TickerPriceChangeStatistics{
+		Symbol:             "ETH_BTC",
+		PriceChange:        "3.00000000",
+		PriceChangePercent: "0.30000000",
+		WeightedAvgPrice:   "10.75",
+		PrevClosePrice:     "9",
+		LastPrice:          "13",
+		LastQty:            "1",
+		OpenPrice:          "10",
+		HighPrice:          "13",
+		LowPrice:           "8",
+		Volume:             "4",
+		QuoteVolume:        "43.0000",
+		OpenTime:           start.Add(time.Minute).UnixMilli(),
+		CloseTime:          start.Add(time.Hour + time.Minute).UnixMilli(),
+		FirstId:            "1",
+		LastId:             "3",
+		Count:              3,
+	}, stat)
+}
+
+func TestRollingTicker_Restore(t *testing.T) {
+	start := time.Date(2020, 4, 14, 15, 0, 0, 0, time.UTC)
+	r := NewRollingTicker("ETH_BTC", time.Hour, DefaultMarketPrecision)
+	r.SetPrevClose(start.Add(-time.Second), model.MustParseDecimal("9"))
+	require.NoError(t, r.AddDeal(start.Add(10*time.Second), "1", model.MustParseDecimal("10"), model.MustParseDecimal("1")))
+	require.NoError(t, r.AddDeal(start.Add(20*time.Second), "2", model.MustParseDecimal("8"), model.MustParseDecimal("0.5")))
+	require.NoError(t, r.AddDeal(start.Add(30*time.Minute), "3", model.MustParseDecimal("12.5"), model.MustParseDecimal("2")))
+
+	restored := NewRollingTicker("ETH_BTC", time.Hour, DefaultMarketPrecision)
+	require.NoError(t, restored.Restore(r.State()))
+	now := start.Add(40 * time.Minute)
+	expected, _ := r.Statistics(now)
+	stat, ok := restored.Statistics(now)
+	require.True(t, ok)
+	assert.Equal(t, expected, stat)
+
+	assert.True(t, restored.Evict(start.Add(time.Hour+time.Minute)), "the restored buckets are evicted by minutes")
+	stat, _ = restored.Statistics(start.Add(time.Hour + time.Minute))
+	assert.Equal(t, "8", stat.PrevClosePrice)
+}
+
+func TestParseTickerWindow(t *testing.T) {
+	for s, d := range map[string]time.Duration{
+		"1m":  time.Minute,
+		"59m": 59 * time.Minute,
+		"1h":  time.Hour,
+		"23h": 23 * time.Hour,
+		"1d":  24 * time.Hour,
+		"7d":  MaxTickerWindow,
+	} {
+		got, err := ParseTickerWindow(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, d, got, s)
+	}
+	for _, s := range []string{"", "m", "0m", "60m", "24h", "8d", "1w", "-1h", "1.5h"} {
+		_, err := ParseTickerWindow(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -2,10 +2,11 @@ package domain
 
 import (
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"context"
 	"fmt"
-	"strconv"
 	"time"
 
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -19,6 +20,11 @@ type Candle struct {
 	Volume     primitive.Decimal128 `json:"v"`
 	OpenTime   time.Time            `json:"t"`
 	CloseTime  time.Time
+	// Closed is set on the last update of a candle whose period is over.
+	Closed bool
+	// Ctx carries the trace of the deal which updated the candle, it's nil
+	// if the deal isn't traced.
+	Ctx context.Context `json:"-" bson:"-"`
 }
 
 func (c Candle) ContainsTs(nano int64) bool {
@@ -34,17 +40,56 @@ type Chart struct {
 	C          []primitive.Decimal128 `json:"c"`
 	V          []primitive.Decimal128 `json:"v"`
 	T          []int64                `json:"t"`
+	// Final is set if the last candle of a live chart is closed, it won't be
+	// updated anymore. The earlier candles of a chart are always closed.
+	Final bool `json:"final,omitempty" bson:"-"`
+	// Ctx carries the trace of the update of a live chart.
+	Ctx context.Context `json:"-" bson:"-"`
 }
 
 type ChartResponse struct {
 	Symbol     string `json:"symbol"`
 	resolution model.Resolution
-	O          []float64 `json:"o"`
-	H          []float64 `json:"h"`
-	L          []float64 `json:"l"`
-	C          []float64 `json:"c"`
-	V          []float64 `json:"v"`
-	T          []int64   `json:"t"`
+	O          ChartDecimals `json:"o"`
+	H          ChartDecimals `json:"h"`
+	L          ChartDecimals `json:"l"`
+	C          ChartDecimals `json:"c"`
+	V          ChartDecimals `json:"v"`
+	T          []int64       `json:"t"`
+}
+
+// ChartDecimals are exact chart values encoded as JSON numbers, or as JSON
+// strings if Quoted.
+type ChartDecimals struct {
+	Values []primitive.Decimal128
+	Quoted bool
+}
+
+func (d ChartDecimals) MarshalJSON() ([]byte, error) {
+	if d.Values == nil {
+		return []byte("null"), nil
+	}
+	b := make([]byte, 0, 16*len(d.Values)+2)
+	b = append(b, '[')
+	for i, v := range d.Values {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if d.Quoted {
+			b = append(b, '"')
+		}
+		b = append(b, decimal128.String(v)...)
+		if d.Quoted {
+			b = append(b, '"')
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// ChartOptions sets the encoding of the chart values.
+type ChartOptions struct {
+	// StringDecimals encodes the values as JSON strings.
+	StringDecimals bool
 }
 
 func (c *Chart) SetResolution(resolution model.Resolution) {
@@ -55,39 +100,26 @@ func (c *Chart) SetMarket(market string) {
 	c.Symbol = market
 }
 
-func MakeChartResponse(market string, chart *Chart) ChartResponse {
+func MakeChartResponse(market string, chart *Chart, opts ChartOptions) ChartResponse {
 	if nil == chart {
 		return ChartResponse{
 			Symbol: market,
 		}
 	}
 
-	r := ChartResponse{
-		Symbol: chart.Symbol,
-		H:      make([]float64, len(chart.H)),
-		L:      make([]float64, len(chart.L)),
-		O:      make([]float64, len(chart.O)),
-		C:      make([]float64, len(chart.C)),
-		V:      make([]float64, len(chart.V)),
-		T:      chart.T,
+	values := func(ds []primitive.Decimal128) ChartDecimals {
+		return ChartDecimals{Values: ds, Quoted: opts.StringDecimals}
 	}
 
-	for i := 0; i < len(chart.V); i++ {
-		oString := chart.O[i].String()
-		oFloat, _ := strconv.ParseFloat(oString, 64)
-		hFloat, _ := strconv.ParseFloat(chart.H[i].String(), 64)
-		lFloat, _ := strconv.ParseFloat(chart.L[i].String(), 64)
-		cFloat, _ := strconv.ParseFloat(chart.C[i].String(), 64)
-		vFloat, _ := strconv.ParseFloat(chart.V[i].String(), 64)
-
-		r.O[i] = oFloat
-		r.H[i] = hFloat
-		r.L[i] = lFloat
-		r.C[i] = cFloat
-		r.V[i] = vFloat
+	return ChartResponse{
+		Symbol: chart.Symbol,
+		O:      values(chart.O),
+		H:      values(chart.H),
+		L:      values(chart.L),
+		C:      values(chart.C),
+		V:      values(chart.V),
+		T:      chart.T,
 	}
-
-	return r
 }
 
 func ChartToCurrentCandle(chart *Chart, resolution model.Resolution) (Candle, error) {
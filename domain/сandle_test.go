@@ -2,6 +2,7 @@ package domain
 
 import (
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -146,3 +147,29 @@ func TestCandle_ContainsTs(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeChartResponse(t *testing.T) {
+	chart := &Chart{
+		Symbol: "SHIB_USDT",
+		O:      []primitive.Decimal128{mustParseDecimal128(t, "0.000012345678901234")},
+		H:      []primitive.Decimal128{mustParseDecimal128(t, "0.000012345678901235")},
+		L:      []primitive.Decimal128{mustParseDecimal128(t, "1.2E-5")},
+		C:      []primitive.Decimal128{mustParseDecimal128(t, "0.00001234")},
+		V:      []primitive.Decimal128{mustParseDecimal128(t, "123456789012345678.9")},
+		T:      []int64{1},
+	}
+	b, err := json.Marshal(MakeChartResponse("SHIB_USDT", chart, ChartOptions{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"symbol":"SHIB_USDT","o":[0.000012345678901234],"h":[0.000012345678901235],`+
+		`"l":[0.000012],"c":[0.00001234],"v":[123456789012345678.9],"t":[1]}`, string(b))
+	assert.Contains(t, string(b), `123456789012345678.9`)
+
+	b, err = json.Marshal(MakeChartResponse("SHIB_USDT", chart, ChartOptions{StringDecimals: true}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"symbol":"SHIB_USDT","o":["0.000012345678901234"],"h":["0.000012345678901235"],`+
+		`"l":["0.000012"],"c":["0.00001234"],"v":["123456789012345678.9"],"t":[1]}`, string(b))
+
+	b, err = json.Marshal(MakeChartResponse("SHIB_USDT", nil, ChartOptions{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"symbol":"SHIB_USDT","o":null,"h":null,"l":null,"c":null,"v":null,"t":null}`, string(b))
+}
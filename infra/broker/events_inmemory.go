@@ -1,6 +1,9 @@
 package broker
 
 import (
+	"context"
+	"sync"
+
 	"bitbucket.org/novatechnologies/common/infra/logger"
 
 	"bitbucket.org/novatechnologies/ohlcv/domain"
@@ -15,6 +18,7 @@ var _ domain.EventsBroker = new(EventsInMemory)
 type EventsInMemory struct {
 	log         logger.Logger
 	subscribers map[domain.EventType][]domain.EventHandler
+	running     sync.WaitGroup
 }
 
 func NewInMemory() *EventsInMemory {
@@ -44,7 +48,9 @@ func (ps *EventsInMemory) Publish(tp domain.EventType, ev *domain.Event) {
 	for _, handler := range ps.subscribers[tp] {
 		currHandler := handler
 
+		ps.running.Add(1)
 		go func() {
+			defer ps.running.Done()
 			defer func() {
 				if r := recover(); r != nil {
 					ps.log.Errorf(
@@ -63,3 +69,18 @@ func (ps *EventsInMemory) Publish(tp domain.EventType, ev *domain.Event) {
 		}()
 	}
 }
+
+// Wait waits for the running handlers, e.g. the last broadcasts on shutdown.
+func (ps *EventsInMemory) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		ps.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,27 +3,60 @@ package centrifuge
 import (
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type broadcaster struct {
 	Centrifuge   Centrifuge
 	Channels     map[string]map[model.Resolution]*domain.ChartChannel
 	eventsBroker domain.EventsBroker
+	mu           sync.RWMutex
 }
 
-func NewBroadcaster(publisher Centrifuge, eventsBroker domain.EventsBroker, marketsMap map[string]string) *broadcaster {
-	return &broadcaster{
+// NewBroadcaster creates the chart channels of the markets and keeps them in
+// sync with the registry.
+func NewBroadcaster(publisher Centrifuge, eventsBroker domain.EventsBroker, markets *domain.MarketRegistry) *broadcaster {
+	var names []string
+	if markets != nil {
+		names = markets.Names()
+	}
+	b := &broadcaster{
 		Centrifuge:   publisher,
-		Channels:     GetChartsChannels(marketsMap),
+		Channels:     GetChartsChannels(names),
 		eventsBroker: eventsBroker,
 	}
+	if markets != nil {
+		markets.Subscribe(b.HandleMarketEvent)
+	}
+	return b
 }
 
-func (b broadcaster) SubscribeForCharts() {
+// HandleMarketEvent creates the chart channels of an added market and drops
+// the ones of a removed market, a renamed market gets the new channels.
+func (b *broadcaster) HandleMarketEvent(e domain.MarketEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch e.Type {
+	case domain.MarketAdded:
+		b.Channels[e.Market.Name] = newMarketChannels(e.Market.Name)
+	case domain.MarketRemoved:
+		delete(b.Channels, e.Market.Name)
+	case domain.MarketRenamed:
+		delete(b.Channels, e.OldName)
+		b.Channels[e.Market.Name] = newMarketChannels(e.Market.Name)
+	}
+}
+
+func (b *broadcaster) SubscribeForCharts() {
 	b.eventsBroker.Subscribe(
 		domain.EvTypeCharts, func(e *domain.Event) error {
 			b.BroadcastCandleCharts(e.Ctx, e.MustGetCharts())
@@ -32,14 +65,70 @@ func (b broadcaster) SubscribeForCharts() {
 	)
 }
 
-func (b broadcaster) BroadcastCandleCharts(
+func (b *broadcaster) SubscribeForTickers() {
+	b.eventsBroker.Subscribe(
+		domain.EvTypeTickers, func(e *domain.Event) error {
+			b.BroadcastTickers(e.Ctx, e.MustGetTickers())
+			return nil
+		},
+	)
+}
+
+// BroadcastTickers publishes every ticker into its market channel and all of
+// them at once into the TickerAllChannel.
+func (b *broadcaster) BroadcastTickers(
+	ctx context.Context,
+	tickers []*domain.TickerPriceChangeStatistics,
+) {
+	messages := make([]MessageData, 0, len(tickers)+1)
+
+	for _, ticker := range tickers {
+		payload, _ := json.Marshal(ticker)
+		messages = append(
+			messages, MessageData{
+				Channel: TickerChannelName(ticker.Symbol),
+				Data:    string(payload),
+			},
+		)
+	}
+	payload, _ := json.Marshal(tickers)
+	messages = append(
+		messages, MessageData{
+			Channel: domain.TickerAllChannel,
+			Data:    string(payload),
+		},
+	)
+
+	logger.FromContext(ctx).WithField(
+		"messageCount",
+		len(messages),
+	).Tracef("[Broadcaster.BroadcastTickers] Push tickers to Centrifugo.")
+	b.Centrifuge.BatchPublish(ctx, messages)
+}
+
+func (b *broadcaster) BroadcastCandleCharts(
 	ctx context.Context,
 	cht []*domain.Chart,
 ) {
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"Broadcaster.BroadcastCandleCharts",
+		trace.WithAttributes(attribute.Int("charts", len(cht))),
+	)
+	defer span.End()
 	messages := make([]MessageData, 0)
 
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	for _, chart := range cht {
-		channel := b.Channels[chart.Symbol][chart.Resolution]
+		channel, ok := b.Channels[chart.Symbol][chart.Resolution]
+		if !ok {
+			logger.FromContext(ctx).
+				WithField("market", chart.Symbol).
+				WithField("resolution", chart.Resolution).
+				Errorf("no chart channel of the market")
+			continue
+		}
 		payload, _ := json.Marshal(chart)
 		messages = append(
 			messages, MessageData{
@@ -59,22 +148,26 @@ func (b broadcaster) BroadcastCandleCharts(
 	b.Centrifuge.BatchPublish(ctx, messages)
 }
 
-func GetChartsChannels(marketsMap map[string]string) map[string]map[model.Resolution]*domain.ChartChannel {
-	c := make(map[string]map[model.Resolution]*domain.ChartChannel, len(marketsMap))
-	for _, market := range marketsMap {
-		resolutions := model.GetAvailableResolutions()
-		marketChannels := make(
-			map[model.Resolution]*domain.ChartChannel,
-			len(resolutions),
-		)
-		for _, resolution := range resolutions {
-			marketChannels[resolution] = NewChartChannel(market, resolution)
-		}
-		c[market] = marketChannels
+func GetChartsChannels(markets []string) map[string]map[model.Resolution]*domain.ChartChannel {
+	c := make(map[string]map[model.Resolution]*domain.ChartChannel, len(markets))
+	for _, market := range markets {
+		c[market] = newMarketChannels(market)
 	}
 	return c
 }
 
+func newMarketChannels(market string) map[model.Resolution]*domain.ChartChannel {
+	resolutions := model.GetAvailableResolutions()
+	marketChannels := make(
+		map[model.Resolution]*domain.ChartChannel,
+		len(resolutions),
+	)
+	for _, resolution := range resolutions {
+		marketChannels[resolution] = NewChartChannel(market, resolution)
+	}
+	return marketChannels
+}
+
 func NewChartChannel(market string, resolution model.Resolution) *domain.ChartChannel {
 	name := fmt.Sprintf(
 		"%s_%s_%s",
@@ -88,3 +181,7 @@ func NewChartChannel(market string, resolution model.Resolution) *domain.ChartCh
 		Resolution: resolution,
 	}
 }
+
+func TickerChannelName(market string) string {
+	return fmt.Sprintf("%s_%s", domain.TickerChannelPrefix, market)
+}
@@ -3,6 +3,8 @@ package centrifuge
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/centrifugal/gocent/v3"
 
 	"bitbucket.org/novatechnologies/common/infra/logger"
@@ -10,8 +12,13 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"bitbucket.org/novatechnologies/ohlcv/infra"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
 )
 
 // NewClient returns centrifugo server-side WS client.
@@ -85,21 +92,35 @@ func (c centrifuge) BatchPublish(ctx context.Context, messages []MessageData) {
 	if len(messages) == 0 {
 		return
 	}
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"centrifugo.BatchPublish",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("messages", len(messages))),
+	)
+	defer span.End()
 	log := logger.FromContext(ctx)
 	pipe := c.Client.Pipe()
 	for _, message := range messages {
 		e := pipe.AddPublish(message.Channel, []byte(message.Data))
 		if e != nil {
+			metrics.CentrifugoPublishErrors.Inc()
 			log.Errorf("Error calling BatchPublish func: %v", e)
 		}
 	}
+	start := time.Now()
 	replies, err := c.Client.SendPipe(ctx, pipe)
+	metrics.ObserveSince(metrics.CentrifugoPublishDuration, start)
 	if err != nil {
+		metrics.CentrifugoPublishErrors.Add(float64(len(messages)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Errorf("Error sending pipe: %v", err.Error())
 	}
 	for _, reply := range replies {
 		if reply.Error != nil {
-			log.Errorf("Error in pipe reply: %v", err)
+			metrics.CentrifugoPublishErrors.Inc()
+			log.Errorf("Error in pipe reply: %v", reply.Error)
 		}
 	}
 	log.Tracef("Sent %d publish commands in one HTTP request ", len(replies))
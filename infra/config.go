@@ -2,6 +2,7 @@ package infra
 
 import (
 	"context"
+	"time"
 
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"github.com/joho/godotenv"
@@ -19,6 +20,12 @@ type HttpConfig struct {
 type KafkaConfig struct {
 	Host          string `envconfig:"KAFKA_HOST" required:"true"`
 	ConsumerCount int    `envconfig:"KAFKA_CONSUMER_COUNT" required:"true"`
+	// ConsumerGroup is the consumer group of the ingesting instances, every
+	// API replica reads all the deals in the group named
+	// ConsumerGroup-api-INSTANCE_NAME. The sharded ingesting instances save
+	// the deals in ConsumerGroup and read all of them for the live state in
+	// ConsumerGroup-live-INSTANCE_NAME.
+	ConsumerGroup string `envconfig:"KAFKA_CONSUMER_GROUP" default:"OhlcvConsumer"`
 	TopicPrefix   string `envconfig:"KAFKA_TOPIC_PREFIX" required:"true" default:"master"`
 	SslFlag       bool   `envconfig:"KAFKA_SSL" required:"true" default:"false"`
 }
@@ -29,6 +36,93 @@ type MongoDbConfig struct {
 	TimeOut                    int    `envconfig:"MONGODB_TIMEOUT" required:"true"`
 	MinuteCandleCollectionName string `envconfig:"MONGODB_MINUTE_CANDLE_COLLECTION_NAME" required:"true" default:"minutes"`
 	DealCollectionName         string `envconfig:"MONGODB_DEAL_COLLECTION_NAME" required:"true"`
+	// Klines materialized from deals for the window tickers.
+	MinuteKlineCollectionName string `envconfig:"MONGODB_MINUTE_KLINE_COLLECTION_NAME" default:"klines_minutes"`
+	HourKlineCollectionName   string `envconfig:"MONGODB_HOUR_KLINE_COLLECTION_NAME" default:"klines_hours"`
+	KlineStateCollectionName  string `envconfig:"MONGODB_KLINE_STATE_COLLECTION_NAME" default:"klines_state"`
+	// LeaseCollectionName keeps the leases of the instances.
+	LeaseCollectionName string `envconfig:"MONGODB_LEASE_COLLECTION_NAME" default:"leases"`
+	// LiveStateCollectionName keeps the snapshots of the live state of the
+	// markets.
+	LiveStateCollectionName string `envconfig:"MONGODB_LIVE_STATE_COLLECTION_NAME" default:"live_state"`
+	// MarketSnapshotCollectionName keeps the last good market list.
+	MarketSnapshotCollectionName string `envconfig:"MONGODB_MARKET_SNAPSHOT_COLLECTION_NAME" default:"markets_snapshot"`
+}
+
+// DealSubscriberConfig is a default policy for slow gRPC deal subscribers.
+type DealSubscriberConfig struct {
+	// Backpressure is one of drop-newest, drop-oldest, disconnect, block. The
+	// clients choose any of them but block.
+	Backpressure string        `envconfig:"DEAL_SUBSCRIBER_BACKPRESSURE" default:"drop-newest"`
+	BufferSize   int           `envconfig:"DEAL_SUBSCRIBER_BUFFER_SIZE" default:"1024"`
+	MaxDrops     uint64        `envconfig:"DEAL_SUBSCRIBER_MAX_DROPS" default:"1000"`
+	BlockTimeout time.Duration `envconfig:"DEAL_SUBSCRIBER_BLOCK_TIMEOUT" default:"100ms"`
+}
+
+// TracingConfig configures the OpenTelemetry tracing, the spans are exported
+// to an OTLP/gRPC collector if the endpoint is set.
+type TracingConfig struct {
+	// Endpoint is the collector address, e.g. localhost:4317.
+	Endpoint    string  `envconfig:"TRACING_OTLP_ENDPOINT"`
+	Insecure    bool    `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+	ServiceName string  `envconfig:"TRACING_SERVICE_NAME" default:"ohlcv"`
+	SampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// HealthConfig configures the readiness checks, the zero ages disable the
+// checks of the deals and the market list. They're off by default: a quiet
+// deal stream or an outage of the markets service would make all the
+// replicas unready at once, while they still serve the cached state.
+type HealthConfig struct {
+	// MaxDealAge is the max time since the last consumed deal.
+	MaxDealAge time.Duration `envconfig:"HEALTH_MAX_DEAL_AGE" default:"0"`
+	// MaxMarketsAge is the max age of the last good market list.
+	MaxMarketsAge time.Duration `envconfig:"HEALTH_MAX_MARKETS_AGE" default:"0"`
+	CheckTimeout  time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+}
+
+// ShardingConfig shares the live state of the markets, the current candles
+// and the tickers with their broadcasts, between the instances of the ingest
+// role, the all role can't be sharded. Each market is owned by one of them. The ownership isn't tied
+// to the Kafka partitions: each instance reads all the deals in a consumer
+// group of its own and keeps the owned ones, the deals are saved in the
+// shared group.
+type ShardingConfig struct {
+	Enabled bool `envconfig:"SHARDING_ENABLED" default:"false"`
+	// LeaseTTL is the time the markets of a failed instance are left
+	// without an owner.
+	LeaseTTL time.Duration `envconfig:"SHARDING_LEASE_TTL" default:"15s"`
+}
+
+// LeaderConfig elects the instances running the singleton jobs: the
+// materialization of the klines and the refresh of the market list. Every
+// instance runs them if the election is disabled.
+type LeaderConfig struct {
+	Enabled bool `envconfig:"LEADER_ELECTION_ENABLED" default:"false"`
+	// LeaseTTL is the time the jobs of a failed leader are left without a
+	// leader.
+	LeaseTTL time.Duration `envconfig:"LEADER_LEASE_TTL" default:"15s"`
+}
+
+// LiveStateConfig snapshots the current candles and the tickers, so they're
+// restored on start by replaying the deals made since the snapshot. The zero
+// interval disables the snapshots.
+type LiveStateConfig struct {
+	Interval time.Duration `envconfig:"LIVE_STATE_SNAPSHOT_INTERVAL" default:"1m"`
+	// MaxAge is the max age of a restored state, the older state is rebuilt
+	// from the storage.
+	MaxAge time.Duration `envconfig:"LIVE_STATE_MAX_AGE" default:"15m"`
+	// Path is a file of the snapshot, it's kept in MongoDb if it's empty.
+	Path string `envconfig:"LIVE_STATE_SNAPSHOT_PATH"`
+}
+
+// ShutdownConfig limits the graceful shutdown, it should be shorter than the
+// termination grace period of the pod.
+type ShutdownConfig struct {
+	Timeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// GRPCGracePeriod is the wait for the gRPC calls, the streams are closed
+	// after it.
+	GRPCGracePeriod time.Duration `envconfig:"SHUTDOWN_GRPC_GRACE_PERIOD" default:"5s"`
 }
 
 // CryptoKeyInPEM is string alias just explicitly informing of PEM format:
@@ -52,14 +146,36 @@ type CentrifugeConfig struct {
 }
 
 type Config struct {
-	KafkaConfig              KafkaConfig
-	GRPCConfig               GRPCConfig
-	MongoDbConfig            MongoDbConfig
-	CentrifugeConfig         CentrifugeConfig
-	HttpConfig               HttpConfig
+	KafkaConfig          KafkaConfig
+	GRPCConfig           GRPCConfig
+	MongoDbConfig        MongoDbConfig
+	CentrifugeConfig     CentrifugeConfig
+	HttpConfig           HttpConfig
+	DealSubscriberConfig DealSubscriberConfig
+	TracingConfig        TracingConfig
+	HealthConfig         HealthConfig
+	ShutdownConfig       ShutdownConfig
+	ShardingConfig       ShardingConfig
+	LeaderConfig         LeaderConfig
+	LiveStateConfig      LiveStateConfig
+	// Instance is the unique name of the instance in the leases, the host
+	// name by default. It's required with the sharding: it names the live
+	// consumer group, which has to survive the restarts, so it's stable like
+	// the StatefulSet pod name.
+	Instance                 string `envconfig:"INSTANCE_NAME"`
 	ExchangeMarketsServerURL string `envconfig:"EXCHANGE_MARKETS_SERVER_URL"`
 	ExchangeMarketsServerSSL bool   `envconfig:"EXCHANGE_MARKETS_SERVER_SSL" default:"true"`
 	ExchangeMarketsToken     string `envconfig:"EXCHANGE_MARKETS_TOKEN"`
+	// ExchangeMarketsSnapshotPath is a file of the last good market list, the
+	// list is kept in MongoDb if it's empty. It must be empty if the leader
+	// election is enabled.
+	ExchangeMarketsSnapshotPath string `envconfig:"EXCHANGE_MARKETS_SNAPSHOT_PATH"`
+	// MarketSymbolFormats are the accepted symbol formats in the order of
+	// precedence: id, name, pair, concat.
+	MarketSymbolFormats []string `envconfig:"MARKET_SYMBOL_FORMATS" default:"id,name,pair,concat"`
+	// MarketSymbolAliases maps the extra symbols to the market names, e.g.
+	// XBTUSDT:USDT_BTC,BTC-USD:USDT_BTC.
+	MarketSymbolAliases map[string]string `envconfig:"MARKET_SYMBOL_ALIASES"`
 }
 
 func SetConfig(configPath string) Config {
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// LivenessHandler answers while the process is able to serve the requests.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusUp})
+	})
+}
+
+// ReadinessHandler answers 503 Service Unavailable with the failed checks if
+// any of them is down.
+func ReadinessHandler(c *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		status := http.StatusOK
+		if !report.Up() {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// RunGRPC sets the serving status of the gRPC health service, the overall one
+// and the one of the services, from the checks every interval until the
+// context is done.
+func RunGRPC(ctx context.Context, c *Checker, srv *health.Server, interval time.Duration, services ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		report := c.Check(ctx)
+		if !report.Up() {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		if status != last {
+			if !report.Up() {
+				logger.FromContext(ctx).WithField("checks", report.Checks).Errorf("the service isn't ready")
+			}
+			srv.SetServingStatus("", status)
+			for _, service := range services {
+				srv.SetServingStatus(service, status)
+			}
+			last = status
+		}
+		select {
+		case <-ctx.Done():
+			srv.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
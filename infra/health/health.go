@@ -0,0 +1,152 @@
+// Package health reports the liveness and the readiness of the service, the
+// readiness is the status of every registered dependency check.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check returns an error if the dependency isn't ready.
+type Check func(ctx context.Context) error
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckReport is the result of a check.
+type CheckReport struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the status of all the checks, the service is up if all of them
+// are up.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckReport `json:"checks"`
+}
+
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the registered checks, each of them is limited by the timeout.
+type Checker struct {
+	mu      sync.RWMutex
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds the check of the dependency.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Check runs the checks concurrently.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	reports := make([]CheckReport, len(checks))
+	wg := sync.WaitGroup{}
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			reports[i] = c.run(ctx, check)
+		}(i, check.check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckReport, len(checks))}
+	for i, check := range checks {
+		if reports[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+		report.Checks[check.name] = reports[i]
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckReport {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return CheckReport{Status: StatusDown, Error: err.Error()}
+	}
+	return CheckReport{Status: StatusUp}
+}
+
+// ErrNotDone is the error of an unfinished initialization.
+var ErrNotDone = errors.New("not done yet")
+
+// Flag is the check of a one-off initialization.
+type Flag struct {
+	done int32
+}
+
+// Done marks the initialization finished.
+func (f *Flag) Done() {
+	atomic.StoreInt32(&f.done, 1)
+}
+
+func (f *Flag) IsDone() bool {
+	return atomic.LoadInt32(&f.done) == 1
+}
+
+func (f *Flag) Check(context.Context) error {
+	if !f.IsDone() {
+		return ErrNotDone
+	}
+	return nil
+}
+
+var timeNow = time.Now
+
+// MaxAge checks that the time returned by last isn't older than the max age,
+// the zero time is never fresh.
+func MaxAge(last func() time.Time, maxAge time.Duration) Check {
+	return func(context.Context) error {
+		at := last()
+		if at.IsZero() {
+			return ErrNotDone
+		}
+		if age := timeNow().Sub(at); age > maxAge {
+			return fmt.Errorf("last update %s ago, max %s", age.Truncate(time.Second), maxAge)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChecker_Check(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+	flag := new(Flag)
+	lastDeal := now.Add(-time.Minute)
+	c := NewChecker(10 * time.Millisecond)
+	c.Register("mongo", func(context.Context) error { return nil })
+	c.Register("deals", MaxAge(func() time.Time { return lastDeal }, 5*time.Minute))
+	c.Register("current_candles", flag.Check)
+	block := make(chan struct{})
+	defer close(block)
+	c.Register("slow", func(context.Context) error {
+		<-block
+		return nil
+	})
+
+	report := c.Check(context.Background())
+	assert.False(t, report.Up())
+	assert.Equal(t, CheckReport{Status: StatusUp}, report.Checks["mongo"])
+	assert.Equal(t, CheckReport{Status: StatusUp}, report.Checks["deals"])
+	assert.Equal(t, CheckReport{Status: StatusDown, Error: ErrNotDone.Error()}, report.Checks["current_candles"])
+	assert.Equal(t, CheckReport{Status: StatusDown, Error: context.DeadlineExceeded.Error()}, report.Checks["slow"])
+
+	flag.Done()
+	lastDeal = now.Add(-10 * time.Minute)
+	report = c.Check(context.Background())
+	assert.Equal(t, StatusUp, report.Checks["current_candles"].Status)
+	assert.Equal(t, CheckReport{Status: StatusDown, Error: "last update 10m0s ago, max 5m0s"}, report.Checks["deals"])
+}
+
+func TestReadinessHandler(t *testing.T) {
+	var mongoErr error
+	c := NewChecker(time.Second)
+	c.Register("mongo", func(context.Context) error { return mongoErr })
+	handler := ReadinessHandler(c)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mongoErr = errors.New("server selection timeout")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, Report{
+		Status: StatusDown,
+		Checks: map[string]CheckReport{"mongo": {Status: StatusDown, Error: "server selection timeout"}},
+	}, report)
+
+	rec = httptest.NewRecorder()
+	LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRunGRPC(t *testing.T) {
+	flag := new(Flag)
+	c := NewChecker(time.Second)
+	c.Register("current_candles", flag.Check)
+	srv := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunGRPC(ctx, c, srv, time.Millisecond, "ohlcv.OHLCVService")
+		close(done)
+	}()
+
+	status := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := srv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			return grpc_health_v1.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+	assert.Eventually(t, func() bool {
+		return status("ohlcv.OHLCVService") == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, time.Millisecond)
+	flag.Done()
+	assert.Eventually(t, func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_SERVING &&
+			status("ohlcv.OHLCVService") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""), "the shutdown isn't serving")
+}
@@ -6,6 +6,7 @@ import (
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"context"
 	"golang.org/x/sync/errgroup"
+	"io"
 	"strings"
 )
 
@@ -30,21 +31,48 @@ func NewPublisher(ctx context.Context, cfg KafkaConfig) (
 	return pub, nil
 }
 
-func NewConsumer(ctx context.Context, config KafkaConfig) pubsub.Subscriber {
+// Consumer consumes the topics of a Kafka consumer group.
+type Consumer struct {
+	pubsub.Subscriber
+	// closer is nil if the subscriber can't be closed.
+	closer io.Closer
+}
+
+// Close commits the offsets of the handled messages and leaves the consumer
+// group, it's called when the consumption of all the topics is finished. It
+// does nothing if the subscriber can't be closed.
+func (c *Consumer) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+func NewConsumer(ctx context.Context, config KafkaConfig) (*Consumer, error) {
 	group, _ := errgroup.WithContext(ctx)
 	brokers := strings.Split(config.Host, ",")
 	log := logger.FromContext(ctx).WithField("m", "main")
 	kSub, err := kafka.NewSubscriber(log, brokers, config.SslFlag)
 	if err != nil {
 		log.Errorf("[kafka]NewConsumer failed with err: %v", err)
-		panic(err)
+		return nil, err
 	}
 	consumer, err := pubsub.NewWrappedSubscriber(
 		kSub, group, pubsub.WSubscriberConfig{
-			Name:         "OhlcvConsumer",
+			Name:         config.ConsumerGroup,
 			WorkersCount: config.ConsumerCount,
 		},
 	)
+	if err != nil {
+		log.Errorf("[kafka]NewConsumer failed with err: %v", err)
+		return nil, err
+	}
+	// the offsets are committed on close, the subscribers without Close
+	// commit them on their own
+	closer, ok := kSub.(io.Closer)
+	if !ok {
+		log.Infof("[kafka]NewConsumer: subscriber %T can't be closed, the shutdown won't commit the offsets", kSub)
+	}
 
-	return consumer
+	return &Consumer{Subscriber: consumer, closer: closer}, nil
 }
@@ -0,0 +1,166 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+)
+
+// Component is a part of the application, it's started after the components
+// it requires and stopped before them.
+type Component struct {
+	Name     string
+	Requires []string
+	// Start initializes the component and starts its background work, the
+	// returned hook stops it. The hook may be nil.
+	Start func(ctx context.Context) (Hook, error)
+}
+
+// App starts the components with their requirements in the order of the
+// dependencies and stops them in the reverse order.
+type App struct {
+	stopTimeout time.Duration
+	components  map[string]Component
+	errs        []string
+	started     []namedHook
+}
+
+func NewApp(stopTimeout time.Duration) *App {
+	return &App{stopTimeout: stopTimeout, components: map[string]Component{}}
+}
+
+// Add registers the component, the errors of the registration are returned
+// by Start.
+func (a *App) Add(c Component) {
+	if _, ok := a.components[c.Name]; ok {
+		a.errs = append(a.errs, fmt.Sprintf("component %s is added twice", c.Name))
+		return
+	}
+	a.components[c.Name] = c
+}
+
+// Order returns the names of the components to start in order to run the
+// named ones, the requirements go first in the order they're listed.
+func (a *App) Order(names ...string) ([]string, error) {
+	if len(a.errs) > 0 {
+		return nil, fmt.Errorf("invalid components: %s", strings.Join(a.errs, "; "))
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(a.components))
+	order := make([]string, 0, len(a.components))
+	var path []string
+	var visit func(name, requiredBy string) error
+	visit = func(name, requiredBy string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		c, ok := a.components[name]
+		if !ok {
+			if requiredBy == "" {
+				return fmt.Errorf("unknown component %s", name)
+			}
+			return fmt.Errorf("unknown component %s required by %s", name, requiredBy)
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, required := range c.Requires {
+			if err := visit(required, name); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Start starts the named components and their requirements. If one of them
+// fails the started ones are stopped.
+func (a *App) Start(ctx context.Context, names ...string) error {
+	order, err := a.Order(names...)
+	if err != nil {
+		return err
+	}
+	for _, name := range order {
+		start := time.Now()
+		stop, err := startComponent(ctx, a.components[name])
+		if err != nil {
+			err = fmt.Errorf("can't start %s: %w", name, err)
+			if stopErr := a.Stop(ctx); stopErr != nil {
+				logger.FromContext(ctx).WithField("err", stopErr).Errorf("can't stop the started components")
+			}
+			return err
+		}
+		logger.FromContext(ctx).
+			WithField("component", name).
+			WithField("duration", time.Since(start).String()).
+			Infof("component started")
+		if stop != nil {
+			a.started = append(a.started, namedHook{name: name, hook: stop})
+		}
+	}
+	return nil
+}
+
+// startComponent reports the panic of the start as an error.
+func startComponent(ctx context.Context, c Component) (stop Hook, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.Start(ctx)
+}
+
+// Stop stops the started components in the reverse order within the stop
+// timeout.
+func (a *App) Stop(ctx context.Context) error {
+	m := NewManager(a.stopTimeout)
+	for i := len(a.started) - 1; i >= 0; i-- {
+		m.OnShutdown(a.started[i].name, a.started[i].hook)
+	}
+	a.started = nil
+	return m.Shutdown(ctx)
+}
+
+// Run runs the job in the background, the returned hook cancels it and waits
+// for it to return.
+func Run(ctx context.Context, job func(ctx context.Context)) Hook {
+	ctx, cancel := context.WithCancel(ctx)
+	return Cancel(cancel, Go(func() {
+		job(ctx)
+	}))
+}
+
+// Hooks runs the hooks one after another, all of them run if some fail.
+func Hooks(hooks ...Hook) Hook {
+	return func(ctx context.Context) error {
+		var failed []string
+		for _, h := range hooks {
+			if err := h(ctx); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+		if len(failed) > 0 {
+			return errors.New(strings.Join(failed, "; "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,141 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) component(name string, requires ...string) Component {
+	return Component{
+		Name:     name,
+		Requires: requires,
+		Start: func(context.Context) (Hook, error) {
+			r.events = append(r.events, "start "+name)
+			return func(context.Context) error {
+				r.events = append(r.events, "stop "+name)
+				return nil
+			}, nil
+		},
+	}
+}
+
+func TestApp_Order(t *testing.T) {
+	r := new(recorder)
+	app := NewApp(time.Second)
+	app.Add(r.component("tracing"))
+	app.Add(r.component("mongo", "tracing"))
+	app.Add(r.component("markets", "mongo"))
+	app.Add(r.component("http", "markets"))
+	app.Add(r.component("kafka"))
+	app.Add(r.component("consumption", "kafka", "markets"))
+	app.Add(r.component("grpc", "markets"))
+
+	order, err := app.Order("http", "consumption")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracing", "mongo", "markets", "http", "kafka", "consumption"}, order, "grpc isn't required")
+
+	t.Run("unknown", func(t *testing.T) {
+		app.Add(r.component("materializer", "klines"))
+		_, err := app.Order("materializer")
+		assert.EqualError(t, err, "unknown component klines required by materializer")
+		_, err = app.Order("ws")
+		assert.EqualError(t, err, "unknown component ws")
+	})
+	t.Run("cycle", func(t *testing.T) {
+		app.Add(r.component("a", "b"))
+		app.Add(r.component("b", "mongo", "a"))
+		_, err := app.Order("a")
+		assert.EqualError(t, err, "dependency cycle: a -> b -> a")
+	})
+	t.Run("twice", func(t *testing.T) {
+		app.Add(r.component("mongo"))
+		_, err := app.Order("http")
+		assert.EqualError(t, err, "invalid components: component mongo is added twice")
+	})
+}
+
+func TestApp_StartStop(t *testing.T) {
+	r := new(recorder)
+	app := NewApp(time.Second)
+	app.Add(r.component("mongo"))
+	app.Add(Component{
+		Name:     "broadcaster",
+		Requires: []string{"mongo"},
+		Start: func(context.Context) (Hook, error) {
+			r.events = append(r.events, "start broadcaster")
+			return nil, nil
+		},
+	})
+	app.Add(r.component("consumption", "broadcaster"))
+
+	require.NoError(t, app.Start(context.Background(), "consumption"))
+	require.NoError(t, app.Stop(context.Background()))
+	assert.Equal(t, []string{
+		"start mongo", "start broadcaster", "start consumption",
+		"stop consumption", "stop mongo",
+	}, r.events)
+}
+
+func TestApp_StartFailed(t *testing.T) {
+	for name, start := range map[string]func(context.Context) (Hook, error){
+		"error": func(context.Context) (Hook, error) {
+			return nil, errors.New("connection refused")
+		},
+		"panic": func(context.Context) (Hook, error) {
+			panic("connection refused")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := new(recorder)
+			app := NewApp(time.Second)
+			app.Add(r.component("mongo"))
+			app.Add(r.component("markets", "mongo"))
+			app.Add(Component{Name: "kafka", Requires: []string{"markets"}, Start: start})
+			app.Add(r.component("consumption", "kafka"))
+
+			err := app.Start(context.Background(), "consumption")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "can't start kafka: ")
+			assert.Contains(t, err.Error(), "connection refused")
+			assert.Equal(t, []string{"start mongo", "start markets", "stop markets", "stop mongo"}, r.events)
+			assert.NoError(t, app.Stop(context.Background()), "the stopped components aren't stopped again")
+			assert.Len(t, r.events, 4)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	stopped := make(chan struct{})
+	stop := Run(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	require.NoError(t, stop(context.Background()))
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("the job isn't stopped")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var calls int
+	hook := func(err error) Hook {
+		return func(context.Context) error {
+			calls++
+			return err
+		}
+	}
+	err := Hooks(hook(errors.New("commit failed")), hook(nil), hook(errors.New("disconnected")))(context.Background())
+	assert.EqualError(t, err, "commit failed; disconnected")
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,111 @@
+// Package lifecycle starts the components of the service in the order of
+// their dependencies and shuts them down in order: the hooks run one after
+// another within a common timeout.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+)
+
+// Hook stops a component, it should give up when the context is done.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager runs the shutdown hooks in the order they're added.
+type Manager struct {
+	timeout time.Duration
+	hooks   []namedHook
+}
+
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{timeout: timeout}
+}
+
+// OnShutdown adds the hook to the end of the shutdown.
+func (m *Manager) OnShutdown(name string, hook Hook) {
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// WaitSignal blocks until one of the signals is received or the context is
+// done. The signals have the default behaviour again when it returns, so a
+// second one kills a stuck shutdown.
+func WaitSignal(ctx context.Context, signals ...os.Signal) {
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, signals...)
+	defer signal.Stop(signalCh)
+	select {
+	case sig := <-signalCh:
+		logger.FromContext(ctx).WithField("signal", sig.String()).Infof("shutting down")
+	case <-ctx.Done():
+	}
+}
+
+// Shutdown runs all the hooks within the timeout. The hooks after a failed
+// or a timed out one still run, with the done context they're expected to
+// stop at once.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	var failed []string
+	for _, h := range m.hooks {
+		start := time.Now()
+		err := h.hook(ctx)
+		log := logger.FromContext(ctx).
+			WithField("hook", h.name).
+			WithField("duration", time.Since(start).String())
+		if err != nil {
+			log.WithField("err", err).Errorf("shutdown hook failed")
+			failed = append(failed, fmt.Sprintf("%s: %v", h.name, err))
+			continue
+		}
+		log.Infof("shutdown hook finished")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("shutdown failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// WaitDone waits for the channels to be closed, it fails if the context is
+// done first.
+func WaitDone(ctx context.Context, done ...<-chan struct{}) error {
+	for _, d := range done {
+		select {
+		case <-d:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Cancel returns the hook cancelling a context and waiting for the components
+// using it to finish.
+func Cancel(cancel context.CancelFunc, done ...<-chan struct{}) Hook {
+	return func(ctx context.Context) error {
+		cancel()
+		return WaitDone(ctx, done...)
+	}
+}
+
+// Go runs the function in a goroutine, the returned channel is closed when
+// it returns.
+func Go(f func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	return done
+}
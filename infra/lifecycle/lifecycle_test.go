@@ -0,0 +1,82 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Shutdown(t *testing.T) {
+	var order []string
+	hook := func(name string, err error) Hook {
+		return func(context.Context) error {
+			order = append(order, name)
+			return err
+		}
+	}
+	m := NewManager(time.Second)
+	m.OnShutdown("consumption", hook("consumption", nil))
+	m.OnShutdown("kafka", hook("kafka", errors.New("commit failed")))
+	m.OnShutdown("mongo", hook("mongo", nil))
+
+	err := m.Shutdown(context.Background())
+	assert.EqualError(t, err, "shutdown failed: kafka: commit failed")
+	assert.Equal(t, []string{"consumption", "kafka", "mongo"}, order, "the hooks after a failed one still run")
+}
+
+func TestManager_ShutdownTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	var lastErr error
+	m := NewManager(10 * time.Millisecond)
+	m.OnShutdown("deals", Cancel(func() {}, block))
+	m.OnShutdown("mongo", func(ctx context.Context) error {
+		lastErr = ctx.Err()
+		return nil
+	})
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.EqualError(t, err, "shutdown failed: deals: context deadline exceeded")
+	assert.Equal(t, context.DeadlineExceeded, lastErr, "the later hooks get the done context")
+}
+
+func TestWaitSignal(t *testing.T) {
+	done := Go(func() {
+		WaitSignal(context.Background(), syscall.SIGTERM)
+	})
+	// the signal isn't handled until WaitSignal is subscribed
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WaitSignal didn't return on the signal")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	WaitSignal(ctx, syscall.SIGTERM)
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := Go(func() {
+		<-ctx.Done()
+	})
+	assert.NoError(t, Cancel(cancel, stopped)(context.Background()))
+	assert.Error(t, ctx.Err())
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelTimeout()
+	assert.Equal(t, context.DeadlineExceeded, WaitDone(timeout, stopped, stuck))
+}
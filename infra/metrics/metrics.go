@@ -0,0 +1,142 @@
+// Package metrics defines the Prometheus metrics of the service, they're
+// served by Handler at /metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ohlcv"
+
+var (
+	// ConsumerLag is how late the last consumed message of a topic is.
+	ConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "consumer_lag_seconds",
+		Help:      "Time between the creation and the consumption of the last message of the topic.",
+	}, []string{"topic"})
+
+	// DealsConsumed counts the consumed deals, their rate is the deals per
+	// second of a market.
+	DealsConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deals_consumed_total",
+		Help:      "Deals consumed from Kafka by market.",
+	}, []string{"market"})
+
+	SaveDealDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "save_deal_duration_seconds",
+		Help:      "Duration of the deal validation and insertion into MongoDb.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	})
+
+	SaveDealErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "save_deal_errors_total",
+		Help:      "Deals which failed to be saved.",
+	})
+
+	// ChannelDropped counts the values dropped because a channel was full.
+	ChannelDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "channel_dropped_total",
+		Help:      "Values dropped because the channel was full.",
+	}, []string{"channel"})
+
+	MicrobatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "microbatch_size",
+		Help:      "Number of the charts in a published microbatch.",
+		Buckets:   prometheus.LinearBuckets(1, 1, 10),
+	})
+
+	CentrifugoPublishDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "centrifugo_publish_duration_seconds",
+		Help:      "Duration of a batch publication into Centrifugo.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	CentrifugoPublishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "centrifugo_publish_errors_total",
+		Help:      "Failed publications into Centrifugo, a failed batch counts once per message.",
+	})
+
+	MongoAggregationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_aggregation_duration_seconds",
+		Help:      "Duration of the MongoDb aggregations by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"operation"})
+
+	DealsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deals_dropped_total",
+		Help:      "Deals dropped for the slow deal subscribers.",
+	})
+
+	DealSubscriberDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deal_subscriber_dropped_total",
+		Help:      "Deals dropped for a connected deal subscriber.",
+	}, []string{"subscriber"})
+
+	MarketsRefreshedAt = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "markets_refreshed_at_seconds",
+		Help:      "Unix time of the last good market list.",
+	})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests by endpoint and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Duration of the gRPC calls and streams by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// Handler serves the metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterChannel exposes the length and the capacity of a channel, the
+// functions are called on every scrape.
+func RegisterChannel(name string, length, capacity func() int) {
+	labels := prometheus.Labels{"channel": name}
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "channel_length",
+		Help:        "Number of the values queued in the channel.",
+		ConstLabels: labels,
+	}, func() float64 {
+		return float64(length())
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "channel_capacity",
+		Help:        "Capacity of the channel.",
+		ConstLabels: labels,
+	}, func() float64 {
+		return float64(capacity())
+	})
+}
+
+// ObserveSince observes the seconds elapsed since the start.
+func ObserveSince(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// HTTPMiddleware observes the duration of the requests, the endpoint label
+// is returned by name, e.g. the route name.
+func HTTPMiddleware(name func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			ObserveSince(httpDuration.WithLabelValues(name(r), strconv.Itoa(recorder.code)), start)
+		})
+	}
+}
+
+// HTTPHandler observes the duration of the requests of one endpoint.
+func HTTPHandler(endpoint string, next http.Handler) http.Handler {
+	return HTTPMiddleware(func(*http.Request) string {
+		return endpoint
+	})(next)
+}
+
+// UnaryServerInterceptor observes the duration of the gRPC calls.
+func UnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	ObserveSince(grpcDuration.WithLabelValues(info.FullMethod, status.Code(err).String()), start)
+	return resp, err
+}
+
+// StreamServerInterceptor observes the duration of the gRPC streams.
+func StreamServerInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	ObserveSince(grpcDuration.WithLabelValues(info.FullMethod, status.Code(err).String()), start)
+	return err
+}
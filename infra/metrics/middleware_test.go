@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	h := HTTPHandler("test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Contains(t, scrape(), `ohlcv_http_request_duration_seconds_count{code="400",endpoint="test"} 2`)
+}
+
+func TestRegisterChannel(t *testing.T) {
+	ch := make(chan int, 4)
+	ch <- 1
+	RegisterChannel("test", func() int { return len(ch) }, func() int { return cap(ch) })
+
+	metrics := scrape()
+	assert.Contains(t, metrics, `ohlcv_channel_length{channel="test"} 1`)
+	assert.Contains(t, metrics, `ohlcv_channel_capacity{channel="test"} 4`)
+}
+
+func scrape() string {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
@@ -3,7 +3,9 @@ package mongo
 import (
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"bitbucket.org/novatechnologies/ohlcv/infra"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
 	"context"
+	"fmt"
 	"github.com/AlekSi/pointer"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -22,7 +24,8 @@ func NewMongoClient(
 		ApplyURI(config.ConnectionUrl).
 		SetServerAPIOptions(serverAPIOptions).
 		SetMaxPoolSize(100).
-		SetConnectTimeout(timeoutD)
+		SetConnectTimeout(timeoutD).
+		SetMonitor(tracing.NewMongoMonitor())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 
@@ -43,9 +46,12 @@ func InitDealsCollection(
 	ctx context.Context,
 	client *mongo.Client,
 	config infra.MongoDbConfig,
-) *mongo.Collection {
-	collection := initCollection(ctx, client.Database(config.DatabaseName), config.DealCollectionName, getDealsCollectionOptions())
-	createIndex(ctx, collection, "trades",
+) (*mongo.Collection, error) {
+	collection, err := initCollection(ctx, client.Database(config.DatabaseName), config.DealCollectionName, getDealsCollectionOptions())
+	if err != nil {
+		return nil, err
+	}
+	err = createIndex(ctx, collection, "trades",
 		bson.D{
 			{
 				"data.market",
@@ -55,6 +61,9 @@ func InitDealsCollection(
 				"t",
 				-1,
 			}}, false)
+	if err != nil {
+		return nil, err
+	}
 
 	// Unique indexes are not supported on collections clustered by _id ;c
 	// createIndex(ctx, collection, "dealid",
@@ -64,16 +73,19 @@ func InitDealsCollection(
 	//			1,
 	//		}}, true)
 
-	return collection
+	return collection, nil
 }
 
 func InitMinutesCollection(
 	ctx context.Context,
 	client *mongo.Client,
 	config infra.MongoDbConfig,
-) *mongo.Collection {
-	collection := initCollection(ctx, client.Database(config.DatabaseName), config.MinuteCandleCollectionName, getMinutesCollectionOptions())
-	createIndex(ctx, collection, "minutes",
+) (*mongo.Collection, error) {
+	collection, err := initCollection(ctx, client.Database(config.DatabaseName), config.MinuteCandleCollectionName, getMinutesCollectionOptions())
+	if err != nil {
+		return nil, err
+	}
+	err = createIndex(ctx, collection, "minutes",
 		bson.D{
 			{
 				"symbol", 1,
@@ -83,30 +95,60 @@ func InitMinutesCollection(
 				-1,
 			},
 		}, false)
-	return collection
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func InitKlinesCollection(
+	ctx context.Context,
+	client *mongo.Client,
+	config infra.MongoDbConfig,
+	collectionName string,
+) (*mongo.Collection, error) {
+	collection, err := initCollection(ctx, client.Database(config.DatabaseName), collectionName, options.CreateCollection())
+	if err != nil {
+		return nil, err
+	}
+	err = createIndex(ctx, collection, "klines",
+		bson.D{
+			{
+				"symbol", 1,
+			},
+			{
+				"openTime",
+				-1,
+			},
+		}, true)
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
 }
 
-func createIndex(ctx context.Context, coll *mongo.Collection, name string, keys bson.D, isUnique bool) {
+func createIndex(ctx context.Context, coll *mongo.Collection, name string, keys bson.D, isUnique bool) error {
 	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
 		Keys:    keys,
 		Options: options.Index().SetName(name).SetUnique(isUnique),
 	})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("can't create the index %s of %s: %w", name, coll.Name(), err)
 	}
+	return nil
 }
 
-func initCollection(ctx context.Context, db *mongo.Database, collectionName string, opt *options.CreateCollectionOptions) *mongo.Collection {
+func initCollection(ctx context.Context, db *mongo.Database, collectionName string, opt *options.CreateCollectionOptions) (*mongo.Collection, error) {
 	err := db.CreateCollection(
 		ctx,
 		collectionName,
 		opt,
 	)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("can't create the collection %s: %w", collectionName, err)
 	}
 
-	return db.Collection(collectionName)
+	return db.Collection(collectionName), nil
 }
 
 func getDealsCollectionOptions() *options.CreateCollectionOptions {
@@ -123,12 +165,13 @@ func getMinutesCollectionOptions() *options.CreateCollectionOptions {
 	return options.CreateCollection()
 }
 
-func CollectionExist(ctx context.Context, client *mongo.Client, dbName string, collectionName string) bool {
+func CollectionExist(ctx context.Context, client *mongo.Client, dbName string, collectionName string) (bool, error) {
 	db := client.Database(dbName)
 	collections, err := db.ListCollections(ctx, bson.M{})
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("can't list the collections of %s: %w", dbName, err)
 	}
+	defer collections.Close(ctx)
 
 	for collections.Next(ctx) {
 		var collectionInfo struct {
@@ -136,37 +179,59 @@ func CollectionExist(ctx context.Context, client *mongo.Client, dbName string, c
 		}
 		err := collections.Decode(&collectionInfo)
 		if err != nil {
-			panic(err)
+			return false, err
 		}
 
 		if collectionInfo.Name == collectionName {
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, collections.Err()
 }
 
 func GetOrCreateDealsCollection(ctx context.Context,
 	client *mongo.Client,
-	config infra.MongoDbConfig) *mongo.Collection {
+	config infra.MongoDbConfig) (*mongo.Collection, error) {
 	cName := config.DealCollectionName
-	if CollectionExist(ctx, client, config.DatabaseName, cName) {
-		return GetCollection(ctx, client, config, cName)
+	exist, err := CollectionExist(ctx, client, config.DatabaseName, cName)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return GetCollection(ctx, client, config, cName), nil
 	}
 	return InitDealsCollection(ctx, client, config)
 }
 
 func GetOrCreateMinutesCollection(ctx context.Context,
 	client *mongo.Client,
-	config infra.MongoDbConfig) *mongo.Collection {
+	config infra.MongoDbConfig) (*mongo.Collection, error) {
 	cName := config.MinuteCandleCollectionName
-	if CollectionExist(ctx, client, config.DatabaseName, cName) {
-		return GetCollection(ctx, client, config, cName)
+	exist, err := CollectionExist(ctx, client, config.DatabaseName, cName)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return GetCollection(ctx, client, config, cName), nil
 	}
 	return InitMinutesCollection(ctx, client, config)
 }
 
+func GetOrCreateKlinesCollection(ctx context.Context,
+	client *mongo.Client,
+	config infra.MongoDbConfig,
+	cName string) (*mongo.Collection, error) {
+	exist, err := CollectionExist(ctx, client, config.DatabaseName, cName)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return GetCollection(ctx, client, config, cName), nil
+	}
+	return InitKlinesCollection(ctx, client, config, cName)
+}
+
 func GetCollection(
 	ctx context.Context,
 	client *mongo.Client,
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mongoRequest struct {
+	connectionID string
+	requestID    int64
+}
+
+// mongoMonitor starts a client span on every command, the span ends on the
+// reply of the command.
+type mongoMonitor struct {
+	mu    sync.Mutex
+	spans map[mongoRequest]trace.Span
+}
+
+// NewMongoMonitor returns the command monitor tracing the MongoDb commands,
+// the spans are children of the spans of the operation contexts.
+func NewMongoMonitor() *event.CommandMonitor {
+	m := &mongoMonitor{spans: map[mongoRequest]trace.Span{}}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *mongoMonitor) started(ctx context.Context, e *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBNameKey.String(e.DatabaseName),
+		semconv.DBOperationKey.String(e.CommandName),
+	}
+	// the first element of a command is its name with the collection
+	if first, err := e.Command.IndexErr(0); err == nil {
+		if collection, ok := first.Value().StringValueOK(); ok {
+			attrs = append(attrs, semconv.DBMongoDBCollectionKey.String(collection))
+		}
+	}
+	_, span := Tracer().Start(
+		ctx,
+		"mongo."+e.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	m.mu.Lock()
+	m.spans[mongoRequest{e.ConnectionID, e.RequestID}] = span
+	m.mu.Unlock()
+}
+
+func (m *mongoMonitor) succeeded(_ context.Context, e *event.CommandSucceededEvent) {
+	m.finished(e.CommandFinishedEvent, nil)
+}
+
+func (m *mongoMonitor) failed(_ context.Context, e *event.CommandFailedEvent) {
+	m.finished(e.CommandFinishedEvent, errors.New(e.Failure))
+}
+
+func (m *mongoMonitor) finished(e event.CommandFinishedEvent, err error) {
+	key := mongoRequest{e.ConnectionID, e.RequestID}
+	m.mu.Lock()
+	span, ok := m.spans[key]
+	delete(m.spans, key)
+	m.mu.Unlock()
+	if ok {
+		EndSpan(span, err)
+	}
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	return recorder
+}
+
+func TestExtract(t *testing.T) {
+	newRecorder(t)
+	ctx, span := Tracer().Start(context.Background(), "producer")
+	defer span.End()
+
+	metadata := map[string]string{}
+	Inject(ctx, metadata)
+	assert.Contains(t, metadata, "traceparent")
+
+	remote := trace.SpanContextFromContext(Extract(context.Background(), metadata))
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), remote.SpanID())
+
+	assert.False(t, trace.SpanContextFromContext(Extract(context.Background(), nil)).IsValid())
+}
+
+func TestLinks(t *testing.T) {
+	newRecorder(t)
+	first, span := Tracer().Start(context.Background(), "first")
+	span.End()
+	second, span := Tracer().Start(context.Background(), "second")
+	span.End()
+
+	links := Links(first, nil, context.Background(), second)
+	require.Len(t, links, 2)
+	assert.Equal(t, trace.SpanContextFromContext(first), links[0].SpanContext)
+	assert.Equal(t, trace.SpanContextFromContext(second), links[1].SpanContext)
+}
+
+func TestMongoMonitor(t *testing.T) {
+	recorder := newRecorder(t)
+	monitor := NewMongoMonitor()
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	defer parent.End()
+
+	command, err := bson.Marshal(bson.D{{"aggregate", "deals"}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "ohlcv",
+		CommandName:  "aggregate",
+		RequestID:    1,
+		ConnectionID: "c",
+	})
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "ohlcv",
+		CommandName:  "insert",
+		RequestID:    2,
+		ConnectionID: "c",
+	})
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 2, ConnectionID: "c"},
+		Failure:              "duplicate key",
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 1, ConnectionID: "c"},
+	})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "mongo.insert", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "mongo.aggregate", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Contains(t, spans[1].Attributes(), attribute.String("db.mongodb.collection", "deals"))
+}
@@ -0,0 +1,108 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+)
+
+// CandleFilter selects candles for a subscriber, empty sets match everything.
+type CandleFilter struct {
+	Markets     map[string]struct{}
+	Resolutions map[model.Resolution]struct{}
+}
+
+func NewCandleFilter(markets []string, resolutions []model.Resolution) CandleFilter {
+	f := CandleFilter{}
+	if len(markets) > 0 {
+		f.Markets = make(map[string]struct{}, len(markets))
+		for _, m := range markets {
+			f.Markets[m] = struct{}{}
+		}
+	}
+	if len(resolutions) > 0 {
+		f.Resolutions = make(map[model.Resolution]struct{}, len(resolutions))
+		for _, r := range resolutions {
+			f.Resolutions[r] = struct{}{}
+		}
+	}
+	return f
+}
+
+func (f CandleFilter) Match(candle domain.Candle) bool {
+	if len(f.Markets) > 0 {
+		if _, ok := f.Markets[candle.Symbol]; !ok {
+			return false
+		}
+	}
+	if len(f.Resolutions) > 0 {
+		if _, ok := f.Resolutions[candle.Resolution]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type candleSubscriber struct {
+	filter  CandleFilter
+	channel chan domain.Candle
+}
+
+// Candle fans out live candle updates to the subscribers. Candle symbols are
+// market names.
+type Candle struct {
+	candleChan    chan domain.Candle
+	subscribers   map[string]candleSubscriber
+	subscribersMu sync.RWMutex
+}
+
+func NewCandle(candleChan chan domain.Candle) *Candle {
+	return &Candle{
+		candleChan:  candleChan,
+		subscribers: make(map[string]candleSubscriber),
+	}
+}
+
+func (c *Candle) Subscribe(key string, filter CandleFilter, channel chan domain.Candle) {
+	c.subscribersMu.Lock()
+	c.subscribers[key] = candleSubscriber{filter: filter, channel: channel}
+	c.subscribersMu.Unlock()
+}
+
+func (c *Candle) UnSubscribe(key string) {
+	c.subscribersMu.Lock()
+	delete(c.subscribers, key)
+	c.subscribersMu.Unlock()
+}
+
+func (c *Candle) Consume(ctx context.Context) {
+	for {
+		select {
+		case candle, ok := <-c.candleChan:
+			if !ok {
+				return
+			}
+			c.subscribersMu.RLock()
+			for key, subscriber := range c.subscribers {
+				if !subscriber.filter.Match(candle) {
+					continue
+				}
+				select {
+				case subscriber.channel <- candle:
+				default:
+					metrics.ChannelDropped.WithLabelValues("candle_subscriber").Inc()
+					logger.FromContext(ctx).
+						WithField("subscriber", key).
+						Errorf("channel candles overloaded")
+				}
+			}
+			c.subscribersMu.RUnlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
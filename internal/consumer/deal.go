@@ -1,50 +1,87 @@
 package consumer
 
 import (
-	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"context"
 	"sync"
 )
 
+// DealFilter selects deals for a subscriber, empty set matches everything.
+type DealFilter struct {
+	Markets map[string]struct{}
+}
+
+func NewDealFilter(markets []string) DealFilter {
+	f := DealFilter{}
+	if len(markets) > 0 {
+		f.Markets = make(map[string]struct{}, len(markets))
+		for _, m := range markets {
+			f.Markets[m] = struct{}{}
+		}
+	}
+	return f
+}
+
+func (f DealFilter) Match(deal *model.Deal) bool {
+	if len(f.Markets) > 0 {
+		if _, ok := f.Markets[deal.Data.Market]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 type Deal struct {
 	dealChan      chan *model.Deal
-	subscribers   map[string]chan *model.Deal
+	subscribers   map[string]*DealSubscriber
 	subscribersMu sync.RWMutex
 }
 
 func NewDeal(dealChan chan *model.Deal) *Deal {
 	return &Deal{
 		dealChan:    dealChan,
-		subscribers: make(map[string]chan *model.Deal),
+		subscribers: make(map[string]*DealSubscriber),
 	}
 }
 
-func (c *Deal) Subscribe(key string, channel chan *model.Deal) {
+func (c *Deal) Subscribe(key string, filter DealFilter, policy SubscriberPolicy) *DealSubscriber {
+	subscriber := newDealSubscriber(key, filter, policy)
 	c.subscribersMu.Lock()
-	c.subscribers[key] = channel
+	c.subscribers[key] = subscriber
 	c.subscribersMu.Unlock()
+	return subscriber
 }
 
 func (c *Deal) UnSubscribe(key string) {
 	c.subscribersMu.Lock()
 	delete(c.subscribers, key)
 	c.subscribersMu.Unlock()
+	metrics.DealSubscriberDropped.DeleteLabelValues(key)
 }
 
+// Consume delivers the deals to the subscribers until the context is done or
+// the deal channel is closed and drained.
 func (c *Deal) Consume(ctx context.Context) {
+	var matched []*DealSubscriber
 	for {
 		select {
-		case d := <-c.dealChan:
+		case d, ok := <-c.dealChan:
+			if !ok {
+				return
+			}
+			// a blocking subscriber must not hold the lock
+			matched = matched[:0]
 			c.subscribersMu.RLock()
-			for key := range c.subscribers {
-				select {
-				case c.subscribers[key] <- d:
-				default:
-					logger.FromContext(ctx).Errorf("channel deals overloaded")
+			for _, subscriber := range c.subscribers {
+				if subscriber.filter.Match(d) {
+					matched = append(matched, subscriber)
 				}
 			}
 			c.subscribersMu.RUnlock()
+			for _, subscriber := range matched {
+				subscriber.deliver(ctx, d)
+			}
 		case <-ctx.Done():
 			return
 		}
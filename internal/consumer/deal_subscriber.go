@@ -0,0 +1,170 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+)
+
+// Backpressure defines what happens to a deal when a subscriber's buffer is full.
+type Backpressure string
+
+const (
+	// DropNewest drops the deal which doesn't fit into the buffer.
+	DropNewest Backpressure = "drop-newest"
+	// DropOldest drops the oldest buffered deal to make room for the new one.
+	DropOldest Backpressure = "drop-oldest"
+	// Disconnect drops the newest deals, after MaxDrops of them the subscriber
+	// is disconnected.
+	Disconnect Backpressure = "disconnect"
+	// Block waits up to BlockTimeout for the buffer and then drops the deal.
+	// It delays the deals of all the other subscribers.
+	Block Backpressure = "block"
+)
+
+func ParseBackpressure(s string) (Backpressure, error) {
+	switch b := Backpressure(s); b {
+	case DropNewest, DropOldest, Disconnect, Block:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown backpressure policy %s", s)
+}
+
+type SubscriberPolicy struct {
+	Backpressure Backpressure
+	BufferSize   int
+	MaxDrops     uint64
+	BlockTimeout time.Duration
+}
+
+// DealSubscriber receives deals matched by its filter. Lost deals are
+// collected into a gap, Gaps signals when there is a gap to take.
+type DealSubscriber struct {
+	key     string
+	filter  DealFilter
+	policy  SubscriberPolicy
+	channel chan *model.Deal
+	gaps    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+
+	gapMu sync.Mutex
+	gap   model.DealsGap
+}
+
+func newDealSubscriber(key string, filter DealFilter, policy SubscriberPolicy) *DealSubscriber {
+	return &DealSubscriber{
+		key:     key,
+		filter:  filter,
+		policy:  policy,
+		channel: make(chan *model.Deal, policy.BufferSize),
+		gaps:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *DealSubscriber) Deals() <-chan *model.Deal {
+	return s.channel
+}
+
+func (s *DealSubscriber) Gaps() <-chan struct{} {
+	return s.gaps
+}
+
+// Done is closed when the subscriber is disconnected for being too slow.
+func (s *DealSubscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped returns the number of deals dropped since subscribing.
+func (s *DealSubscriber) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// TakeGap returns the period of deals dropped since the previous call.
+func (s *DealSubscriber) TakeGap() (model.DealsGap, bool) {
+	s.gapMu.Lock()
+	defer s.gapMu.Unlock()
+	if s.gap.Dropped == 0 {
+		return model.DealsGap{}, false
+	}
+	gap := s.gap
+	s.gap = model.DealsGap{}
+	return gap, true
+}
+
+func (s *DealSubscriber) deliver(ctx context.Context, d *model.Deal) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	switch s.policy.Backpressure {
+	case DropOldest:
+		for {
+			select {
+			case s.channel <- d:
+				return
+			default:
+			}
+			select {
+			case old := <-s.channel:
+				s.drop(ctx, old)
+			default:
+			}
+		}
+	case Block:
+		timer := time.NewTimer(s.policy.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.channel <- d:
+		case <-timer.C:
+			s.drop(ctx, d)
+		}
+	default:
+		select {
+		case s.channel <- d:
+		default:
+			s.drop(ctx, d)
+		}
+	}
+}
+
+func (s *DealSubscriber) drop(ctx context.Context, d *model.Deal) {
+	dropped := atomic.AddUint64(&s.dropped, 1)
+	metrics.DealsDropped.Inc()
+	metrics.DealSubscriberDropped.WithLabelValues(s.key).Inc()
+
+	t := d.T.Time()
+	s.gapMu.Lock()
+	if s.gap.Dropped == 0 || t.Before(s.gap.From) {
+		s.gap.From = t
+	}
+	if s.gap.Dropped == 0 || t.After(s.gap.To) {
+		s.gap.To = t
+	}
+	s.gap.Reason = "slow consumer"
+	s.gap.Dropped++
+	s.gapMu.Unlock()
+
+	select {
+	case s.gaps <- struct{}{}:
+	default:
+	}
+
+	if s.policy.Backpressure == Disconnect && dropped >= s.policy.MaxDrops {
+		s.once.Do(func() {
+			logger.FromContext(ctx).
+				WithField("subscriber", s.key).
+				Errorf("deals subscriber disconnected after %d drops", dropped)
+			close(s.done)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package consumer
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testDeal(i int) *model.Deal {
+	return &model.Deal{
+		T: primitive.NewDateTimeFromTime(time.Date(2020, 4, 14, 15, 45, i, 0, time.UTC)),
+		Data: model.DealData{
+			DealId: strconv.Itoa(i),
+			Market: "ETH_BTC",
+		},
+	}
+}
+
+func TestDealSubscriber_deliver(t *testing.T) {
+	ctx := context.Background()
+	t.Run("drop newest", func(t *testing.T) {
+		s := newDealSubscriber("1", DealFilter{}, SubscriberPolicy{Backpressure: DropNewest, BufferSize: 2})
+		for i := 0; i < 4; i++ {
+			s.deliver(ctx, testDeal(i))
+		}
+		assert.Equal(t, "0", (<-s.Deals()).Data.DealId)
+		assert.Equal(t, "1", (<-s.Deals()).Data.DealId)
+		assert.Equal(t, uint64(2), s.Dropped())
+		require.Len(t, s.Gaps(), 1)
+		gap, ok := s.TakeGap()
+		require.True(t, ok)
+		assert.Equal(t, testDeal(2).T.Time(), gap.From)
+		assert.Equal(t, testDeal(3).T.Time(), gap.To)
+		assert.Equal(t, uint64(2), gap.Dropped)
+		_, ok = s.TakeGap()
+		assert.False(t, ok)
+	})
+	t.Run("drop oldest", func(t *testing.T) {
+		s := newDealSubscriber("1", DealFilter{}, SubscriberPolicy{Backpressure: DropOldest, BufferSize: 2})
+		for i := 0; i < 4; i++ {
+			s.deliver(ctx, testDeal(i))
+		}
+		assert.Equal(t, "2", (<-s.Deals()).Data.DealId)
+		assert.Equal(t, "3", (<-s.Deals()).Data.DealId)
+		gap, ok := s.TakeGap()
+		require.True(t, ok)
+		assert.Equal(t, testDeal(0).T.Time(), gap.From)
+		assert.Equal(t, testDeal(1).T.Time(), gap.To)
+	})
+	t.Run("disconnect", func(t *testing.T) {
+		s := newDealSubscriber("1", DealFilter{}, SubscriberPolicy{Backpressure: Disconnect, BufferSize: 1, MaxDrops: 2})
+		s.deliver(ctx, testDeal(0))
+		s.deliver(ctx, testDeal(1))
+		select {
+		case <-s.Done():
+			t.Fatal("disconnected too early")
+		default:
+		}
+		s.deliver(ctx, testDeal(2))
+		<-s.Done()
+		s.deliver(ctx, testDeal(3))
+		assert.Equal(t, uint64(2), s.Dropped())
+	})
+	t.Run("block with timeout", func(t *testing.T) {
+		s := newDealSubscriber("1", DealFilter{}, SubscriberPolicy{
+			Backpressure: Block,
+			BufferSize:   1,
+			BlockTimeout: 50 * time.Millisecond,
+		})
+		s.deliver(ctx, testDeal(0))
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			<-s.Deals()
+		}()
+		s.deliver(ctx, testDeal(1))
+		assert.Equal(t, uint64(0), s.Dropped())
+		started := time.Now()
+		s.deliver(ctx, testDeal(2))
+		assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+		assert.Equal(t, uint64(1), s.Dropped())
+	})
+}
+
+func TestDeal_Consume(t *testing.T) {
+	dealChan := make(chan *model.Deal)
+	c := NewDeal(dealChan)
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		c.Consume(context.Background())
+	}()
+	all := c.Subscribe("all", NewDealFilter(nil), SubscriberPolicy{BufferSize: 10})
+	other := c.Subscribe("other", NewDealFilter([]string{"BTC_USDT"}), SubscriberPolicy{BufferSize: 10})
+	dealChan <- testDeal(0)
+	assert.Equal(t, "0", (<-all.Deals()).Data.DealId)
+	c.UnSubscribe("all")
+	dealChan <- testDeal(1)
+	btc := testDeal(2)
+	btc.Data.Market = "BTC_USDT"
+	dealChan <- btc
+	close(dealChan)
+	<-consumed
+
+	assert.Empty(t, all.Deals(), "the unsubscribed doesn't receive deals")
+	require.Len(t, other.Deals(), 1, "only the deals of the filter markets are received")
+	assert.Equal(t, btc, <-other.Deals())
+}
+
+func TestDeal_ConsumeBackpressure(t *testing.T) {
+	for _, tt := range []struct {
+		policy       SubscriberPolicy
+		received     []string
+		disconnected bool
+	}{
+		{policy: SubscriberPolicy{Backpressure: DropNewest}, received: []string{"0"}},
+		{policy: SubscriberPolicy{Backpressure: DropOldest}, received: []string{"2"}},
+		{policy: SubscriberPolicy{Backpressure: Disconnect, MaxDrops: 2}, received: []string{"0"}, disconnected: true},
+		{policy: SubscriberPolicy{Backpressure: Block, BlockTimeout: 10 * time.Millisecond}, received: []string{"0"}},
+	} {
+		t.Run(string(tt.policy.Backpressure), func(t *testing.T) {
+			dealChan := make(chan *model.Deal)
+			c := NewDeal(dealChan)
+			consumed := make(chan struct{})
+			go func() {
+				defer close(consumed)
+				c.Consume(context.Background())
+			}()
+			tt.policy.BufferSize = 1
+			slow := c.Subscribe("slow", NewDealFilter(nil), tt.policy)
+			fast := c.Subscribe("fast", NewDealFilter(nil), SubscriberPolicy{BufferSize: 10})
+			for i := 0; i < 3; i++ {
+				dealChan <- testDeal(i)
+			}
+			close(dealChan)
+			<-consumed
+
+			require.Len(t, fast.Deals(), 3, "the slow subscriber doesn't lose the deals of the others")
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, strconv.Itoa(i), (<-fast.Deals()).Data.DealId)
+			}
+			var received []string
+			for len(slow.Deals()) > 0 {
+				received = append(received, (<-slow.Deals()).Data.DealId)
+			}
+			assert.Equal(t, tt.received, received)
+			assert.Equal(t, uint64(2), slow.Dropped())
+			gap, ok := slow.TakeGap()
+			require.True(t, ok)
+			assert.Equal(t, uint64(2), gap.Dropped)
+			select {
+			case <-slow.Done():
+				assert.True(t, tt.disconnected, "disconnected")
+			default:
+				assert.False(t, tt.disconnected, "not disconnected")
+			}
+		})
+	}
+}
@@ -4,57 +4,221 @@ import (
 	"bitbucket.org/novatechnologies/common/infra/logger"
 	"bitbucket.org/novatechnologies/interfaces/matcher"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"context"
+	"fmt"
 	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// tickerThrottle is the minimal interval between two published updates of a
+// market ticker.
+const tickerThrottle = 250 * time.Millisecond
+
+type tickerSubscriber struct {
+	markets map[string]struct{}
+	channel chan *domain.TickerPriceChangeStatistics
+}
+
+var timeNow = time.Now
+
+// Ticker keeps rolling 24h tickers by market names and publishes their
+// updates.
 type Ticker struct {
-	cache       map[string]*domain.TickerPriceChangeStatistics
-	subscribers map[string]chan *matcher.Deal
-	mm          map[string]string
-	mu          sync.RWMutex
+	tickers      map[string]*domain.RollingTicker
+	deals        chan *matcher.Deal
+	subscribers  map[string]tickerSubscriber
+	dirty        map[string]struct{}
+	markets      *domain.MarketRegistry
+	eventsBroker domain.EventsBroker
+	// positions are the positions of the last added deals by market names.
+	positions map[string]*model.DealPosition
+	// restoredAt are the times of the last restores by market names, the
+	// older deals covered by the positions are skipped.
+	restoredAt map[string]time.Time
+	mu         sync.RWMutex
 }
 
-func NewTicker(mm map[string]string) *Ticker {
+// MarketTicker is the state of the ticker of a market.
+type MarketTicker struct {
+	Ticker   domain.TickerState `bson:"ticker"`
+	Position model.DealPosition `bson:"position"`
+}
+
+func NewTicker(markets *domain.MarketRegistry, eventsBroker domain.EventsBroker) *Ticker {
 	return &Ticker{
-		cache:       make(map[string]*domain.TickerPriceChangeStatistics),
-		subscribers: make(map[string]chan *matcher.Deal),
-		mm:          mm,
+		tickers:      make(map[string]*domain.RollingTicker),
+		deals:        make(chan *matcher.Deal, 1024),
+		subscribers:  make(map[string]tickerSubscriber),
+		dirty:        make(map[string]struct{}),
+		markets:      markets,
+		eventsBroker: eventsBroker,
+		positions:    make(map[string]*model.DealPosition),
+		restoredAt:   make(map[string]time.Time),
 	}
 }
 
 func (c *Ticker) Get(key string) (*domain.TickerPriceChangeStatistics, bool) {
-	c.mu.RLock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	result, ok := c.cache[key]
+	return c.statistics(key, timeNow())
+}
 
-	c.mu.RUnlock()
+// statistics should be called under the lock.
+func (c *Ticker) statistics(market string, now time.Time) (*domain.TickerPriceChangeStatistics, bool) {
+	ticker, ok := c.tickers[market]
+	if !ok {
+		return nil, false
+	}
+	return ticker.Statistics(now)
+}
 
-	return result, ok
+// Precision returns the precision of the market or the default one.
+func (c *Ticker) Precision(market string) domain.MarketPrecision {
+	return c.markets.Precision(market)
 }
 
-func (c *Ticker) Set(key string, value *domain.TickerPriceChangeStatistics) {
+// HandleMarketEvent drops the ticker of a removed market and moves the ticker
+// of a renamed one.
+func (c *Ticker) HandleMarketEvent(e domain.MarketEvent) {
 	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	c.cache[key] = value
+	switch e.Type {
+	case domain.MarketRemoved:
+		c.removeMarket(e.Market.Name)
+	case domain.MarketRenamed:
+		if ticker, ok := c.tickers[e.OldName]; ok {
+			ticker.Symbol = e.Market.Name
+			c.tickers[e.Market.Name] = ticker
+			delete(c.tickers, e.OldName)
+			c.dirty[e.Market.Name] = struct{}{}
+		}
+		if position, ok := c.positions[e.OldName]; ok {
+			c.positions[e.Market.Name] = position
+			delete(c.positions, e.OldName)
+		}
+		if restoredAt, ok := c.restoredAt[e.OldName]; ok {
+			c.restoredAt[e.Market.Name] = restoredAt
+			delete(c.restoredAt, e.OldName)
+		}
+		delete(c.dirty, e.OldName)
+	}
+}
 
-	c.mu.Unlock()
+// RemoveMarket drops the ticker of the market name.
+func (c *Ticker) RemoveMarket(market string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeMarket(market)
 }
 
-func (c *Ticker) GetChanel(key string) (chan *matcher.Deal, bool) {
+// removeMarket should be called under the lock.
+func (c *Ticker) removeMarket(market string) {
+	delete(c.tickers, market)
+	delete(c.dirty, market)
+	delete(c.positions, market)
+	delete(c.restoredAt, market)
+}
+
+// State returns the ticker of the market name with the position of its last
+// added deal, it returns false if the market has no ticker.
+func (c *Ticker) State(market string) (MarketTicker, bool) {
 	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	result, ok := c.subscribers[key]
+	ticker, ok := c.tickers[market]
+	if !ok {
+		return MarketTicker{}, false
+	}
+	state := MarketTicker{Ticker: ticker.State()}
+	if position := c.positions[market]; position != nil {
+		state.Position = model.DealPosition{T: position.T, IDs: append([]string(nil), position.IDs...)}
+	}
+	return state, true
+}
 
-	c.mu.RUnlock()
+// Restore replaces the ticker of the market name. The deals made before the
+// restore and covered by the position are skipped, so the replayed and the
+// redelivered deals aren't added twice.
+func (c *Ticker) Restore(market string, state MarketTicker) error {
+	ticker := domain.NewRollingTicker(market, domain.TickerWindow, c.Precision(market))
+	if err := ticker.Restore(state.Ticker); err != nil {
+		return err
+	}
 
-	return result, ok
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tickers[market] = ticker
+	c.dirty[market] = struct{}{}
+	position := state.Position
+	c.positions[market] = &position
+	c.restoredAt[market] = timeNow()
+	return nil
 }
 
-func (c *Ticker) Subscribe(key string, channel chan *matcher.Deal) {
+// Loaded skips the deals of the market names made before now and covered by
+// the positions, so the deals loaded from the storage aren't added twice when
+// Kafka redelivers them.
+func (c *Ticker) Loaded(markets ...string) {
+	now := timeNow()
 	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, market := range markets {
+		c.restoredAt[market] = now
+	}
+}
 
-	c.subscribers[key] = channel
+// AddDeal adds a stored deal, it is used to load the tickers on start.
+func (c *Ticker) AddDeal(deal *model.Deal) error {
+	return c.addDeal(deal.Data.Market, deal.T.Time(), deal.Data.DealId, deal.Data.Price, deal.Data.Volume)
+}
+
+func (c *Ticker) addDeal(market string, t time.Time, id string, price, qty primitive.Decimal128) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	position := c.positions[market]
+	if position == nil {
+		position = &model.DealPosition{}
+		c.positions[market] = position
+	}
+	if !t.After(c.restoredAt[market]) && position.Covers(t, id) {
+		// the deal is added already
+		return nil
+	}
+	ticker, ok := c.tickers[market]
+	if !ok {
+		ticker = domain.NewRollingTicker(market, domain.TickerWindow, c.Precision(market))
+		c.tickers[market] = ticker
+	}
+	if err := ticker.AddDeal(t, id, price, qty); err != nil {
+		return err
+	}
+	position.Advance(t, id)
+	c.dirty[market] = struct{}{}
+	return nil
+}
+
+// Subscribe registers a channel for ticker updates of the markets, empty
+// markets mean all of them.
+func (c *Ticker) Subscribe(key string, markets []string, channel chan *domain.TickerPriceChangeStatistics) {
+	s := tickerSubscriber{channel: channel}
+	if len(markets) > 0 {
+		s.markets = make(map[string]struct{}, len(markets))
+		for _, m := range markets {
+			s.markets[m] = struct{}{}
+		}
+	}
+
+	c.mu.Lock()
+
+	c.subscribers[key] = s
 
 	c.mu.Unlock()
 }
@@ -67,46 +231,98 @@ func (c *Ticker) UnSubscribe(key string) {
 	c.mu.Unlock()
 }
 
-func (c *Ticker) UpdateWithNewDeal(ctx context.Context, key string, value *matcher.Deal) {
-	c.mu.Lock()
-	ch, ok := c.subscribers[key]
-	if !ok {
-		logger.FromContext(ctx).Errorf("can't find subscriber %s", key)
-	} else {
+func (c *Ticker) UpdateWithNewDeal(ctx context.Context, value *matcher.Deal) {
+	select {
+	case c.deals <- value:
+	default:
+		metrics.ChannelDropped.WithLabelValues("ticker_deals").Inc()
+		logger.FromContext(ctx).Errorf("update ticker chanel overloaded")
+	}
+}
+
+func (c *Ticker) ConsumeNewDeals(ctx context.Context) {
+	for {
 		select {
-		case ch <- value:
-		default:
-			logger.FromContext(ctx).Errorf("update ticker chanel overloaded")
+		case <-ctx.Done():
+			return
+		case deal := <-c.deals:
+			if err := c.AddLiveDeal(deal); err != nil {
+				logger.FromContext(ctx).
+					WithField("dealMessage", deal).
+					Errorf("can't add deal to ticker %v", err)
+			}
 		}
+	}
+}
 
+// AddLiveDeal adds a consumed deal at once, UpdateWithNewDeal queues it.
+func (c *Ticker) AddLiveDeal(deal *matcher.Deal) error {
+	market, ok := c.markets.Name(deal.GetMarket())
+	if !ok {
+		market = deal.GetMarket()
 	}
-	c.mu.Unlock()
+	price, err := primitive.ParseDecimal128(deal.GetPrice())
+	if err != nil {
+		return fmt.Errorf("can't parse deal price: %w", err)
+	}
+	qty, err := primitive.ParseDecimal128(deal.GetAmount())
+	if err != nil {
+		return fmt.Errorf("can't parse deal amount: %w", err)
+	}
+	return c.addDeal(market, time.Unix(0, deal.GetCreatedAt()), deal.GetId(), price, qty)
 }
 
-func (c *Ticker) ConsumeNewDeals(ctx context.Context) {
-	for k, m := range c.mm {
-		ch := make(chan *matcher.Deal, 1024)
-		c.Subscribe(k, ch)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case deal := <-ch:
-				ticker, ok := c.Get(m)
-				if !ok {
-					logger.FromContext(ctx).
-						WithField("method", "deal.UpdateTickerFromDeal in consuming").
-						WithField("dealMessage", deal).
-						Errorf("ticker not found")
-					continue
+// PublishUpdates sends the changed tickers to the subscribers and the events
+// broker, not more often than once per tickerThrottle for a market.
+func (c *Ticker) PublishUpdates(ctx context.Context) {
+	t := time.NewTicker(tickerThrottle)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			now := timeNow()
+			c.mu.Lock()
+			for market, ticker := range c.tickers {
+				if ticker.Evict(now) {
+					c.dirty[market] = struct{}{}
 				}
-				ticker.LastPrice = deal.GetPrice()
-				ticker.LastQty = deal.GetAmount()
-				ticker.CloseTime = deal.GetCreatedAt()
-				ticker.LastId = deal.GetId()
-				c.mu.Lock()
-				c.cache[deal.GetMarket()] = ticker
+			}
+			if len(c.dirty) == 0 {
 				c.mu.Unlock()
+				continue
+			}
+			tickers := make([]*domain.TickerPriceChangeStatistics, 0, len(c.dirty))
+			for market := range c.dirty {
+				if stat, ok := c.statistics(market, now); ok {
+					tickers = append(tickers, stat)
+				}
+			}
+			c.dirty = make(map[string]struct{})
+			subscribers := make(map[string]tickerSubscriber, len(c.subscribers))
+			for key, s := range c.subscribers {
+				subscribers[key] = s
+			}
+			c.mu.Unlock()
+
+			c.eventsBroker.Publish(domain.EvTypeTickers, domain.NewEvent(ctx, tickers))
+			for key, s := range subscribers {
+				for _, ticker := range tickers {
+					if len(s.markets) > 0 {
+						if _, ok := s.markets[ticker.Symbol]; !ok {
+							continue
+						}
+					}
+					select {
+					case s.channel <- ticker:
+					default:
+						metrics.ChannelDropped.WithLabelValues("ticker_subscriber").Inc()
+						logger.FromContext(ctx).
+							WithField("subscriber", key).
+							Errorf("channel tickers overloaded")
+					}
+				}
 			}
 		}
 	}
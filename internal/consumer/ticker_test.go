@@ -0,0 +1,207 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/interfaces/matcher"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTicker_PublishUpdates(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventsBroker := broker.NewInMemory()
+	published := make(chan []*domain.TickerPriceChangeStatistics, 10)
+	eventsBroker.Subscribe(domain.EvTypeTickers, func(e *domain.Event) error {
+		published <- e.MustGetTickers()
+		return nil
+	})
+	c := NewTicker(domain.NewMarketRegistry([]market.Market{
+		{ID: "id1", Name: "ETH_BTC"},
+		{ID: "id2", Name: "BTC_USDT"},
+	}), eventsBroker)
+	for _, market := range []string{"ETH_BTC", "BTC_USDT"} {
+		c.AddDeal(&model.Deal{
+			T: primitive.NewDateTimeFromTime(now.Add(-time.Hour)),
+			Data: model.DealData{
+				Price:  model.MustParseDecimal("1"),
+				Volume: model.MustParseDecimal("2"),
+				Market: market,
+				DealId: "1",
+			},
+		})
+	}
+	ch := make(chan *domain.TickerPriceChangeStatistics, 10)
+	c.Subscribe("s", []string{"ETH_BTC"}, ch)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.ConsumeNewDeals(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.PublishUpdates(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	tickers := <-published
+	assert.Len(t, tickers, 2)
+	assert.Equal(t, "ETH_BTC", (<-ch).Symbol)
+
+	for _, price := range []string{"2", "3", "4"} {
+		c.UpdateWithNewDeal(ctx, &matcher.Deal{
+			Id:        price,
+			Market:    "id1",
+			Price:     price,
+			Amount:    "1",
+			CreatedAt: now.UnixNano(),
+		})
+	}
+	require.Eventually(t, func() bool {
+		ticker, _ := c.Get("ETH_BTC")
+		return ticker.LastPrice == "4"
+	}, time.Second, time.Millisecond)
+	// the updates in one throttling interval are published once
+	ticker := <-ch
+	if ticker.LastPrice != "4" {
+		ticker = <-ch
+	}
+	assert.Equal(t, "4", ticker.LastPrice)
+	assert.Equal(t, "4", ticker.LastId)
+	assert.Equal(t, "4", ticker.HighPrice)
+	assert.Equal(t, "5", ticker.Volume)
+	assert.Equal(t, 4, ticker.Count)
+	select {
+	case ticker := <-ch:
+		t.Fatalf("unexpected update %v", ticker)
+	case <-time.After(2 * tickerThrottle):
+	}
+}
+
+func TestTicker_HandleMarketEvent(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	markets := domain.NewMarketRegistry([]market.Market{
+		{ID: "id1", Name: "ETH_BTC"},
+		{ID: "id2", Name: "BTC_USDT"},
+	})
+	c := NewTicker(markets, broker.NewInMemory())
+	markets.Subscribe(c.HandleMarketEvent)
+	for _, m := range []string{"ETH_BTC", "BTC_USDT"} {
+		c.AddDeal(&model.Deal{
+			T: primitive.NewDateTimeFromTime(now.Add(-time.Hour)),
+			Data: model.DealData{
+				Price:  model.MustParseDecimal("1"),
+				Volume: model.MustParseDecimal("2"),
+				Market: m,
+				DealId: "1",
+			},
+		})
+	}
+
+	markets.Update([]market.Market{{ID: "id1", Name: "ETH_USDT"}})
+
+	_, ok := c.Get("BTC_USDT")
+	assert.False(t, ok)
+	_, ok = c.Get("ETH_BTC")
+	assert.False(t, ok)
+	ticker, ok := c.Get("ETH_USDT")
+	require.True(t, ok)
+	assert.Equal(t, "ETH_USDT", ticker.Symbol)
+}
+
+func TestTicker_Restore(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	markets := domain.NewMarketRegistry([]market.Market{{ID: "id1", Name: "ETH_BTC"}})
+	deal := func(id string, at time.Time, price string) *model.Deal {
+		return &model.Deal{
+			T: primitive.NewDateTimeFromTime(at),
+			Data: model.DealData{
+				Price:  model.MustParseDecimal(price),
+				Volume: model.MustParseDecimal("1"),
+				Market: "ETH_BTC",
+				DealId: id,
+			},
+		}
+	}
+	c := NewTicker(markets, broker.NewInMemory())
+	require.NoError(t, c.AddDeal(deal("1", now.Add(-time.Hour), "1")))
+	require.NoError(t, c.AddDeal(deal("2", now.Add(-time.Minute), "2")))
+	state, ok := c.State("ETH_BTC")
+	require.True(t, ok)
+	assert.True(t, now.Add(-time.Minute).Equal(state.Position.T))
+	assert.Equal(t, []string{"2"}, state.Position.IDs)
+	_, ok = c.State("BTC_USDT")
+	assert.False(t, ok)
+
+	restored := NewTicker(markets, broker.NewInMemory())
+	require.NoError(t, restored.Restore("ETH_BTC", state))
+	// the replayed or redelivered deals are skipped
+	require.NoError(t, restored.AddDeal(deal("1", now.Add(-time.Hour), "1")))
+	require.NoError(t, restored.AddDeal(deal("2", now.Add(-time.Minute), "2")))
+	require.NoError(t, restored.AddDeal(deal("3", now.Add(-time.Minute), "3")))
+	require.NoError(t, c.AddDeal(deal("3", now.Add(-time.Minute), "3")))
+
+	expected, _ := c.Get("ETH_BTC")
+	ticker, ok := restored.Get("ETH_BTC")
+	require.True(t, ok)
+	assert.Equal(t, expected, ticker)
+	assert.Equal(t, 3, ticker.Count)
+}
+
+func TestTicker_Loaded(t *testing.T) {
+	now := time.Date(2020, 4, 14, 15, 45, 56, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	markets := domain.NewMarketRegistry([]market.Market{{ID: "id1", Name: "ETH_BTC"}, {ID: "id2", Name: "BTC_USDT"}})
+	c := NewTicker(markets, broker.NewInMemory())
+	loaded := &model.Deal{
+		T: primitive.NewDateTimeFromTime(now.Add(-time.Minute)),
+		Data: model.DealData{
+			Price:  model.MustParseDecimal("1"),
+			Volume: model.MustParseDecimal("1"),
+			Market: "ETH_BTC",
+			DealId: "1",
+		},
+	}
+	require.NoError(t, c.AddDeal(loaded))
+	c.Loaded("ETH_BTC", "BTC_USDT")
+
+	// the redelivered deal is skipped
+	require.NoError(t, c.AddLiveDeal(&matcher.Deal{Id: "1", Market: "id1", Price: "1", Amount: "1", CreatedAt: now.Add(-time.Minute).UnixNano()}))
+	require.NoError(t, c.AddLiveDeal(&matcher.Deal{Id: "2", Market: "id1", Price: "2", Amount: "1", CreatedAt: now.Add(-time.Minute).UnixNano()}))
+	require.NoError(t, c.AddLiveDeal(&matcher.Deal{Id: "3", Market: "id2", Price: "3", Amount: "1", CreatedAt: now.Add(-time.Hour).UnixNano()}))
+	// the deal made before the load but stored after it is added
+	require.NoError(t, c.AddLiveDeal(&matcher.Deal{Id: "5", Market: "id1", Price: "2", Amount: "1", CreatedAt: now.Add(-time.Second).UnixNano()}))
+	ticker, ok := c.Get("ETH_BTC")
+	require.True(t, ok)
+	assert.Equal(t, 3, ticker.Count)
+	ticker, ok = c.Get("BTC_USDT")
+	require.True(t, ok)
+	assert.Equal(t, 1, ticker.Count, "the market without loaded deals skips nothing")
+
+	assert.Error(t, c.AddLiveDeal(&matcher.Deal{Id: "4", Market: "id1", Price: "x", Amount: "1", CreatedAt: now.UnixNano()}))
+}
@@ -0,0 +1,355 @@
+// Package decimal128 implements exact arithmetic over primitive.Decimal128.
+//
+// The results keep up to 34 significant digits, longer ones are rounded half
+// to even like in IEEE 754. NaN and infinities are rejected with ErrNotFinite.
+package decimal128
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoundingMode tells how to drop the digits beyond a scale.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest neighbour, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest neighbour, ties to the even one.
+	RoundHalfEven
+	// RoundDown rounds toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+const maxDigits = 34
+
+var (
+	ErrNotFinite      = errors.New("decimal128: NaN or infinity")
+	ErrOverflow       = errors.New("decimal128: overflow")
+	ErrDivisionByZero = errors.New("decimal128: division by zero")
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// Zero is 0E0, unlike the zero value of primitive.Decimal128 which is
+// 0E-6176.
+var Zero = mustFromBig(new(big.Int), 0)
+
+// FromInt returns the integer as a decimal.
+func FromInt(n int64) primitive.Decimal128 {
+	return mustFromBig(big.NewInt(n), 0)
+}
+
+func mustFromBig(coefficient *big.Int, exp int) primitive.Decimal128 {
+	d, err := fromBig(coefficient, exp)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func toBig(d primitive.Decimal128) (*big.Int, int, error) {
+	coefficient, exp, err := d.BigInt()
+	if err != nil {
+		return nil, 0, ErrNotFinite
+	}
+	return coefficient, exp, nil
+}
+
+// fromBig rounds the coefficient half to even to the maximum digits.
+func fromBig(coefficient *big.Int, exp int) (primitive.Decimal128, error) {
+	if extra := digits(coefficient) - maxDigits; extra > 0 {
+		coefficient = quo(coefficient, pow10(extra), RoundHalfEven)
+		exp += extra
+		// rounding up may add a digit, e.g. 99..9 to 100..0
+		if digits(coefficient) > maxDigits {
+			coefficient.Quo(coefficient, bigTen)
+			exp++
+		}
+	}
+	d, ok := primitive.ParseDecimal128FromBigInt(coefficient, exp)
+	if !ok {
+		return primitive.Decimal128{}, ErrOverflow
+	}
+	return d, nil
+}
+
+func digits(n *big.Int) int {
+	if n.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(n).String())
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// quo divides with rounding.
+func quo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// the sign of the exact quotient
+	sign := num.Sign() * den.Sign()
+	awayFromZero := false
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		awayFromZero = true
+	case RoundFloor:
+		awayFromZero = sign < 0
+	case RoundCeiling:
+		awayFromZero = sign > 0
+	case RoundHalfUp, RoundHalfEven:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch half.Cmp(new(big.Int).Abs(den)) {
+		case 1:
+			awayFromZero = true
+		case 0:
+			awayFromZero = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+	if awayFromZero {
+		if sign < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// align returns the coefficients of a and b with the smaller exponent.
+func align(a, b primitive.Decimal128) (*big.Int, *big.Int, int, error) {
+	ca, ea, err := toBig(a)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	cb, eb, err := toBig(b)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	// the exponent of zero doesn't matter, e.g. of the zero value 0E-6176
+	switch {
+	case ca.Sign() == 0:
+		ea = eb
+	case cb.Sign() == 0:
+		eb = ea
+	}
+	switch {
+	case ea > eb:
+		ca.Mul(ca, pow10(ea-eb))
+		ea = eb
+	case eb > ea:
+		cb.Mul(cb, pow10(eb-ea))
+	}
+	return ca, cb, ea, nil
+}
+
+// Compare returns -1, 0 or 1 if a is less than, equal to or greater than b
+// whatever their exponents are, e.g. 10E0 < 2E1.
+func Compare(a, b primitive.Decimal128) (int, error) {
+	ca, _, err := toBig(a)
+	if err != nil {
+		return 0, err
+	}
+	cb, _, err := toBig(b)
+	if err != nil {
+		return 0, err
+	}
+	// the different signs don't need the alignment
+	if sa, sb := ca.Sign(), cb.Sign(); sa != sb {
+		if sa > sb {
+			return 1, nil
+		}
+		return -1, nil
+	}
+	ca, cb, _, err = align(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return ca.Cmp(cb), nil
+}
+
+// Sign returns -1, 0 or 1 for a negative, zero or positive d.
+func Sign(d primitive.Decimal128) (int, error) {
+	c, _, err := toBig(d)
+	if err != nil {
+		return 0, err
+	}
+	return c.Sign(), nil
+}
+
+func Min(a, b primitive.Decimal128) (primitive.Decimal128, error) {
+	c, err := Compare(a, b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	if c > 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+func Max(a, b primitive.Decimal128) (primitive.Decimal128, error) {
+	c, err := Compare(a, b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	if c < 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+func Add(a, b primitive.Decimal128) (primitive.Decimal128, error) {
+	ca, cb, exp, err := align(a, b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	return fromBig(ca.Add(ca, cb), exp)
+}
+
+func Sub(a, b primitive.Decimal128) (primitive.Decimal128, error) {
+	ca, cb, exp, err := align(a, b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	return fromBig(ca.Sub(ca, cb), exp)
+}
+
+func Mul(a, b primitive.Decimal128) (primitive.Decimal128, error) {
+	ca, ea, err := toBig(a)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	cb, eb, err := toBig(b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	return fromBig(ca.Mul(ca, cb), ea+eb)
+}
+
+// Div returns a/b rounded to the scale decimal places.
+func Div(a, b primitive.Decimal128, scale int32, mode RoundingMode) (primitive.Decimal128, error) {
+	ca, ea, err := toBig(a)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	cb, eb, err := toBig(b)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	if cb.Sign() == 0 {
+		return primitive.Decimal128{}, ErrDivisionByZero
+	}
+	if ca.Sign() == 0 {
+		return fromBig(ca, -int(scale))
+	}
+	// a/b = ca/cb * 10^(ea-eb), the result coefficient is scaled by 10^scale
+	if k := ea - eb + int(scale); k >= 0 {
+		ca.Mul(ca, pow10(k))
+	} else {
+		cb.Mul(cb, pow10(-k))
+	}
+	return fromBig(quo(ca, cb, mode), -int(scale))
+}
+
+// Round rounds d to the scale decimal places, it doesn't add the digits.
+func Round(d primitive.Decimal128, scale int32, mode RoundingMode) (primitive.Decimal128, error) {
+	c, exp, err := toBig(d)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	if extra := -int(scale) - exp; extra > 0 {
+		return fromBig(quo(c, pow10(extra), mode), -int(scale))
+	}
+	return d, nil
+}
+
+// Normalize removes the trailing zeros of the coefficient, so the equal
+// values have the same representation, zero becomes 0E0.
+func Normalize(d primitive.Decimal128) (primitive.Decimal128, error) {
+	c, exp, err := toBig(d)
+	if err != nil {
+		return primitive.Decimal128{}, err
+	}
+	if c.Sign() == 0 {
+		return Zero, nil
+	}
+	r := new(big.Int)
+	for {
+		q, _ := new(big.Int).QuoRem(c, bigTen, r)
+		if r.Sign() != 0 || exp >= primitive.MaxDecimal128Exp {
+			break
+		}
+		c = q
+		exp++
+	}
+	return fromBig(c, exp)
+}
+
+// String formats d in plain notation without the trailing zeros, e.g.
+// 1.50E-5 as 0.000015. NaN and infinities keep their names.
+func String(d primitive.Decimal128) string {
+	n, err := Normalize(d)
+	if err != nil {
+		return d.String()
+	}
+	c, exp, _ := n.BigInt()
+	return plain(c, exp, 0)
+}
+
+// StringFixed formats d rounded half up to the scale decimal places with the
+// trailing zeros kept, e.g. 1.5 as 1.50 for the scale 2.
+func StringFixed(d primitive.Decimal128, scale int32) string {
+	r, err := Round(d, scale, RoundHalfUp)
+	if err != nil {
+		return d.String()
+	}
+	c, exp, _ := r.BigInt()
+	return plain(c, exp, int(scale))
+}
+
+// plain formats c*10^exp with at least scale decimal places.
+func plain(c *big.Int, exp, scale int) string {
+	neg := c.Sign() < 0
+	s := new(big.Int).Abs(c).String()
+	if c.Sign() == 0 && exp > 0 {
+		exp = 0
+	}
+	if exp > 0 {
+		s += strings.Repeat("0", exp)
+		exp = 0
+	}
+	places := -exp
+	if places < scale {
+		s += strings.Repeat("0", scale-places)
+		places = scale
+	}
+	if places > 0 {
+		if len(s) <= places {
+			s = strings.Repeat("0", places-len(s)+1) + s
+		}
+		s = s[:len(s)-places] + "." + s[len(s)-places:]
+	}
+	if neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
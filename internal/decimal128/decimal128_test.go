@@ -0,0 +1,269 @@
+package decimal128
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mustParse(t *testing.T, s string) primitive.Decimal128 {
+	t.Helper()
+	d, err := primitive.ParseDecimal128(s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestCompare(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"10E0", "2E1", -1},
+		{"2E1", "10E0", 1},
+		{"20", "2E1", 0},
+		{"1.50", "1.5", 0},
+		{"-10E0", "-2E1", 1},
+		{"0.0001", "1E-5", 1},
+		{"-0.0001", "1E-5", -1},
+		{"0", "-0.00", 0},
+		{"374", "-130.6543", 1},
+	} {
+		got, err := Compare(mustParse(t, tt.a), mustParse(t, tt.b))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s vs %s", tt.a, tt.b)
+	}
+
+	got, err := Compare(primitive.Decimal128{}, mustParse(t, "0.001"))
+	require.NoError(t, err)
+	assert.Equal(t, -1, got)
+
+	_, err = Compare(mustParse(t, "NaN"), Zero)
+	assert.ErrorIs(t, err, ErrNotFinite)
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := Add(primitive.Decimal128{}, mustParse(t, "1.5"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", sum.String())
+
+	diff, err := Sub(mustParse(t, "0.3"), mustParse(t, "0.1"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.2", diff.String())
+
+	product, err := Mul(mustParse(t, "0.00000001"), mustParse(t, "123456789012345678901234567890"))
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890123456789012.3456789", String(product))
+
+	// the result is rounded to 34 digits half to even
+	sum, err = Add(mustParse(t, "9999999999999999999999999999999999"), mustParse(t, "0.5"))
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000000000000000000000000000", String(sum))
+
+	_, err = Div(FromInt(1), Zero, 2, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+
+	for _, tt := range []struct {
+		mode RoundingMode
+		a, b string
+		want string
+	}{
+		{RoundHalfUp, "1", "8", "0.13"},
+		{RoundHalfEven, "1", "8", "0.12"},
+		{RoundHalfEven, "3", "8", "0.38"},
+		{RoundDown, "-2", "3", "-0.66"},
+		{RoundUp, "-2", "3", "-0.67"},
+		{RoundFloor, "2", "3", "0.66"},
+		{RoundFloor, "-2", "3", "-0.67"},
+		{RoundCeiling, "-2", "3", "-0.66"},
+		{RoundCeiling, "1E-10", "3", "0.01"},
+	} {
+		q, err := Div(mustParse(t, tt.a), mustParse(t, tt.b), 2, tt.mode)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, q.String(), "%s/%s mode %d", tt.a, tt.b, tt.mode)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for s, want := range map[string]string{
+		"1.500":   "1.5",
+		"1500E-3": "1.5",
+		"100":     "1E+2",
+		"0.000":   "0",
+		"-0.10":   "-0.1",
+	} {
+		n, err := Normalize(mustParse(t, s))
+		require.NoError(t, err)
+		assert.Equal(t, want, n.String(), s)
+	}
+	n, err := Normalize(primitive.Decimal128{})
+	require.NoError(t, err)
+	assert.Equal(t, Zero, n)
+}
+
+func TestString(t *testing.T) {
+	for s, want := range map[string]string{
+		"1.50E-5":  "0.000015",
+		"1.5E+3":   "1500",
+		"-0.0":     "0",
+		"12.50":    "12.5",
+		"NaN":      "NaN",
+		"Infinity": "Infinity",
+	} {
+		assert.Equal(t, want, String(mustParse(t, s)), s)
+	}
+	assert.Equal(t, "0", String(primitive.Decimal128{}))
+	assert.Equal(t, "1.50", StringFixed(mustParse(t, "1.5"), 2))
+	assert.Equal(t, "1.26", StringFixed(mustParse(t, "1.255"), 2))
+	assert.Equal(t, "-1.26", StringFixed(mustParse(t, "-1.255"), 2))
+	assert.Equal(t, "0.00000000", StringFixed(primitive.Decimal128{}, 8))
+	assert.Equal(t, "100", StringFixed(mustParse(t, "1E+2"), 0))
+}
+
+// randomDecimal is a decimal of up to 18 digits and up to 12 decimal places.
+type randomDecimal struct {
+	d decimal.Decimal
+}
+
+func (randomDecimal) Generate(r *rand.Rand, _ int) reflect.Value {
+	coefficient := r.Int63n(1_000_000_000_000_000_000)
+	if r.Intn(2) == 0 {
+		coefficient = -coefficient
+	}
+	switch r.Intn(4) {
+	case 0:
+		coefficient %= 1000
+	case 1:
+		coefficient = 0
+	}
+	d := decimal.New(coefficient, int32(r.Intn(18)-12))
+	return reflect.ValueOf(randomDecimal{d})
+}
+
+func (r randomDecimal) decimal128(t *testing.T) primitive.Decimal128 {
+	d, ok := primitive.ParseDecimal128FromBigInt(r.d.Coefficient(), int(r.d.Exponent()))
+	require.True(t, ok)
+	return d
+}
+
+// round34 rounds half to even to 34 significant digits like the results.
+func round34(d decimal.Decimal) decimal.Decimal {
+	if extra := len(new(big.Int).Abs(d.Coefficient()).String()) - maxDigits; extra > 0 {
+		return d.RoundBank(-(d.Exponent() + int32(extra)))
+	}
+	return d
+}
+
+// equal compares the values ignoring the exponents.
+func equal(t *testing.T, want decimal.Decimal, got primitive.Decimal128, err error) bool {
+	want = round34(want)
+	if err != nil {
+		t.Log(err)
+		return false
+	}
+	g, err := decimal.NewFromString(String(got))
+	if err != nil {
+		t.Log(err)
+		return false
+	}
+	if !want.Equal(g) {
+		t.Logf("want %s, got %s", want, got)
+		return false
+	}
+	return true
+}
+
+func TestProperties(t *testing.T) {
+	config := &quick.Config{MaxCount: 2000}
+	check := func(name string, f interface{}) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, quick.Check(f, config))
+		})
+	}
+
+	check("compare", func(a, b randomDecimal) bool {
+		got, err := Compare(a.decimal128(t), b.decimal128(t))
+		return err == nil && got == a.d.Cmp(b.d)
+	})
+	check("min max", func(a, b randomDecimal) bool {
+		min, err := Min(a.decimal128(t), b.decimal128(t))
+		if !equal(t, decimal.Min(a.d, b.d), min, err) {
+			return false
+		}
+		max, err := Max(a.decimal128(t), b.decimal128(t))
+		return equal(t, decimal.Max(a.d, b.d), max, err)
+	})
+	check("add", func(a, b randomDecimal) bool {
+		got, err := Add(a.decimal128(t), b.decimal128(t))
+		return equal(t, a.d.Add(b.d), got, err)
+	})
+	check("sub", func(a, b randomDecimal) bool {
+		got, err := Sub(a.decimal128(t), b.decimal128(t))
+		return equal(t, a.d.Sub(b.d), got, err)
+	})
+	check("mul", func(a, b randomDecimal) bool {
+		got, err := Mul(a.decimal128(t), b.decimal128(t))
+		return equal(t, a.d.Mul(b.d), got, err)
+	})
+	check("div half up", func(a, b randomDecimal, scale uint8) bool {
+		if b.d.IsZero() {
+			return true
+		}
+		s := int32(scale % 16)
+		got, err := Div(a.decimal128(t), b.decimal128(t), s, RoundHalfUp)
+		return equal(t, a.d.DivRound(b.d, s), got, err)
+	})
+	check("div down", func(a, b randomDecimal, scale uint8) bool {
+		if b.d.IsZero() {
+			return true
+		}
+		s := int32(scale % 16)
+		got, err := Div(a.decimal128(t), b.decimal128(t), s, RoundDown)
+		q, _ := a.d.QuoRem(b.d, s)
+		return equal(t, q, got, err)
+	})
+	check("round", func(a randomDecimal, scale uint8) bool {
+		s := int32(scale % 14)
+		d := a.decimal128(t)
+		for mode, want := range map[RoundingMode]decimal.Decimal{
+			RoundHalfUp:   a.d.Round(s),
+			RoundHalfEven: a.d.RoundBank(s),
+			RoundDown:     a.d.RoundDown(s),
+			RoundUp:       a.d.RoundUp(s),
+			RoundFloor:    a.d.RoundFloor(s),
+			RoundCeiling:  a.d.RoundCeil(s),
+		} {
+			got, err := Round(d, s, mode)
+			if !equal(t, want, got, err) {
+				t.Logf("mode %d", mode)
+				return false
+			}
+		}
+		return true
+	})
+	check("string fixed", func(a randomDecimal, scale uint8) bool {
+		s := int32(scale % 14)
+		if got, want := StringFixed(a.decimal128(t), s), a.d.StringFixed(s); got != want {
+			t.Logf("want %s, got %s", want, got)
+			return false
+		}
+		return true
+	})
+	check("string", func(a randomDecimal) bool {
+		return String(a.decimal128(t)) == a.d.String()
+	})
+}
+
+func TestFromBig_roundsToMaxDigits(t *testing.T) {
+	c, ok := new(big.Int).SetString("12345678901234567890123456789012345", 10)
+	require.True(t, ok)
+	d, err := fromBig(c, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678901234567890123456789012340", String(d))
+}
@@ -0,0 +1,128 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+)
+
+const leaderKeyPrefix = "leader/"
+
+// Leader elects one of the instances to run the singleton jobs of the
+// election. The jobs run while the instance leads and are cancelled as soon
+// as the leadership is lost.
+type Leader struct {
+	store    Store
+	election string
+	instance string
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	leading bool
+	renewed time.Time
+	jobs    []func(ctx context.Context)
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewLeader returns the election of the instance, the leadership is held for
+// the TTL and renewed every third of it.
+func NewLeader(store Store, election, instance string, ttl time.Duration) *Leader {
+	return &Leader{store: store, election: election, instance: instance, ttl: ttl}
+}
+
+// Go registers the job run while the instance leads, it should return when
+// its context is done. The jobs are registered before Run.
+func (l *Leader) Go(job func(ctx context.Context)) {
+	l.mu.Lock()
+	l.jobs = append(l.jobs, job)
+	l.mu.Unlock()
+}
+
+func (l *Leader) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leading
+}
+
+// Run campaigns every third of the TTL until the context is done, the jobs
+// are stopped then.
+func (l *Leader) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	defer l.stepDown(ctx)
+	for {
+		if err := l.Campaign(ctx, timeNow()); err != nil {
+			logger.FromContext(ctx).
+				WithField("err", err).
+				WithField("election", l.election).
+				Errorf("can't campaign")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Campaign acquires or renews the leadership and starts or stops the jobs if
+// it's changed. The leader steps down if it can't renew the leadership before
+// it expires.
+func (l *Leader) Campaign(ctx context.Context, now time.Time) error {
+	ok, err := l.store.Acquire(ctx, leaderKeyPrefix+l.election, l.instance, now, now.Add(l.ttl))
+	if err != nil {
+		l.mu.Lock()
+		expired := l.leading && now.Sub(l.renewed) >= l.ttl
+		l.mu.Unlock()
+		if expired {
+			l.stepDown(ctx)
+		}
+		return err
+	}
+	if !ok {
+		l.stepDown(ctx)
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.renewed = now
+	if l.leading {
+		return nil
+	}
+	l.leading = true
+	logger.FromContext(ctx).WithField("election", l.election).WithField("instance", l.instance).Infof("leading")
+	jobsCtx, cancel := context.WithCancel(ctx)
+	l.cancel = cancel
+	for _, job := range l.jobs {
+		l.running.Add(1)
+		go func(job func(ctx context.Context)) {
+			defer l.running.Done()
+			job(jobsCtx)
+		}(job)
+	}
+	return nil
+}
+
+// Resign releases the leadership, so another instance takes it at once. The
+// jobs should be stopped before.
+func (l *Leader) Resign(ctx context.Context) error {
+	l.stepDown(ctx)
+	return l.store.Release(ctx, leaderKeyPrefix+l.election, l.instance)
+}
+
+// stepDown stops the jobs and waits for them.
+func (l *Leader) stepDown(ctx context.Context) {
+	l.mu.Lock()
+	if !l.leading {
+		l.mu.Unlock()
+		return
+	}
+	l.leading = false
+	l.cancel()
+	l.mu.Unlock()
+	l.running.Wait()
+	logger.FromContext(ctx).WithField("election", l.election).WithField("instance", l.instance).Infof("stepped down")
+}
@@ -0,0 +1,92 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type candidate struct {
+	*Leader
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func newCandidate(store Store, name string) *candidate {
+	c := &candidate{
+		Leader:  NewLeader(store, "materializer", name, 15*time.Second),
+		started: make(chan struct{}, 10),
+		stopped: make(chan struct{}, 10),
+	}
+	c.Go(func(ctx context.Context) {
+		c.started <- struct{}{}
+		<-ctx.Done()
+		c.stopped <- struct{}{}
+	})
+	return c
+}
+
+func TestLeader_Campaign(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	a := newCandidate(store, "a")
+	b := newCandidate(store, "b")
+
+	require.NoError(t, a.Campaign(ctx, now))
+	require.NoError(t, b.Campaign(ctx, now))
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader(), "the leadership is held by another instance")
+	<-a.started
+
+	now = now.Add(5 * time.Second)
+	require.NoError(t, a.Campaign(ctx, now))
+	assert.True(t, a.IsLeader())
+	assert.Empty(t, a.started, "the jobs aren't restarted on renewal")
+
+	t.Run("failover", func(t *testing.T) {
+		now = now.Add(16 * time.Second)
+		require.NoError(t, b.Campaign(ctx, now))
+		assert.True(t, b.IsLeader(), "the expired leadership is taken")
+		<-b.started
+
+		require.NoError(t, a.Campaign(ctx, now))
+		assert.False(t, a.IsLeader())
+		assert.Len(t, a.stopped, 1, "the jobs are stopped before Campaign returns")
+		<-a.stopped
+	})
+	t.Run("resign", func(t *testing.T) {
+		require.NoError(t, b.Resign(ctx))
+		assert.False(t, b.IsLeader())
+		assert.Len(t, b.stopped, 1)
+		<-b.stopped
+
+		require.NoError(t, a.Campaign(ctx, now))
+		assert.True(t, a.IsLeader(), "the released leadership is taken at once")
+		<-a.started
+		require.NoError(t, a.Resign(ctx))
+	})
+}
+
+func TestLeader_stepDownExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &failingStore{Memory: NewMemory()}
+	a := newCandidate(store, "a")
+	require.NoError(t, a.Campaign(ctx, now))
+	<-a.started
+
+	store.err = errors.New("server selection timeout")
+	now = now.Add(10 * time.Second)
+	require.Error(t, a.Campaign(ctx, now))
+	assert.True(t, a.IsLeader(), "the leadership isn't expired yet")
+
+	now = now.Add(5 * time.Second)
+	require.Error(t, a.Campaign(ctx, now))
+	assert.False(t, a.IsLeader(), "the expired leadership could be taken by another instance")
+	<-a.stopped
+}
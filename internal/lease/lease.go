@@ -0,0 +1,73 @@
+// Package lease coordinates the instances with expiring leases: a lease is
+// held by one owner until it expires or is released.
+package lease
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Lease struct {
+	Key     string
+	Owner   string
+	Expires time.Time
+}
+
+// Store keeps the leases. The expiries are compared with the clocks of the
+// instances, so the TTL of the leases should be much longer than their skew.
+type Store interface {
+	// Acquire takes the lease of the key for the owner until the expiry or
+	// renews it, it returns false if another owner holds the lease.
+	Acquire(ctx context.Context, key, owner string, now, expires time.Time) (bool, error)
+	// Release drops the lease if it's held by the owner.
+	Release(ctx context.Context, key, owner string) error
+	// List returns the unexpired leases of the keys with the prefix.
+	List(ctx context.Context, prefix string, now time.Time) ([]Lease, error)
+}
+
+// Memory keeps the leases of the instances of a single process.
+type Memory struct {
+	mu     sync.Mutex
+	leases map[string]Lease
+}
+
+func NewMemory() *Memory {
+	return &Memory{leases: map[string]Lease{}}
+}
+
+func (m *Memory) Acquire(_ context.Context, key, owner string, now, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[key]; ok && l.Owner != owner && l.Expires.After(now) {
+		return false, nil
+	}
+	m.leases[key] = Lease{Key: key, Owner: owner, Expires: expires}
+	return true, nil
+}
+
+func (m *Memory) Release(_ context.Context, key, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[key]; ok && l.Owner == owner {
+		delete(m.leases, key)
+	}
+	return nil
+}
+
+func (m *Memory) List(_ context.Context, prefix string, now time.Time) ([]Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	leases := make([]Lease, 0)
+	for key, l := range m.leases {
+		if strings.HasPrefix(key, prefix) && l.Expires.After(now) {
+			leases = append(leases, l)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].Key < leases[j].Key
+	})
+	return leases, nil
+}
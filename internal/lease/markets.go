@@ -0,0 +1,281 @@
+package lease
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+)
+
+const (
+	instanceKeyPrefix = "instance/"
+	marketKeyPrefix   = "market/"
+)
+
+var timeNow = time.Now
+
+type EventType int
+
+const (
+	// Acquired is sent before the instance owns the market, its state
+	// should be rebuilt from the storage then. The deals consumed during the
+	// rebuild should be held.
+	Acquired EventType = iota + 1
+	// Released is sent after the instance stops owning the market.
+	Released
+	// Taken is sent after the instance owns the acquired market, the held
+	// deals should be applied then.
+	Taken
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Acquired:
+		return "acquired"
+	case Released:
+		return "released"
+	case Taken:
+		return "taken"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is a change of the ownership of a market.
+type Event struct {
+	Type   EventType
+	Market string
+}
+
+// Markets shares the markets between the live instances: every instance
+// holds the leases of its fair share of them. The markets of a failed
+// instance are taken by the others when its leases expire.
+type Markets struct {
+	store    Store
+	instance string
+	ttl      time.Duration
+	markets  func() []string
+
+	mu          sync.RWMutex
+	owned       map[string]struct{}
+	renewed     time.Time
+	subscribers []func(context.Context, Event)
+}
+
+// NewMarkets returns the ownership of the markets listed by the function
+// for the instance, the leases are held for the TTL and renewed every third
+// of it.
+func NewMarkets(store Store, instance string, ttl time.Duration, markets func() []string) *Markets {
+	return &Markets{
+		store:    store,
+		instance: instance,
+		ttl:      ttl,
+		markets:  markets,
+		owned:    map[string]struct{}{},
+	}
+}
+
+// Subscribe registers a function called on every change of the ownership.
+// It's called synchronously by Balance, so the market is owned after the
+// function rebuilding its state returns and the deals held during the
+// rebuild are applied on Taken, before the next change of the market.
+func (m *Markets) Subscribe(f func(context.Context, Event)) {
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, f)
+	m.mu.Unlock()
+}
+
+func (m *Markets) Owns(market string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.owned[market]
+	return ok
+}
+
+// Owned returns the sorted owned markets.
+func (m *Markets) Owned() []string {
+	m.mu.RLock()
+	owned := make([]string, 0, len(m.owned))
+	for market := range m.owned {
+		owned = append(owned, market)
+	}
+	m.mu.RUnlock()
+	sort.Strings(owned)
+	return owned
+}
+
+// Run balances the markets every third of the TTL until the context is done.
+// The markets are dropped if the leases can't be renewed before they expire.
+func (m *Markets) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.ttl / 3)
+	defer ticker.Stop()
+	for {
+		now := timeNow()
+		if err := m.Balance(ctx, now); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't balance the markets")
+			m.dropExpired(ctx, now)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Balance renews the leases of the owned markets, releases the markets above
+// the fair share and acquires the free ones up to it. The state of every
+// acquired market is rebuilt during the pass, so the leases expire in the TTL
+// from their own acquisition and the markets left once a third of the TTL is
+// spent are acquired by the next passes, the owned leases are renewed in time
+// then.
+func (m *Markets) Balance(ctx context.Context, now time.Time) error {
+	started := timeNow()
+	clock := func() time.Time {
+		return now.Add(timeNow().Sub(started))
+	}
+	if _, err := m.store.Acquire(ctx, instanceKeyPrefix+m.instance, m.instance, now, now.Add(m.ttl)); err != nil {
+		return fmt.Errorf("can't renew the instance: %w", err)
+	}
+	instances, err := m.store.List(ctx, instanceKeyPrefix, now)
+	if err != nil {
+		return fmt.Errorf("can't list the instances: %w", err)
+	}
+	leases, err := m.store.List(ctx, marketKeyPrefix, now)
+	if err != nil {
+		return fmt.Errorf("can't list the markets: %w", err)
+	}
+	held := make(map[string]string, len(leases))
+	for _, l := range leases {
+		held[l.Key[len(marketKeyPrefix):]] = l.Owner
+	}
+	markets := m.markets()
+	sort.Strings(markets)
+	exist := make(map[string]struct{}, len(markets))
+	for _, market := range markets {
+		exist[market] = struct{}{}
+	}
+	share := len(markets)
+	if len(instances) > 1 {
+		share = (len(markets) + len(instances) - 1) / len(instances)
+	}
+
+	owned := m.Owned()
+	kept := owned[:0]
+	for _, market := range owned {
+		if _, ok := exist[market]; !ok {
+			m.release(ctx, market)
+			continue
+		}
+		at := clock()
+		ok, err := m.store.Acquire(ctx, marketKeyPrefix+market, m.instance, at, at.Add(m.ttl))
+		if err != nil {
+			return fmt.Errorf("can't renew the market %s: %w", market, err)
+		}
+		if !ok {
+			// the lease has expired and another instance has taken it
+			m.drop(ctx, market)
+			continue
+		}
+		kept = append(kept, market)
+	}
+	m.mu.Lock()
+	m.renewed = now
+	m.mu.Unlock()
+	for len(kept) > share {
+		m.release(ctx, kept[len(kept)-1])
+		kept = kept[:len(kept)-1]
+	}
+	for _, market := range markets {
+		if len(kept) >= share {
+			break
+		}
+		if m.Owns(market) {
+			continue
+		}
+		if _, ok := held[market]; ok {
+			continue
+		}
+		at := clock()
+		if at.Sub(now) >= m.ttl/3 {
+			break
+		}
+		ok, err := m.store.Acquire(ctx, marketKeyPrefix+market, m.instance, at, at.Add(m.ttl))
+		if err != nil {
+			return fmt.Errorf("can't acquire the market %s: %w", market, err)
+		}
+		if ok {
+			m.own(ctx, market)
+			kept = append(kept, market)
+		}
+	}
+	return nil
+}
+
+// ReleaseAll releases the leases of the markets and of the instance, so the
+// others take the markets at once. The subscribers aren't notified.
+func (m *Markets) ReleaseAll(ctx context.Context) error {
+	for _, market := range m.Owned() {
+		if err := m.store.Release(ctx, marketKeyPrefix+market, m.instance); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	m.owned = map[string]struct{}{}
+	m.mu.Unlock()
+	return m.store.Release(ctx, instanceKeyPrefix+m.instance, m.instance)
+}
+
+func (m *Markets) own(ctx context.Context, market string) {
+	m.notify(ctx, Event{Type: Acquired, Market: market})
+	m.mu.Lock()
+	m.owned[market] = struct{}{}
+	m.mu.Unlock()
+	m.notify(ctx, Event{Type: Taken, Market: market})
+}
+
+func (m *Markets) release(ctx context.Context, market string) {
+	if err := m.store.Release(ctx, marketKeyPrefix+market, m.instance); err != nil {
+		logger.FromContext(ctx).
+			WithField("err", err).
+			WithField("market", market).
+			Errorf("can't release the market, it's taken when the lease expires")
+	}
+	m.drop(ctx, market)
+}
+
+func (m *Markets) drop(ctx context.Context, market string) {
+	m.mu.Lock()
+	delete(m.owned, market)
+	m.mu.Unlock()
+	m.notify(ctx, Event{Type: Released, Market: market})
+}
+
+// dropExpired drops the markets if their leases could have been taken by the
+// other instances.
+func (m *Markets) dropExpired(ctx context.Context, now time.Time) {
+	m.mu.RLock()
+	expired := now.Sub(m.renewed) >= m.ttl
+	m.mu.RUnlock()
+	if !expired {
+		return
+	}
+	for _, market := range m.Owned() {
+		m.drop(ctx, market)
+	}
+}
+
+func (m *Markets) notify(ctx context.Context, e Event) {
+	logger.FromContext(ctx).
+		WithField("market", e.Market).
+		WithField("instance", m.instance).
+		Infof("market %s", e.Type)
+	m.mu.RLock()
+	subscribers := m.subscribers
+	m.mu.RUnlock()
+	for _, f := range subscribers {
+		f(ctx, e)
+	}
+}
@@ -0,0 +1,171 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type instance struct {
+	*Markets
+	events []Event
+}
+
+func newInstance(t *testing.T, store Store, name string, markets *[]string) *instance {
+	i := &instance{}
+	i.Markets = NewMarkets(store, name, 15*time.Second, func() []string {
+		return append([]string(nil), *markets...)
+	})
+	i.Subscribe(func(_ context.Context, e Event) {
+		switch e.Type {
+		case Acquired:
+			assert.False(t, i.Owns(e.Market), "the market is owned after the state is rebuilt")
+		case Taken:
+			assert.True(t, i.Owns(e.Market), "the held deals are applied once the market is owned")
+		}
+		i.events = append(i.events, e)
+	})
+	return i
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	ok, err := store.Acquire(ctx, "market/1", "a", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = store.Acquire(ctx, "market/1", "b", now, now.Add(time.Minute))
+	assert.False(t, ok, "the lease is held by another owner")
+	ok, _ = store.Acquire(ctx, "market/1", "b", now.Add(time.Minute), now.Add(2*time.Minute))
+	assert.True(t, ok, "the lease is expired")
+
+	require.NoError(t, store.Release(ctx, "market/1", "a"))
+	leases, _ := store.List(ctx, "market/", now.Add(time.Minute))
+	assert.Equal(t, []Lease{{Key: "market/1", Owner: "b", Expires: now.Add(2 * time.Minute)}}, leases, "only the owner releases the lease")
+	require.NoError(t, store.Release(ctx, "market/1", "b"))
+	leases, _ = store.List(ctx, "market/", now)
+	assert.Empty(t, leases)
+}
+
+func TestMarkets_Balance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	markets := []string{"1", "2", "3", "4", "5"}
+	a := newInstance(t, store, "a", &markets)
+
+	require.NoError(t, a.Balance(ctx, now))
+	assert.Equal(t, markets, a.Owned(), "the single instance owns all the markets")
+	assert.Len(t, a.events, 10)
+
+	b := newInstance(t, store, "b", &markets)
+	require.NoError(t, b.Balance(ctx, now))
+	assert.Empty(t, b.Owned(), "the markets held by another instance aren't taken")
+
+	a.events = nil
+	now = now.Add(5 * time.Second)
+	require.NoError(t, a.Balance(ctx, now))
+	assert.Equal(t, []string{"1", "2", "3"}, a.Owned(), "the markets above the fair share are released")
+	assert.Equal(t, []Event{{Type: Released, Market: "5"}, {Type: Released, Market: "4"}}, a.events)
+	require.NoError(t, b.Balance(ctx, now))
+	assert.Equal(t, []string{"4", "5"}, b.Owned())
+	assert.Equal(t, []Event{
+		{Type: Acquired, Market: "4"}, {Type: Taken, Market: "4"},
+		{Type: Acquired, Market: "5"}, {Type: Taken, Market: "5"},
+	}, b.events)
+
+	t.Run("removed market", func(t *testing.T) {
+		markets = []string{"1", "2", "3", "4"}
+		b.events = nil
+		require.NoError(t, b.Balance(ctx, now))
+		assert.Equal(t, []string{"4"}, b.Owned())
+		assert.Equal(t, []Event{{Type: Released, Market: "5"}}, b.events)
+	})
+	t.Run("failover", func(t *testing.T) {
+		b.events = nil
+		now = now.Add(16 * time.Second)
+		require.NoError(t, b.Balance(ctx, now))
+		assert.Equal(t, []string{"1", "2", "3", "4"}, b.Owned(), "the expired markets are taken")
+		assert.Len(t, b.events, 6)
+
+		a.events = nil
+		require.NoError(t, a.Balance(ctx, now))
+		assert.Empty(t, a.Owned(), "the leases taken by another instance are dropped")
+		assert.Len(t, a.events, 3)
+	})
+	t.Run("release all", func(t *testing.T) {
+		require.NoError(t, b.ReleaseAll(ctx))
+		assert.Empty(t, b.Owned())
+		require.NoError(t, a.Balance(ctx, now))
+		assert.Equal(t, []string{"1", "2", "3", "4"}, a.Owned(), "the released markets are taken at once")
+	})
+}
+
+type failingStore struct {
+	*Memory
+	err error
+}
+
+func (s *failingStore) Acquire(ctx context.Context, key, owner string, now, expires time.Time) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.Memory.Acquire(ctx, key, owner, now, expires)
+}
+
+func TestMarkets_dropExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &failingStore{Memory: NewMemory()}
+	markets := []string{"1", "2"}
+	a := newInstance(t, store, "a", &markets)
+	require.NoError(t, a.Balance(ctx, now))
+
+	store.err = errors.New("server selection timeout")
+	now = now.Add(10 * time.Second)
+	require.Error(t, a.Balance(ctx, now))
+	a.dropExpired(ctx, now)
+	assert.Equal(t, markets, a.Owned(), "the leases aren't expired yet")
+
+	now = now.Add(5 * time.Second)
+	a.dropExpired(ctx, now)
+	assert.Empty(t, a.Owned(), "the expired leases could be taken by the others")
+}
+
+func TestMarkets_Balance_slowRebuild(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock := now
+	timeNow = func() time.Time {
+		return clock
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+	store := NewMemory()
+	markets := []string{"1", "2", "3", "4"}
+	a := newInstance(t, store, "a", &markets)
+	a.Subscribe(func(_ context.Context, e Event) {
+		if e.Type == Acquired {
+			clock = clock.Add(4 * time.Second)
+		}
+	})
+
+	require.NoError(t, a.Balance(ctx, now))
+	assert.Equal(t, []string{"1", "2"}, a.Owned(), "the rest is acquired once a third of the TTL is spent")
+	leases, err := store.List(ctx, marketKeyPrefix, now)
+	require.NoError(t, err)
+	assert.Equal(t, []Lease{
+		{Key: "market/1", Owner: "a", Expires: now.Add(15 * time.Second)},
+		{Key: "market/2", Owner: "a", Expires: now.Add(19 * time.Second)},
+	}, leases, "the lease expires in the TTL from its acquisition")
+
+	now = clock
+	require.NoError(t, a.Balance(ctx, now))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, a.Owned())
+}
@@ -0,0 +1,97 @@
+package livestate
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+)
+
+// MarketState is the live state of a market saved into the snapshot. The
+// candles and the ticker carry the position of their last applied deal
+// instead of a Kafka offset: the subscriber doesn't expose the offsets, so
+// the deals after the position are replayed from MongoDb, not from Kafka.
+type MarketState struct {
+	// Market is the market id.
+	Market  string                 `bson:"market"`
+	SavedAt time.Time              `bson:"savedAt"`
+	Candles *candle.MarketCandles  `bson:"candles,omitempty"`
+	Ticker  *consumer.MarketTicker `bson:"ticker,omitempty"`
+}
+
+// Snapshot keeps the last live state of the markets.
+type Snapshot interface {
+	Save(ctx context.Context, states []MarketState) error
+	// Load returns the saved states of the market ids, empty ids mean all of
+	// them.
+	Load(ctx context.Context, markets ...string) ([]MarketState, error)
+}
+
+type snapshotFile struct {
+	States []MarketState `bson:"states"`
+}
+
+type fileSnapshot struct {
+	path string
+}
+
+// NewFileSnapshot stores the snapshot as a BSON file at the path.
+func NewFileSnapshot(path string) Snapshot {
+	return &fileSnapshot{path: path}
+}
+
+// Save writes a temporary file and renames it, so the snapshot is never
+// partially written.
+func (s *fileSnapshot) Save(_ context.Context, states []MarketState) error {
+	data, err := bson.Marshal(snapshotFile{States: states})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileSnapshot) Load(_ context.Context, markets ...string) ([]MarketState, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f snapshotFile
+	if err = bson.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if len(markets) == 0 {
+		return f.States, nil
+	}
+	wanted := make(map[string]struct{}, len(markets))
+	for _, m := range markets {
+		wanted[m] = struct{}{}
+	}
+	states := f.States[:0]
+	for _, state := range f.States {
+		if _, ok := wanted[state.Market]; ok {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
@@ -0,0 +1,82 @@
+package livestate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+)
+
+func TestFileSnapshot(t *testing.T) {
+	ctx := context.Background()
+	savedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	snapshot := NewFileSnapshot(filepath.Join(t.TempDir(), "live_state.bson"))
+
+	states, err := snapshot.Load(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, states, "nothing is saved yet")
+
+	position := model.DealPosition{T: savedAt.Add(-time.Second), IDs: []string{"1", "2"}}
+	saved := []MarketState{
+		{
+			Market:  "1",
+			SavedAt: savedAt,
+			Candles: &candle.MarketCandles{
+				Candles: []domain.Candle{{
+					Symbol:     "1",
+					Resolution: model.Candle1MResolution,
+					Open:       model.MustParseDecimal("0.019"),
+					High:       model.MustParseDecimal("0.02"),
+					Low:        model.MustParseDecimal("0.019"),
+					Close:      model.MustParseDecimal("0.02"),
+					Volume:     model.MustParseDecimal("14.9"),
+					OpenTime:   savedAt.Truncate(time.Minute),
+					CloseTime:  savedAt.Truncate(time.Minute).Add(time.Minute),
+				}},
+				Position: position,
+			},
+		},
+		{
+			Market:  "2",
+			SavedAt: savedAt,
+			Ticker: &consumer.MarketTicker{
+				Ticker: domain.TickerState{
+					Symbol: "ETH_BTC",
+					Buckets: []*model.Kline{{
+						Symbol:   "ETH_BTC",
+						OpenTime: savedAt.Truncate(time.Minute),
+						Close:    model.MustParseDecimal("0.02"),
+						Trades:   1,
+					}},
+				},
+				Position: position,
+			},
+		},
+	}
+	require.NoError(t, snapshot.Save(ctx, saved))
+
+	states, err = snapshot.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	assert.True(t, savedAt.Equal(states[0].SavedAt))
+	require.NotNil(t, states[0].Candles)
+	assert.Equal(t, saved[0].Candles.Candles[0].Volume, states[0].Candles.Candles[0].Volume)
+	assert.Equal(t, position.IDs, states[0].Candles.Position.IDs)
+	assert.True(t, position.T.Equal(states[0].Candles.Position.T))
+	assert.Nil(t, states[0].Ticker)
+
+	states, err = snapshot.Load(ctx, "2")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.NotNil(t, states[0].Ticker)
+	assert.Equal(t, "ETH_BTC", states[0].Ticker.Ticker.Symbol)
+	assert.Equal(t, 1, states[0].Ticker.Ticker.Buckets[0].Trades)
+}
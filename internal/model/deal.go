@@ -1,15 +1,17 @@
 package model
 
 import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"regexp"
 )
 
-var (
-	marketRegex = regexp.MustCompile("[A-Z]{3,4}_[A-Z]{3,4}")
-	//	companyCreation, _ = time.Parse(time.RFC3339, "2018-01-01T00:00:00Z00:00")
-)
+// var companyCreation, _ = time.Parse(time.RFC3339, "2018-01-01T00:00:00Z00:00")
 
 type DealData struct {
 	Price        primitive.Decimal128 `json:"price"`
@@ -20,10 +22,94 @@ type DealData struct {
 }
 
 type Deal struct {
+	ID   primitive.ObjectID `json:"-" bson:"_id,omitempty"`
 	T    primitive.DateTime `json:"t" bson:"t"`
 	Data DealData           `json:"data"`
 }
 
+// QuoteQty returns the deal volume in the quote currency.
+func (d *Deal) QuoteQty() (primitive.Decimal128, error) {
+	return decimal128.Mul(d.Data.Price, d.Data.Volume)
+}
+
+// Cursor returns the position of the stored deal.
+func (d *Deal) Cursor() TradesCursor {
+	return TradesCursor{T: d.T.Time(), ID: d.ID}
+}
+
+// TradesCursor is a position in the stored deals ordered by the time and the
+// storage id, so the deals made in the same millisecond are ordered too.
+type TradesCursor struct {
+	T  time.Time
+	ID primitive.ObjectID
+}
+
+// String returns the opaque cursor for the API clients.
+func (c TradesCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(strconv.FormatInt(c.T.UnixMilli(), 10) + "." + c.ID.Hex()),
+	)
+}
+
+func ParseTradesCursor(s string) (TradesCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TradesCursor{}, errors.Errorf("invalid cursor %q", s)
+	}
+	parts := strings.SplitN(string(b), ".", 2)
+	if len(parts) != 2 {
+		return TradesCursor{}, errors.Errorf("invalid cursor %q", s)
+	}
+	t, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return TradesCursor{}, errors.Errorf("invalid cursor %q", s)
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return TradesCursor{}, errors.Errorf("invalid cursor %q", s)
+	}
+	return TradesCursor{T: time.UnixMilli(t).UTC(), ID: id}, nil
+}
+
+// TradesQuery selects the deals of a market oldest first. If neither From,
+// After nor StartTime are set, the latest deals are selected.
+type TradesQuery struct {
+	Market string
+	// From is the position of the first deal, After is the position right
+	// before it, at most one of them is set.
+	From  *TradesCursor
+	After *TradesCursor
+	// StartTime and EndTime are inclusive, zero means unbounded.
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int64
+}
+
+// Latest reports if the query selects the latest deals.
+func (q TradesQuery) Latest() bool {
+	return q.From == nil && q.After == nil && q.StartTime.IsZero()
+}
+
+// DealsGap is a period which deals can't be delivered to a subscriber.
+type DealsGap struct {
+	From   time.Time
+	To     time.Time
+	Reason string
+	// Dropped is a number of lost deals, zero if unknown.
+	Dropped uint64
+}
+
+// DealsResume is a point to resume a deals stream from: the last received
+// deal id or, if the id is unknown, the time of the last received deal.
+type DealsResume struct {
+	DealId string
+	Since  time.Time
+}
+
+func (r DealsResume) IsZero() bool {
+	return r.DealId == "" && r.Since.IsZero()
+}
+
 type DealRaw struct {
 	Time         primitive.DateTime   `json:"time"`
 	Price        primitive.Decimal128 `json:"price"`
@@ -46,12 +132,9 @@ func (d *Deal) Validate() error {
 		return errors.Errorf("volume value is illegal: %v", d.Data.Volume)
 	}
 
-	if !marketRegex.MatchString(d.Data.Market) {
-		return errors.Errorf(
-			"invalid market %s, must be matches by: %s",
-			d.Data.Market,
-			marketRegex.String(),
-		)
+	// the market is validated by the market registry
+	if d.Data.Market == "" {
+		return errors.New("market is empty")
 	}
 	/*
 		if d.T.Before(companyCreation) {
@@ -68,3 +151,40 @@ func MustParseDecimal(num string) primitive.Decimal128 {
 		return d
 	}
 }
+
+// DealPosition is the position of the last applied deal of a market. The
+// deals are stored with a millisecond precision, so the ids of the deals
+// applied in the last millisecond are kept too.
+type DealPosition struct {
+	T   time.Time `bson:"t"`
+	IDs []string  `bson:"ids"`
+}
+
+// Covers tells if the deal made at t is at or before the position.
+func (p DealPosition) Covers(t time.Time, id string) bool {
+	t = t.Truncate(time.Millisecond)
+	if t.Before(p.T) {
+		return true
+	}
+	if t.After(p.T) {
+		return false
+	}
+	for _, applied := range p.IDs {
+		if applied == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the position to the deal made at t unless it's older.
+func (p *DealPosition) Advance(t time.Time, id string) {
+	t = t.Truncate(time.Millisecond)
+	switch {
+	case t.After(p.T):
+		p.T = t
+		p.IDs = []string{id}
+	case t.Equal(p.T):
+		p.IDs = append(p.IDs, id)
+	}
+}
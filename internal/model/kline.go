@@ -1,6 +1,7 @@
 package model
 
 import (
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
 )
@@ -20,4 +21,18 @@ type Kline struct {
 	Symbol      string               `bson:"symbol"`
 	First       time.Time            `bson:"first"`
 	Last        time.Time            `bson:"last"`
+	FirstId     string               `bson:"firstId"`
+	LastId      string               `bson:"lastId"`
+	LastQty     primitive.Decimal128 `bson:"lastQty"`
+}
+
+// TakerBuyAssets returns the volume bought by the takers, TakerAssets is the
+// volume of the deals whose buyer is the maker, so the taker sold it.
+func (k *Kline) TakerBuyAssets() (primitive.Decimal128, error) {
+	return decimal128.Sub(k.Volume, k.TakerAssets)
+}
+
+// TakerBuyQuotes returns the quote volume of the deals bought by the takers.
+func (k *Kline) TakerBuyQuotes() (primitive.Decimal128, error) {
+	return decimal128.Sub(k.Quotes, k.TakerQuotes)
 }
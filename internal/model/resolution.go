@@ -68,6 +68,38 @@ func GetAvailableResolutions() []Resolution {
 	}
 }
 
+// Canonical returns the resolution which the legacy alias stands for, the
+// other resolutions are canonical.
+func (resolution Resolution) Canonical() Resolution {
+	switch resolution {
+	case Candle1H2Resolution:
+		return Candle1HResolution
+	case Candle2H2Resolution:
+		return Candle2HResolution
+	case Candle4H2Resolution:
+		return Candle4HResolution
+	case Candle6H2Resolution:
+		return Candle6HResolution
+	case Candle12H2Resolution:
+		return Candle12HResolution
+	case Candle1MH2Resolution:
+		return Candle1MHResolution
+	}
+	return resolution
+}
+
+// Aliases returns the canonical resolution with its legacy aliases.
+func (resolution Resolution) Aliases() []Resolution {
+	canonical := resolution.Canonical()
+	aliases := []Resolution{canonical}
+	for _, r := range GetAvailableResolutions() {
+		if r != canonical && r.Canonical() == canonical {
+			aliases = append(aliases, r)
+		}
+	}
+	return aliases
+}
+
 func CalculateCloseTime(openTime time.Time, resolution Resolution) time.Time {
 	duration := resolution.ToDuration(openTime.Month(), openTime.Year())
 
@@ -184,9 +216,11 @@ func GetResolution(interval Resolution) (string, int) {
 	case Candle15MResolution:
 		return MinuteUnit, 15
 	case Candle30MResolution:
-		return MinuteUnit, 3
+		return MinuteUnit, 30
 	case Candle1HResolution:
 		return HourUnit, 1
+	case Candle1H2Resolution:
+		return HourUnit, 1
 	case Candle2HResolution:
 		return HourUnit, 2
 	case Candle2H2Resolution:
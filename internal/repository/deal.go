@@ -8,8 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"bitbucket.org/novatechnologies/ohlcv/client/market"
-
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"bitbucket.org/novatechnologies/common/infra/logger"
@@ -19,26 +17,18 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 )
 
 type Deal struct {
 	DbCollection *mongo.Collection
-	MarketsMap   map[string]string
-	markets      []string
-	marketsInfo  []market.Market
+	markets      *domain.MarketRegistry
 }
 
-func NewDeal(dbCollection *mongo.Collection, marketsMap map[string]string, marketsInfo []market.Market) *Deal {
-	markets := make([]string, 0, len(marketsMap))
-
-	for _, m := range marketsMap {
-		markets = append(markets, m)
-	}
-
+func NewDeal(dbCollection *mongo.Collection, markets *domain.MarketRegistry) *Deal {
 	return &Deal{
 		DbCollection: dbCollection,
-		MarketsMap:   marketsMap,
-		marketsInfo:  marketsInfo,
 		markets:      markets,
 	}
 }
@@ -96,7 +86,161 @@ func (s *Deal) GetLastTrades(ctx context.Context, symbol string, limit int32) ([
 	return deals, nil
 }
 
+// GetDealByID returns nil if there is no deal with the id.
+func (s *Deal) GetDealByID(ctx context.Context, id string) (*model.Deal, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFunc()
+
+	var deal model.Deal
+	err := s.DbCollection.FindOne(ctx, bson.M{"data.dealid": id}).Decode(&deal)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetDealByID: FindOne error '%w'", err)
+	}
+	return &deal, nil
+}
+
+// GetDealsSince returns up to limit deals made at or after from, oldest first.
+// Empty markets mean all markets.
+func (s *Deal) GetDealsSince(ctx context.Context, markets []string, from time.Time, limit int64) ([]*model.Deal, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFunc()
+
+	filter := bson.M{"t": bson.M{"$gte": primitive.NewDateTimeFromTime(from)}}
+	if len(markets) > 0 {
+		filter["data.market"] = bson.M{"$in": markets}
+	}
+	cursor, err := s.DbCollection.Find(
+		ctx,
+		filter,
+		options.Find().
+			SetLimit(limit).
+			SetSort(bson.D{{"t", 1}, {"_id", 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("GetDealsSince: Find error '%w'", err)
+	}
+	var deals []*model.Deal
+	if err = cursor.All(ctx, &deals); err != nil {
+		return nil, fmt.Errorf("GetDealsSince: cursor.All error '%w'", err)
+	}
+	return deals, nil
+}
+
+// GetTrades returns the deals selected by the query oldest first.
+func (s *Deal) GetTrades(ctx context.Context, q model.TradesQuery) ([]*model.Deal, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFunc()
+
+	filter := bson.D{{"data.market", q.Market}}
+	period := bson.D{}
+	if !q.StartTime.IsZero() {
+		period = append(period, bson.E{Key: "$gte", Value: primitive.NewDateTimeFromTime(q.StartTime)})
+	}
+	if !q.EndTime.IsZero() {
+		period = append(period, bson.E{Key: "$lte", Value: primitive.NewDateTimeFromTime(q.EndTime)})
+	}
+	if len(period) > 0 {
+		filter = append(filter, bson.E{Key: "t", Value: period})
+	}
+	position, op := q.From, "$gte"
+	if q.After != nil {
+		position, op = q.After, "$gt"
+	}
+	if position != nil {
+		t := primitive.NewDateTimeFromTime(position.T)
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{"t", bson.D{{"$gt", t}}}},
+			bson.D{{"t", t}, {"_id", bson.D{{op, position.ID}}}},
+		}})
+	}
+	order := 1
+	if q.Latest() {
+		order = -1
+	}
+	cursor, err := s.DbCollection.Find(
+		ctx,
+		filter,
+		options.Find().
+			SetLimit(q.Limit).
+			SetSort(bson.D{{"t", order}, {"_id", order}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("GetTrades: Find error '%w'", err)
+	}
+	deals := make([]*model.Deal, 0)
+	if err = cursor.All(ctx, &deals); err != nil {
+		return nil, fmt.Errorf("GetTrades: cursor.All error '%w'", err)
+	}
+	if q.Latest() {
+		for i, j := 0, len(deals)-1; i < j; i, j = i+1, j-1 {
+			deals[i], deals[j] = deals[j], deals[i]
+		}
+	}
+	return deals, nil
+}
+
+// GetLastDealBefore returns nil if the market has no deals before t.
+func (s *Deal) GetLastDealBefore(ctx context.Context, market string, t time.Time) (*model.Deal, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFunc()
+
+	var deal model.Deal
+	err := s.DbCollection.FindOne(
+		ctx,
+		bson.M{"data.market": market, "t": bson.M{"$lt": primitive.NewDateTimeFromTime(t)}},
+		options.FindOne().SetSort(bson.D{{"t", -1}}),
+	).Decode(&deal)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetLastDealBefore: FindOne error '%w'", err)
+	}
+	return &deal, nil
+}
+
+// GetLastPosition returns the position of the last stored deal of the market
+// with the ids of the deals stored in its millisecond, zero if there is none.
+func (s *Deal) GetLastPosition(ctx context.Context, market string) (model.DealPosition, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFunc()
+
+	var last model.Deal
+	err := s.DbCollection.FindOne(
+		ctx,
+		bson.M{"data.market": market},
+		options.FindOne().SetSort(bson.D{{"t", -1}}),
+	).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return model.DealPosition{}, nil
+	}
+	if err != nil {
+		return model.DealPosition{}, fmt.Errorf("GetLastPosition: FindOne error '%w'", err)
+	}
+	cursor, err := s.DbCollection.Find(
+		ctx,
+		bson.M{"data.market": market, "t": last.T},
+		options.Find().SetProjection(bson.M{"data.dealid": 1}),
+	)
+	if err != nil {
+		return model.DealPosition{}, fmt.Errorf("GetLastPosition: Find error '%w'", err)
+	}
+	var deals []*model.Deal
+	if err = cursor.All(ctx, &deals); err != nil {
+		return model.DealPosition{}, fmt.Errorf("GetLastPosition: cursor.All error '%w'", err)
+	}
+	position := model.DealPosition{T: last.T.Time().UTC()}
+	for _, deal := range deals {
+		position.IDs = append(position.IDs, deal.Data.DealId)
+	}
+	return position, nil
+}
+
 func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string) ([]*domain.TickerPriceChangeStatistics, error) {
+	defer metrics.ObserveSince(metrics.MongoAggregationDuration.WithLabelValues("GetTickerPriceChangeStatistics"), time.Now())
 	fromTime := primitive.NewDateTimeFromTime(time.Now().Add(-24 * time.Hour))
 
 	markets := []interface{}{market}
@@ -104,7 +248,7 @@ func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string
 	if market == "" {
 		markets = []interface{}{}
 
-		for _, m := range s.markets {
+		for _, m := range s.markets.Names() {
 			markets = append(markets, m)
 		}
 	}
@@ -180,12 +324,12 @@ func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string
 
 	statistics := make([]*domain.TickerPriceChangeStatistics, 0, len(resp))
 	for _, v := range resp {
-		statistics = append(statistics, parseStatistics(v))
+		statistics = append(statistics, parseStatistics(v, s.markets.Precision(v["_id"].(string))))
 	}
 	return statistics, nil
 }
 
-func parseStatistics(m bson.M) *domain.TickerPriceChangeStatistics {
+func parseStatistics(m bson.M, precision domain.MarketPrecision) *domain.TickerPriceChangeStatistics {
 	closePrice := m["closePrice"].(primitive.Decimal128)
 	openPrice := m["openPrice"].(primitive.Decimal128)
 	quoteVolume := m["quoteVolume"].(primitive.Decimal128)
@@ -193,21 +337,21 @@ func parseStatistics(m bson.M) *domain.TickerPriceChangeStatistics {
 	priceChange, priceChangePercent := calcChange(closePrice, openPrice)
 	return &domain.TickerPriceChangeStatistics{
 		Symbol:             m["_id"].(string),
-		WeightedAvgPrice:   calcVwap(quoteVolume, volume),
+		WeightedAvgPrice:   calcVwap(quoteVolume, volume, precision),
 		LastPrice:          closePrice.String(),
 		OpenPrice:          openPrice.String(),
 		HighPrice:          m["highPrice"].(primitive.Decimal128).String(),
 		LowPrice:           m["lowPrice"].(primitive.Decimal128).String(),
 		Volume:             volume.String(),
-		QuoteVolume:        quoteVolume.String(),
+		QuoteVolume:        precision.FormatQuote(quoteVolume),
 		OpenTime:           m["openTime"].(primitive.DateTime).Time().UnixMilli(),
 		CloseTime:          m["closeTime"].(primitive.DateTime).Time().UnixMilli(),
 		FirstId:            m["firstId"].(string),
 		LastId:             m["lastId"].(string),
 		LastQty:            m["lastQty"].(primitive.Decimal128).String(),
 		Count:              int(m["count"].(int32)),
-		PriceChange:        strconv.FormatFloat(priceChange, 'f', 8, 64),
-		PriceChangePercent: strconv.FormatFloat(priceChangePercent, 'f', 8, 64),
+		PriceChange:        priceChange,
+		PriceChangePercent: priceChangePercent,
 		PrevClosePrice:     parsePrevClosePrice(m["prev_window_trade"]),
 	}
 }
@@ -244,31 +388,25 @@ func parsePrevClosePrice(i interface{}) string {
 	return ""
 }
 
-func calcVwap(quoteVolume, volume primitive.Decimal128) string {
-	quoteVolumeF, err := strconv.ParseFloat(quoteVolume.String(), 64)
-	if err != nil {
-		return ""
-	}
-	volumeF, err := strconv.ParseFloat(volume.String(), 64)
+func calcVwap(quoteVolume, volume primitive.Decimal128, precision domain.MarketPrecision) string {
+	vwap, err := decimal128.Div(quoteVolume, volume, precision.Price, decimal128.RoundHalfUp)
 	if err != nil {
 		return ""
 	}
-	vwap := quoteVolumeF / volumeF
-	return strconv.FormatFloat(vwap, 'f', 4, 64)
+	return precision.FormatPrice(vwap)
 }
 
-func calcChange(closePrice, openPrice primitive.Decimal128) (float64, float64) {
-	closePriceF, err := strconv.ParseFloat(closePrice.String(), 64)
+// calcChange returns the price change and its ratio to the open price.
+func calcChange(closePrice, openPrice primitive.Decimal128) (string, string) {
+	change, err := decimal128.Sub(closePrice, openPrice)
 	if err != nil {
-		return 0, 0
+		return "", ""
 	}
-	openPriceF, err := strconv.ParseFloat(openPrice.String(), 64)
+	percent, err := decimal128.Div(change, openPrice, 8, decimal128.RoundHalfUp)
 	if err != nil {
-		return 0, 0
+		percent = decimal128.Zero
 	}
-	change := closePriceF - openPriceF
-	priceChangePercent := change / openPriceF
-	return change, priceChangePercent
+	return decimal128.StringFixed(change, 8), decimal128.StringFixed(percent, 8)
 }
 
 func (s *Deal) GetAvgPrice(ctx context.Context, duration time.Duration, market string) (string, error) {
@@ -312,23 +450,9 @@ func (s *Deal) GetAvgPrice(ctx context.Context, duration time.Duration, market s
 	return s.roundByMarket(resp[0]["avg"].(primitive.Decimal128), market)
 }
 
-func (s *Deal) roundByMarket(decimal128 primitive.Decimal128, market string) (string, error) {
-	f, err := strconv.ParseFloat(decimal128.String(), 64)
-	if err != nil {
+func (s *Deal) roundByMarket(d primitive.Decimal128, market string) (string, error) {
+	if _, err := decimal128.Sign(d); err != nil {
 		return "0", nil
 	}
-	return strconv.FormatFloat(
-		f,
-		'f',
-		findPrec(market, s.marketsInfo),
-		64), nil
-}
-
-func findPrec(market string, info []market.Market) int {
-	for _, i := range info {
-		if i.Name == market {
-			return int(i.BasePrecision)
-		}
-	}
-	return 4
+	return s.markets.Precision(market).FormatPrice(d), nil
 }
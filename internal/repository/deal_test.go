@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,3 +43,111 @@ func TestService_GetLastTrades(t *testing.T) {
 		},
 	)
 }
+
+func TestDeal_GetTrades(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	deal := func(id string, t time.Time) bson.D {
+		return bson.D{
+			{"_id", primitive.NewObjectID()},
+			{"t", primitive.NewDateTimeFromTime(t)},
+			{"data", bson.D{{"dealid", id}, {"market", "ETH_BTC"}}},
+		}
+	}
+	mt.Run("latest are reversed", func(mt *mtest.T) {
+		s := Deal{DbCollection: mt.Coll}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+				deal("2", start.Add(time.Second)),
+				deal("1", start),
+			),
+		)
+
+		trades, err := s.GetTrades(context.Background(), model.TradesQuery{Market: "ETH_BTC", Limit: 2})
+		require.NoError(mt, err)
+		require.Len(mt, trades, 2)
+		assert.Equal(mt, "1", trades[0].Data.DealId)
+		assert.Equal(mt, "2", trades[1].Data.DealId)
+
+		sort := mt.GetStartedEvent().Command.Lookup("sort").Document()
+		assert.Equal(mt, int32(-1), sort.Lookup("t").Int32())
+	})
+	mt.Run("after cursor", func(mt *mtest.T) {
+		s := Deal{DbCollection: mt.Coll}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, deal("3", start)),
+		)
+
+		after := model.TradesCursor{T: start, ID: primitive.NewObjectID()}
+		trades, err := s.GetTrades(context.Background(), model.TradesQuery{
+			Market: "ETH_BTC",
+			After:  &after,
+			Limit:  10,
+		})
+		require.NoError(mt, err)
+		require.Len(mt, trades, 1)
+		assert.False(mt, trades[0].ID.IsZero())
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(mt, int32(1), cmd.Lookup("sort").Document().Lookup("t").Int32())
+		or := cmd.Lookup("filter").Document().Lookup("$or").Array()
+		values, err := or.Values()
+		require.NoError(mt, err)
+		require.Len(mt, values, 2)
+		sameTime := values[1].Document()
+		assert.Equal(mt, after.ID, sameTime.Lookup("_id", "$gt").ObjectID())
+	})
+}
+
+func TestTradesCursor(t *testing.T) {
+	c := model.TradesCursor{T: time.UnixMilli(1651399200123).UTC(), ID: primitive.NewObjectID()}
+	parsed, err := model.ParseTradesCursor(c.String())
+	require.NoError(t, err)
+	assert.Equal(t, c, parsed)
+
+	for _, s := range []string{"", "abc", "MTIz", c.String() + "x"} {
+		_, err = model.ParseTradesCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDeal_GetLastPosition(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	last := time.Date(2022, 5, 1, 10, 0, 0, 123000000, time.UTC)
+	deal := func(id string) bson.D {
+		return bson.D{
+			{"_id", primitive.NewObjectID()},
+			{"t", primitive.NewDateTimeFromTime(last)},
+			{"data", bson.D{{"dealid", id}, {"market", "ETH_BTC"}}},
+		}
+	}
+	mt.Run("ids of the last millisecond", func(mt *mtest.T) {
+		s := Deal{DbCollection: mt.Coll}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, deal("2")),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, deal("1"), deal("2")),
+		)
+
+		position, err := s.GetLastPosition(context.Background(), "ETH_BTC")
+		require.NoError(mt, err)
+		assert.Equal(mt, model.DealPosition{T: last, IDs: []string{"1", "2"}}, position)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(mt, int32(-1), cmd.Lookup("sort", "t").Int32())
+		cmd = mt.GetStartedEvent().Command
+		assert.Equal(mt, last, cmd.Lookup("filter", "t").Time().UTC())
+		assert.Equal(mt, "ETH_BTC", cmd.Lookup("filter", "data.market").StringValue())
+	})
+	mt.Run("no deals", func(mt *mtest.T) {
+		s := Deal{DbCollection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		position, err := s.GetLastPosition(context.Background(), "ETH_BTC")
+		require.NoError(mt, err)
+		assert.Equal(mt, model.DealPosition{}, position)
+	})
+}
@@ -22,14 +22,56 @@ func NewKline(dealsDbCollection *mongo.Collection) *Kline {
 
 // Get klines according parameters
 func (r *Kline) Get(ctx context.Context, from, to time.Time) ([]*model.Kline, error) {
-	matchStage := bson.D{
-		{"$match", bson.D{
-			{"t", bson.D{
-				{"$gte", primitive.NewDateTimeFromTime(from)},
-				{"$lte", primitive.NewDateTimeFromTime(to)},
-			}},
+	return r.get(ctx, bson.D{
+		{"t", bson.D{
+			{"$gte", primitive.NewDateTimeFromTime(from)},
+			{"$lte", primitive.NewDateTimeFromTime(to)},
+		}},
+	}, model.MinuteUnit, 1, 0)
+}
+
+// GetByMarkets returns minute klines of the markets made in [from, to), all
+// markets if the list is empty.
+func (r *Kline) GetByMarkets(ctx context.Context, markets []string, from, to time.Time) ([]*model.Kline, error) {
+	match := bson.D{
+		{"t", bson.D{
+			{"$gte", primitive.NewDateTimeFromTime(from)},
+			{"$lt", primitive.NewDateTimeFromTime(to)},
 		}},
 	}
+	if len(markets) > 0 {
+		match = append(match, bson.E{Key: "data.market", Value: bson.D{{"$in", markets}}})
+	}
+	return r.get(ctx, match, model.MinuteUnit, 1, 0)
+}
+
+// GetByResolution returns up to limit first klines of the market at the
+// resolution made in [from, to) ordered by the open time.
+func (r *Kline) GetByResolution(
+	ctx context.Context,
+	market string,
+	resolution model.Resolution,
+	from, to time.Time,
+	limit int64,
+) ([]*model.Kline, error) {
+	unit, binSize := model.GetResolution(resolution)
+	if unit == "" {
+		return nil, fmt.Errorf("unsupported resolution %s", resolution)
+	}
+	return r.get(ctx, bson.D{
+		{"t", bson.D{
+			{"$gte", primitive.NewDateTimeFromTime(from)},
+			{"$lt", primitive.NewDateTimeFromTime(to)},
+		}},
+		{"data.market", market},
+	}, unit, binSize, limit)
+}
+
+// get groups the matched deals by markets into klines of binSize units, the
+// weeks start on Monday. The klines are ordered by the open time only if
+// limited.
+func (r *Kline) get(ctx context.Context, match bson.D, unit string, binSize int, limit int64) ([]*model.Kline, error) {
+	matchStage := bson.D{{"$match", match}}
 
 	firstSortStage := bson.D{{"$sort", bson.D{
 		{
@@ -46,13 +88,17 @@ func (r *Kline) Get(ctx context.Context, from, to time.Time) ([]*model.Kline, er
 			{"openTime", bson.D{
 				{"$dateTrunc", bson.D{
 					{"date", "$t"},
-					{"unit", model.MinuteUnit},
-					{"binSize", 1},
+					{"unit", unit},
+					{"binSize", binSize},
+					{"startOfWeek", "monday"},
 				}},
 			}},
 		}},
 		{"first", bson.D{{"$last", "$t"}}},
 		{"last", bson.D{{"$first", "$t"}}},
+		{"firstId", bson.D{{"$last", "$data.dealid"}}},
+		{"lastId", bson.D{{"$first", "$data.dealid"}}},
+		{"lastQty", bson.D{{"$first", "$data.volume"}}},
 		{"open", bson.D{{"$last", "$data.price"}}},
 		{"high", bson.D{{"$max", "$data.price"}}},
 		{"low", bson.D{{"$min", "$data.price"}}},
@@ -90,12 +136,15 @@ func (r *Kline) Get(ctx context.Context, from, to time.Time) ([]*model.Kline, er
 			{"closeTime", bson.D{{
 				"$dateAdd", bson.D{
 					{"startDate", "$_id.openTime"},
-					{"unit", model.MinuteUnit},
-					{"amount", 1},
+					{"unit", unit},
+					{"amount", binSize},
 				},
 			}}},
 			{"first", "$first"},
 			{"last", "$last"},
+			{"firstId", "$firstId"},
+			{"lastId", "$lastId"},
+			{"lastQty", bson.D{{"$toDecimal", "$lastQty"}}},
 			{"symbol", "$_id.symbol"},
 			{"open", bson.D{{"$toDecimal", "$open"}}},
 			{"high", bson.D{{"$toDecimal", "$high"}}},
@@ -109,14 +158,18 @@ func (r *Kline) Get(ctx context.Context, from, to time.Time) ([]*model.Kline, er
 		}},
 	}
 
+	pipeline := mongo.Pipeline{matchStage, firstSortStage, firstGroupStage, projectStage}
+	if limit > 0 {
+		pipeline = append(pipeline,
+			bson.D{{"$sort", bson.D{{"openTime", 1}}}},
+			bson.D{{"$limit", limit}},
+		)
+	}
+
 	opts := options.Aggregate()
 	adu := true
 	opts.AllowDiskUse = &adu
-	cursor, err := r.dealsDbCollection.Aggregate(
-		ctx,
-		mongo.Pipeline{matchStage, firstSortStage, firstGroupStage, projectStage},
-		opts,
-	)
+	cursor, err := r.dealsDbCollection.Aggregate(ctx, pipeline, opts)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed apply a kline aggregation function on the collection. %w", err)
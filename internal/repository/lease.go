@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/lease"
+)
+
+// Lease keeps the leases in the documents by keys, it implements
+// lease.Store.
+type Lease struct {
+	collection *mongo.Collection
+}
+
+var _ lease.Store = new(Lease)
+
+func NewLease(collection *mongo.Collection) *Lease {
+	return &Lease{collection: collection}
+}
+
+type leaseDocument struct {
+	Key     string    `bson:"_id"`
+	Owner   string    `bson:"owner"`
+	Expires time.Time `bson:"expires"`
+}
+
+// Acquire updates the lease held by the owner or expired, the lease of
+// another owner fails the upsert with the duplicate key.
+func (r *Lease) Acquire(ctx context.Context, key, owner string, now, expires time.Time) (bool, error) {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": key,
+			"$or": bson.A{
+				bson.M{"owner": owner},
+				bson.M{"expires": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{"$set": bson.M{"owner": owner, "expires": expires}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Lease.Acquire: UpdateOne error '%w'", err)
+	}
+	return true, nil
+}
+
+func (r *Lease) Release(ctx context.Context, key, owner string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key, "owner": owner})
+	if err != nil {
+		return fmt.Errorf("Lease.Release: DeleteOne error '%w'", err)
+	}
+	return nil
+}
+
+func (r *Lease) List(ctx context.Context, prefix string, now time.Time) ([]lease.Lease, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"_id":     bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+		"expires": bson.M{"$gt": now},
+	}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("Lease.List: Find error '%w'", err)
+	}
+	var docs []leaseDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("Lease.List: cursor error '%w'", err)
+	}
+	leases := make([]lease.Lease, 0, len(docs))
+	for _, d := range docs {
+		leases = append(leases, lease.Lease{Key: d.Key, Owner: d.Owner, Expires: d.Expires})
+	}
+	return leases, nil
+}
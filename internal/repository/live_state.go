@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/livestate"
+)
+
+// LiveState stores the live state of every market in its own document, it
+// implements livestate.Snapshot.
+type LiveState struct {
+	collection *mongo.Collection
+}
+
+var _ livestate.Snapshot = new(LiveState)
+
+func NewLiveState(collection *mongo.Collection) *LiveState {
+	return &LiveState{collection: collection}
+}
+
+type liveStateDocument struct {
+	Market  string                 `bson:"_id"`
+	SavedAt time.Time              `bson:"savedAt"`
+	Candles *candle.MarketCandles  `bson:"candles,omitempty"`
+	Ticker  *consumer.MarketTicker `bson:"ticker,omitempty"`
+}
+
+func (r *LiveState) Save(ctx context.Context, states []livestate.MarketState) error {
+	models := make([]mongo.WriteModel, 0, len(states))
+	for _, s := range states {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{"_id", s.Market}}).
+			SetReplacement(liveStateDocument{Market: s.Market, SavedAt: s.SavedAt, Candles: s.Candles, Ticker: s.Ticker}).
+			SetUpsert(true),
+		)
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("LiveState.Save: BulkWrite error '%w'", err)
+	}
+	return nil
+}
+
+func (r *LiveState) Load(ctx context.Context, markets ...string) ([]livestate.MarketState, error) {
+	filter := bson.D{}
+	if len(markets) > 0 {
+		filter = bson.D{{"_id", bson.D{{"$in", markets}}}}
+	}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("LiveState.Load: Find error '%w'", err)
+	}
+	var docs []liveStateDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("LiveState.Load: cursor error '%w'", err)
+	}
+	states := make([]livestate.MarketState, 0, len(docs))
+	for _, d := range docs {
+		states = append(states, livestate.MarketState{Market: d.Market, SavedAt: d.SavedAt, Candles: d.Candles, Ticker: d.Ticker})
+	}
+	return states, nil
+}
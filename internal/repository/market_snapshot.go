@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const marketSnapshotID = "markets"
+
+// MarketSnapshot stores the last good market list in a single document, it
+// implements market.Snapshot.
+type MarketSnapshot struct {
+	collection *mongo.Collection
+}
+
+func NewMarketSnapshot(collection *mongo.Collection) *MarketSnapshot {
+	return &MarketSnapshot{collection: collection}
+}
+
+type marketSnapshot struct {
+	SavedAt time.Time       `bson:"savedAt"`
+	Markets []market.Market `bson:"markets"`
+}
+
+func (r *MarketSnapshot) Save(ctx context.Context, markets []market.Market, at time.Time) error {
+	_, err := r.collection.ReplaceOne(
+		ctx,
+		bson.D{{"_id", marketSnapshotID}},
+		marketSnapshot{SavedAt: at, Markets: markets},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("MarketSnapshot.Save: ReplaceOne error '%w'", err)
+	}
+	return nil
+}
+
+func (r *MarketSnapshot) Load(ctx context.Context) ([]market.Market, time.Time, error) {
+	var snapshot marketSnapshot
+	err := r.collection.FindOne(ctx, bson.D{{"_id", marketSnapshotID}}).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("MarketSnapshot.Load: FindOne error '%w'", err)
+	}
+	return snapshot.Markets, snapshot.SavedAt, nil
+}
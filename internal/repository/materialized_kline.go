@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const klineWatermarkID = "klines"
+
+// MaterializedKline stores klines of the closed minutes and hours, klines of a
+// unit are unique by symbol and open time.
+type MaterializedKline struct {
+	collections map[string]*mongo.Collection
+	state       *mongo.Collection
+}
+
+func NewMaterializedKline(minutes, hours, state *mongo.Collection) *MaterializedKline {
+	return &MaterializedKline{
+		collections: map[string]*mongo.Collection{
+			model.MinuteUnit: minutes,
+			model.HourUnit:   hours,
+		},
+		state: state,
+	}
+}
+
+func (r *MaterializedKline) collection(unit string) (*mongo.Collection, error) {
+	c, ok := r.collections[unit]
+	if !ok {
+		return nil, fmt.Errorf("no klines collection for unit %s", unit)
+	}
+	return c, nil
+}
+
+// Save replaces the stored klines with the same symbol and open time.
+func (r *MaterializedKline) Save(ctx context.Context, unit string, klines []*model.Kline) error {
+	if len(klines) == 0 {
+		return nil
+	}
+	c, err := r.collection(unit)
+	if err != nil {
+		return err
+	}
+	models := make([]mongo.WriteModel, len(klines))
+	for i, k := range klines {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{"symbol", k.Symbol}, {"openTime", k.OpenTime}}).
+			SetReplacement(k).
+			SetUpsert(true)
+	}
+	if _, err := c.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("MaterializedKline.Save: BulkWrite error '%w'", err)
+	}
+	return nil
+}
+
+// Find returns klines of the markets opened in [from, to) sorted by symbol and
+// open time, all markets if the list is empty.
+func (r *MaterializedKline) Find(ctx context.Context, unit string, markets []string, from, to time.Time) ([]*model.Kline, error) {
+	c, err := r.collection(unit)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.D{{"openTime", bson.D{{"$gte", from}, {"$lt", to}}}}
+	if len(markets) > 0 {
+		filter = append(filter, bson.E{Key: "symbol", Value: bson.D{{"$in", markets}}})
+	}
+	cursor, err := c.Find(ctx, filter, options.Find().SetSort(bson.D{{"symbol", 1}, {"openTime", 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("MaterializedKline.Find: Find error '%w'", err)
+	}
+	klines := make([]*model.Kline, 0)
+	if err = cursor.All(ctx, &klines); err != nil {
+		return nil, fmt.Errorf("MaterializedKline.Find: cursor.All error '%w'", err)
+	}
+	return klines, nil
+}
+
+// RollupHours replaces the hour klines opened in [from, to) with the ones
+// merged from the stored minute klines, so the hours should be closed.
+func (r *MaterializedKline) RollupHours(ctx context.Context, from, to time.Time) error {
+	minutes, hours := r.collections[model.MinuteUnit], r.collections[model.HourUnit]
+	opts := options.Aggregate()
+	adu := true
+	opts.AllowDiskUse = &adu
+	cursor, err := minutes.Aggregate(ctx, mongo.Pipeline{
+		{{"$match", bson.D{{"openTime", bson.D{{"$gte", from}, {"$lt", to}}}}}},
+		{{"$sort", bson.D{{"symbol", 1}, {"openTime", 1}}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{
+				{"symbol", "$symbol"},
+				{"openTime", bson.D{
+					{"$dateTrunc", bson.D{
+						{"date", "$openTime"},
+						{"unit", model.HourUnit},
+						{"binSize", 1},
+					}},
+				}},
+			}},
+			{"first", bson.D{{"$first", "$first"}}},
+			{"last", bson.D{{"$last", "$last"}}},
+			{"firstId", bson.D{{"$first", "$firstId"}}},
+			{"lastId", bson.D{{"$last", "$lastId"}}},
+			{"lastQty", bson.D{{"$last", "$lastQty"}}},
+			{"open", bson.D{{"$first", "$open"}}},
+			{"high", bson.D{{"$max", "$high"}}},
+			{"low", bson.D{{"$min", "$low"}}},
+			{"close", bson.D{{"$last", "$close"}}},
+			{"volume", bson.D{{"$sum", "$volume"}}},
+			{"quotes", bson.D{{"$sum", "$quotes"}}},
+			{"trades", bson.D{{"$sum", "$trades"}}},
+			{"takerAssets", bson.D{{"$sum", "$takerAssets"}}},
+			{"takerQuotes", bson.D{{"$sum", "$takerQuotes"}}},
+		}}},
+		{{"$project", bson.D{
+			{"_id", 0},
+			{"symbol", "$_id.symbol"},
+			{"openTime", "$_id.openTime"},
+			{"closeTime", bson.D{{
+				"$dateAdd", bson.D{
+					{"startDate", "$_id.openTime"},
+					{"unit", model.HourUnit},
+					{"amount", 1},
+				},
+			}}},
+			{"first", "$first"},
+			{"last", "$last"},
+			{"firstId", "$firstId"},
+			{"lastId", "$lastId"},
+			{"lastQty", "$lastQty"},
+			{"open", "$open"},
+			{"high", "$high"},
+			{"low", "$low"},
+			{"close", "$close"},
+			{"volume", "$volume"},
+			{"quotes", "$quotes"},
+			{"trades", "$trades"},
+			{"takerAssets", "$takerAssets"},
+			{"takerQuotes", "$takerQuotes"},
+		}}},
+		{{"$merge", bson.D{
+			{"into", hours.Name()},
+			{"on", bson.A{"symbol", "openTime"}},
+			{"whenMatched", "replace"},
+			{"whenNotMatched", "insert"},
+		}}},
+	}, opts)
+	if err != nil {
+		return fmt.Errorf("MaterializedKline.RollupHours: Aggregate error '%w'", err)
+	}
+	return cursor.Close(ctx)
+}
+
+// FindLastBefore returns the last minute kline opened before t for every
+// market which has one.
+func (r *MaterializedKline) FindLastBefore(ctx context.Context, markets []string, t time.Time) ([]*model.Kline, error) {
+	c, err := r.collection(model.MinuteUnit)
+	if err != nil {
+		return nil, err
+	}
+	match := bson.D{{"openTime", bson.D{{"$lt", t}}}}
+	if len(markets) > 0 {
+		match = append(match, bson.E{Key: "symbol", Value: bson.D{{"$in", markets}}})
+	}
+	cursor, err := c.Aggregate(ctx, mongo.Pipeline{
+		{{"$match", match}},
+		{{"$sort", bson.D{{"symbol", 1}, {"openTime", -1}}}},
+		{{"$group", bson.D{{"_id", "$symbol"}, {"kline", bson.D{{"$first", "$$ROOT"}}}}}},
+		{{"$replaceRoot", bson.D{{"newRoot", "$kline"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MaterializedKline.FindLastBefore: Aggregate error '%w'", err)
+	}
+	klines := make([]*model.Kline, 0)
+	if err = cursor.All(ctx, &klines); err != nil {
+		return nil, fmt.Errorf("MaterializedKline.FindLastBefore: cursor.All error '%w'", err)
+	}
+	return klines, nil
+}
+
+// Watermark returns the time before which the klines are materialized, zero
+// if nothing is.
+func (r *MaterializedKline) Watermark(ctx context.Context) (time.Time, error) {
+	var state struct {
+		Until time.Time `bson:"until"`
+	}
+	err := r.state.FindOne(ctx, bson.D{{"_id", klineWatermarkID}}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("MaterializedKline.Watermark: FindOne error '%w'", err)
+	}
+	return state.Until, nil
+}
+
+func (r *MaterializedKline) SetWatermark(ctx context.Context, until time.Time) error {
+	_, err := r.state.UpdateOne(
+		ctx,
+		bson.D{{"_id", klineWatermarkID}},
+		bson.D{{"$set", bson.D{{"until", until}}}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("MaterializedKline.SetWatermark: UpdateOne error '%w'", err)
+	}
+	return nil
+}
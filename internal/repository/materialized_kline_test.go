@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func newTestMaterializedKline(mt *mtest.T) *MaterializedKline {
+	return NewMaterializedKline(
+		mt.DB.Collection("minutes"),
+		mt.DB.Collection("hours"),
+		mt.DB.Collection("state"),
+	)
+}
+
+func TestMaterializedKline_RollupHours(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	from := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	mt.Run("merges minutes into hours", func(mt *mtest.T) {
+		r := newTestMaterializedKline(mt)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.minutes", mtest.FirstBatch))
+
+		require.NoError(mt, r.RollupHours(context.Background(), from, from.Add(2*time.Hour)))
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(mt, "minutes", cmd.Lookup("aggregate").StringValue())
+		assert.True(mt, cmd.Lookup("allowDiskUse").Boolean())
+		stages, err := cmd.Lookup("pipeline").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, stages, 5)
+
+		period := stages[0].Document().Lookup("$match", "openTime")
+		assert.Equal(mt, from, period.Document().Lookup("$gte").Time().UTC())
+		assert.Equal(mt, from.Add(2*time.Hour), period.Document().Lookup("$lt").Time().UTC())
+
+		merge := stages[4].Document().Lookup("$merge").Document()
+		assert.Equal(mt, "hours", merge.Lookup("into").StringValue())
+		on, err := merge.Lookup("on").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, on, 2)
+		assert.Equal(mt, "symbol", on[0].StringValue())
+		assert.Equal(mt, "openTime", on[1].StringValue())
+		assert.Equal(mt, "replace", merge.Lookup("whenMatched").StringValue())
+		assert.Equal(mt, "insert", merge.Lookup("whenNotMatched").StringValue())
+	})
+}
+
+func TestMaterializedKline_Watermark(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	until := time.Date(2022, 5, 1, 10, 42, 0, 0, time.UTC)
+	mt.Run("nothing materialized", func(mt *mtest.T) {
+		r := newTestMaterializedKline(mt)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.state", mtest.FirstBatch))
+
+		got, err := r.Watermark(context.Background())
+		require.NoError(mt, err)
+		assert.True(mt, got.IsZero())
+	})
+	mt.Run("resumes from the stored state", func(mt *mtest.T) {
+		r := newTestMaterializedKline(mt)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.state", mtest.FirstBatch,
+			bson.D{{"_id", klineWatermarkID}, {"until", until}},
+		))
+
+		got, err := r.Watermark(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, until, got.UTC())
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(mt, "state", cmd.Lookup("find").StringValue())
+		assert.Equal(mt, klineWatermarkID, cmd.Lookup("filter", "_id").StringValue())
+	})
+	mt.Run("upserts the state", func(mt *mtest.T) {
+		r := newTestMaterializedKline(mt)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		require.NoError(mt, r.SetWatermark(context.Background(), until))
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(mt, "state", cmd.Lookup("update").StringValue())
+		updates, err := cmd.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, updates, 1)
+		update := updates[0].Document()
+		assert.Equal(mt, klineWatermarkID, update.Lookup("q", "_id").StringValue())
+		assert.Equal(mt, until, update.Lookup("u", "$set", "until").Time().UTC())
+		assert.True(mt, update.Lookup("upsert").Boolean())
+	})
+}
+
+func TestMaterializedKline_Save(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("unknown unit", func(mt *mtest.T) {
+		r := newTestMaterializedKline(mt)
+		err := r.Save(context.Background(), model.DayUnit, []*model.Kline{{Symbol: "ETH_BTC"}})
+		assert.Error(mt, err)
+	})
+}
@@ -2,21 +2,42 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
 	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"bitbucket.org/novatechnologies/ohlcv/internal/service"
 	"bitbucket.org/novatechnologies/ohlcv/protocol/ohlcv"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	candleSubscriberBufferSize = 1024
+	tickerSubscriberBufferSize = 1024
+	// dealsDedupWindow is how long before subscribing a replayed deal can
+	// still come from the live stream
+	dealsDedupWindow = time.Minute
+)
+
 type Ohlcv struct {
-	candleService *service.Candle
-	klineService  *service.Kline
-	dealConsumer  *consumer.Deal
-	dealService   *service.Deal
+	candleService  *service.Candle
+	klineService   *service.Kline
+	dealConsumer   *consumer.Deal
+	dealService    *service.Deal
+	candleConsumer *consumer.Candle
+	tickerConsumer *consumer.Ticker
+	currentCandles candle.CurrentCandles
+	markets        *domain.MarketRegistry
+	dealPolicy     consumer.SubscriberPolicy
 	ohlcv.UnimplementedOHLCVServiceServer
 }
 
@@ -25,12 +46,22 @@ func NewOhlcv(
 	klineService *service.Kline,
 	dealService *service.Deal,
 	dealConsumer *consumer.Deal,
+	candleConsumer *consumer.Candle,
+	tickerConsumer *consumer.Ticker,
+	currentCandles candle.CurrentCandles,
+	markets *domain.MarketRegistry,
+	dealPolicy consumer.SubscriberPolicy,
 ) *Ohlcv {
 	return &Ohlcv{
-		candleService: candleService,
-		klineService:  klineService,
-		dealService:   dealService,
-		dealConsumer:  dealConsumer,
+		candleService:  candleService,
+		klineService:   klineService,
+		dealService:    dealService,
+		dealConsumer:   dealConsumer,
+		candleConsumer: candleConsumer,
+		tickerConsumer: tickerConsumer,
+		currentCandles: currentCandles,
+		markets:        markets,
+		dealPolicy:     dealPolicy,
 	}
 }
 
@@ -64,28 +95,64 @@ func (h Ohlcv) GenerateMinutesKlines(ctx context.Context, request *ohlcv.Generat
 	}
 	rsp := &ohlcv.GenerateMinuteKlinesResponse{Klines: make([]*ohlcv.Kline, len(klns))}
 	for i := range klns {
-		rsp.Klines[i] = &ohlcv.Kline{
-			Open:        klns[i].Open.String(),
-			High:        klns[i].High.String(),
-			Low:         klns[i].Low.String(),
-			Close:       klns[i].Close.String(),
-			Symbol:      klns[i].Symbol,
-			Volume:      klns[i].Volume.String(),
-			Quotes:      klns[i].Quotes.String(),
-			OpenTime:    timestamppb.New(klns[i].OpenTime),
-			CloseTime:   timestamppb.New(klns[i].CloseTime),
-			Trades:      int32(klns[i].Trades),
-			TakerQuotes: klns[i].TakerQuotes.String(),
-			TakerAssets: klns[i].TakerAssets.String(),
-			First:       timestamppb.New(klns[i].First),
-			Last:        timestamppb.New(klns[i].Last),
+		if rsp.Klines[i], err = makeKline(klns[i]); err != nil {
+			logger.FromContext(ctx).Errorf("can't convert kline %v", err)
+			return nil, err
+		}
+	}
+	return rsp, nil
+}
+
+func (h Ohlcv) GetKlines(ctx context.Context, r *ohlcv.GetKlinesRequest) (*ohlcv.GetKlinesResponse, error) {
+	if r.Symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "specify symbol")
+	}
+	resolution, err := domain.ParseKlineInterval(r.Interval)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	limit := int(r.Limit)
+	if limit == 0 {
+		limit = domain.KlinesDefaultLimit
+	}
+	if limit < 0 || limit > domain.KlinesMaxLimit {
+		return nil, status.Error(codes.InvalidArgument, "limit should be in 1..1000")
+	}
+	var start, end time.Time
+	if r.StartTime != nil {
+		start = r.StartTime.AsTime()
+	}
+	if r.EndTime != nil {
+		end = r.EndTime.AsTime()
+	}
+	if !start.IsZero() && !end.IsZero() && start.After(end) {
+		return nil, status.Error(codes.InvalidArgument, "invalid time range")
+	}
+	market, err := h.resolveSymbol(r.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	klns, err := h.klineService.GetKlines(ctx, market, resolution, start, end, limit)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("error getting klines: %v", err)
+		return nil, err
+	}
+	rsp := &ohlcv.GetKlinesResponse{Klines: make([]*ohlcv.Kline, len(klns))}
+	for i := range klns {
+		if rsp.Klines[i], err = makeKline(klns[i]); err != nil {
+			logger.FromContext(ctx).Errorf("can't convert kline %v", err)
+			return nil, err
 		}
 	}
 	return rsp, nil
 }
 
 func (h Ohlcv) GetLastTrades(ctx context.Context, request *ohlcv.GetLastTradesRequest) (*ohlcv.GetLastTradesResponse, error) {
-	trades, err := h.dealService.GetLastTrades(ctx, request.Symbol, request.Limit)
+	market, err := h.resolveSymbol(request.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := h.dealService.GetLastTrades(ctx, market, request.Limit)
 	if err != nil {
 		logger.FromContext(ctx).Errorf("error getting last trades: %v", err)
 		return nil, err
@@ -94,83 +161,517 @@ func (h Ohlcv) GetLastTrades(ctx context.Context, request *ohlcv.GetLastTradesRe
 	rsp := &ohlcv.GetLastTradesResponse{Trades: make([]*ohlcv.Trade, len(trades))}
 
 	for i := range trades {
-		rsp.Trades[i] = &ohlcv.Trade{
-			Id:           trades[i].Data.DealId,
-			Price:        trades[i].Data.Price.String(),
-			Qty:          trades[i].Data.Volume.String(),
-			QuoteQty:     trades[i].Data.Volume.String(),
-			Time:         trades[i].T.Time().UnixNano(),
-			IsBuyerMaker: trades[i].Data.IsBuyerMaker,
+		if rsp.Trades[i], err = makeTrade(trades[i]); err != nil {
+			logger.FromContext(ctx).Errorf("can't convert trade %v", err)
+			return nil, err
 		}
 	}
 
 	return rsp, nil
 }
 
+func (h Ohlcv) GetHistoricalTrades(ctx context.Context, r *ohlcv.GetHistoricalTradesRequest) (*ohlcv.GetHistoricalTradesResponse, error) {
+	if r.Symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "specify symbol")
+	}
+	limit := r.Limit
+	if limit == 0 {
+		limit = 500
+	}
+	if limit < 0 || limit > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "limit should be in 1..1000")
+	}
+	if r.FromId != "" && r.Cursor != "" {
+		return nil, status.Error(codes.InvalidArgument, "fromId and cursor can't be used together")
+	}
+	market, err := h.resolveSymbol(r.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	q := model.TradesQuery{Market: market, Limit: int64(limit)}
+	if r.StartTime != nil {
+		q.StartTime = r.StartTime.AsTime()
+	}
+	if r.EndTime != nil {
+		q.EndTime = r.EndTime.AsTime()
+	}
+	if !q.StartTime.IsZero() && !q.EndTime.IsZero() && q.StartTime.After(q.EndTime) {
+		return nil, status.Error(codes.InvalidArgument, "invalid time range")
+	}
+	if r.Cursor != "" {
+		after, err := model.ParseTradesCursor(r.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		q.After = &after
+	}
+	trades, err := h.dealService.GetTrades(ctx, q, r.FromId)
+	if errors.Is(err, service.ErrTradeNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		logger.FromContext(ctx).Errorf("error getting historical trades: %v", err)
+		return nil, err
+	}
+	rsp := &ohlcv.GetHistoricalTradesResponse{
+		Trades:     make([]*ohlcv.Trade, len(trades)),
+		NextCursor: r.Cursor,
+	}
+	for i := range trades {
+		if rsp.Trades[i], err = makeTrade(trades[i]); err != nil {
+			logger.FromContext(ctx).Errorf("can't convert trade %v", err)
+			return nil, err
+		}
+	}
+	if len(trades) > 0 {
+		rsp.NextCursor = trades[len(trades)-1].Cursor().String()
+	}
+	return rsp, nil
+}
+
 func (h Ohlcv) GetTicker(ctx context.Context, r *ohlcv.GetTickerRequest) (*ohlcv.GetTickerResponse, error) {
-	tickers, err := h.dealService.GetTickerPriceChangeStatistics(ctx, r.Symbol)
+	market, err := h.resolveSymbol(r.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	tickers, err := h.dealService.GetTickerPriceChangeStatistics(ctx, market)
 	if err != nil {
 		logger.FromContext(ctx).Errorf("error getting tickers: %v", err)
 		return nil, err
 	}
 	rsp := &ohlcv.GetTickerResponse{Tickers: make([]*ohlcv.Ticker, len(tickers))}
 	for i := range tickers {
-		rsp.Tickers[i] = &ohlcv.Ticker{
-			Symbol:             tickers[i].Symbol,
-			PriceChange:        tickers[i].PriceChange,
-			PriceChangePercent: tickers[i].PriceChangePercent,
-			WeightedAvgPrice:   tickers[i].WeightedAvgPrice,
-			PrevClosePrice:     tickers[i].PrevClosePrice,
-			LastPrice:          tickers[i].LastPrice,
-			LastQty:            tickers[i].LastQty,
-			BidPrice:           tickers[i].BidPrice,
-			BidQty:             tickers[i].BidQty,
-			AskPrice:           tickers[i].AskPrice,
-			AskQty:             tickers[i].AskQty,
-			OpenPrice:          tickers[i].OpenPrice,
-			HighPrice:          tickers[i].HighPrice,
-			LowPrice:           tickers[i].LowPrice,
-			Volume:             tickers[i].Volume,
-			QuoteVolume:        tickers[i].QuoteVolume,
-			OpenTime:           tickers[i].OpenTime,
-			CloseTime:          tickers[i].CloseTime,
-			FirstId:            tickers[i].FirstId,
-			LastId:             tickers[i].LastId,
-			Count:              int32(tickers[i].Count),
-		}
+		rsp.Tickers[i] = makeTicker(tickers[i])
 	}
 	return rsp, nil
 
 }
 
-func (h Ohlcv) SubscribeDeals(_ *ohlcv.SubscribeDealsRequest, server ohlcv.OHLCVService_SubscribeDealsServer) error {
+func (h Ohlcv) GetWindowTicker(ctx context.Context, r *ohlcv.GetWindowTickerRequest) (*ohlcv.GetTickerResponse, error) {
+	windowSize := r.WindowSize
+	if windowSize == "" {
+		windowSize = "1d"
+	}
+	window, err := domain.ParseTickerWindow(windowSize)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	market, err := h.resolveSymbol(r.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	tickers, err := h.klineService.GetWindowTicker(ctx, market, window)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("error getting window tickers: %v", err)
+		return nil, err
+	}
+	rsp := &ohlcv.GetTickerResponse{Tickers: make([]*ohlcv.Ticker, len(tickers))}
+	for i := range tickers {
+		rsp.Tickers[i] = makeTicker(tickers[i])
+	}
+	return rsp, nil
+}
+
+func makeTicker(t *domain.TickerPriceChangeStatistics) *ohlcv.Ticker {
+	return &ohlcv.Ticker{
+		Symbol:             t.Symbol,
+		PriceChange:        t.PriceChange,
+		PriceChangePercent: t.PriceChangePercent,
+		WeightedAvgPrice:   t.WeightedAvgPrice,
+		PrevClosePrice:     t.PrevClosePrice,
+		LastPrice:          t.LastPrice,
+		LastQty:            t.LastQty,
+		BidPrice:           t.BidPrice,
+		BidQty:             t.BidQty,
+		AskPrice:           t.AskPrice,
+		AskQty:             t.AskQty,
+		OpenPrice:          t.OpenPrice,
+		HighPrice:          t.HighPrice,
+		LowPrice:           t.LowPrice,
+		Volume:             t.Volume,
+		QuoteVolume:        t.QuoteVolume,
+		OpenTime:           t.OpenTime,
+		CloseTime:          t.CloseTime,
+		FirstId:            t.FirstId,
+		LastId:             t.LastId,
+		Count:              int32(t.Count),
+	}
+}
+
+// SubscribeTickers sends the current 24h tickers and then streams their
+// throttled updates.
+func (h Ohlcv) SubscribeTickers(r *ohlcv.SubscribeTickersRequest, server ohlcv.OHLCVService_SubscribeTickersServer) error {
 	log := logger.FromContext(server.Context())
+	_, marketNames, err := h.resolveMarkets(r.Markets)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	id, err := uuid.NewUUID()
 	if err != nil {
 		log.Errorf("can't create uuid %v", err)
 		return err
 	}
-	ch := make(chan *model.Deal, 1024)
-	h.dealConsumer.Subscribe(id.String(), ch)
+	ch := make(chan *domain.TickerPriceChangeStatistics, tickerSubscriberBufferSize)
+	h.tickerConsumer.Subscribe(id.String(), marketNames, ch)
+	defer func() {
+		h.tickerConsumer.UnSubscribe(id.String())
+	}()
+	snapshot := marketNames
+	if len(snapshot) == 0 {
+		snapshot = h.markets.Names()
+	}
+	for _, market := range snapshot {
+		ticker, ok := h.tickerConsumer.Get(market)
+		if !ok {
+			continue
+		}
+		if err := server.Send(&ohlcv.SubscribeTickersResponse{Ticker: makeTicker(ticker)}); err != nil {
+			log.Errorf("can't send tickers %v", err)
+			return err
+		}
+	}
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case ticker := <-ch:
+			if err := server.Send(&ohlcv.SubscribeTickersResponse{Ticker: makeTicker(ticker)}); err != nil {
+				log.Errorf("can't send tickers %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeDeals streams deals of the requested markets. When the request has
+// a resume point, the missed deals are replayed from storage first.
+func (h Ohlcv) SubscribeDeals(r *ohlcv.SubscribeDealsRequest, server ohlcv.OHLCVService_SubscribeDealsServer) error {
+	log := logger.FromContext(server.Context())
+	_, marketNames, err := h.resolveMarkets(r.Markets)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	policy := h.dealPolicy
+	if r.Backpressure != "" {
+		if policy.Backpressure, err = consumer.ParseBackpressure(r.Backpressure); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		// a blocking subscriber delays the deals of all the others
+		if policy.Backpressure == consumer.Block {
+			return status.Error(codes.InvalidArgument, "block backpressure is set by the server only")
+		}
+	}
+	id, err := uuid.NewUUID()
+	if err != nil {
+		log.Errorf("can't create uuid %v", err)
+		return err
+	}
+	// subscribe before the replay to not miss deals in between
+	subscribedAt := time.Now()
+	subscriber := h.dealConsumer.Subscribe(id.String(), consumer.NewDealFilter(marketNames), policy)
 	defer func() {
 		h.dealConsumer.UnSubscribe(id.String())
 	}()
+	resume := model.DealsResume{DealId: r.LastDealId}
+	if r.Since != nil {
+		resume.Since = r.Since.AsTime()
+	}
+	// replayed deals which may come from the live stream too
+	replayed := make(map[string]struct{})
+	err = h.dealService.ReplayDeals(
+		server.Context(),
+		marketNames,
+		resume,
+		func(gap model.DealsGap) error {
+			return server.Send(makeDealsGapResponse(gap))
+		},
+		func(d *model.Deal) error {
+			if d.T.Time().After(subscribedAt.Add(-dealsDedupWindow)) {
+				replayed[d.Data.DealId] = struct{}{}
+			}
+			return server.Send(makeDealResponse(d))
+		},
+	)
+	if err != nil {
+		log.Errorf("can't replay deals %v", err)
+		return err
+	}
 	for {
 		select {
 		case <-server.Context().Done():
 			return nil
-		case d := <-ch:
-			err := server.Send(&ohlcv.SubscribeDealsResponse{
-				Time:         timestamppb.New(d.T.Time()),
-				DealId:       d.Data.DealId,
-				Price:        d.Data.Price.String(),
-				Volume:       d.Data.Volume.String(),
-				Symbol:       d.Data.Market,
-				IsBuyerMaker: d.Data.IsBuyerMaker,
-			})
+		case <-subscriber.Done():
+			if gap, ok := subscriber.TakeGap(); ok {
+				if err := server.Send(makeDealsGapResponse(gap)); err != nil {
+					return err
+				}
+			}
+			return status.Errorf(codes.ResourceExhausted, "too slow, %d deals dropped", subscriber.Dropped())
+		case <-subscriber.Gaps():
+			if gap, ok := subscriber.TakeGap(); ok {
+				if err := server.Send(makeDealsGapResponse(gap)); err != nil {
+					log.Errorf("can't send deals gap %v", err)
+					return err
+				}
+			}
+		case d := <-subscriber.Deals():
+			if _, ok := replayed[d.Data.DealId]; ok {
+				delete(replayed, d.Data.DealId)
+				continue
+			}
+			if err := server.Send(makeDealResponse(d)); err != nil {
+				log.Errorf("can't send deals %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeAggTrades streams the aggregate trades of the live deals.
+func (h Ohlcv) SubscribeAggTrades(r *ohlcv.SubscribeAggTradesRequest, server ohlcv.OHLCVService_SubscribeAggTradesServer) error {
+	log := logger.FromContext(server.Context())
+	_, marketNames, err := h.resolveMarkets(r.Markets)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := uuid.NewUUID()
+	if err != nil {
+		log.Errorf("can't create uuid %v", err)
+		return err
+	}
+	subscriber := h.dealConsumer.Subscribe(id.String(), consumer.NewDealFilter(marketNames), h.dealPolicy)
+	defer func() {
+		h.dealConsumer.UnSubscribe(id.String())
+	}()
+	aggs := domain.NewAggTrades(domain.AggTradeWindow)
+	send := func(trades ...*domain.AggTrade) error {
+		for _, t := range trades {
+			if err := server.Send(makeAggTrade(t)); err != nil {
+				log.Errorf("can't send aggregate trade %v", err)
+				return err
+			}
+		}
+		return nil
+	}
+	ticker := time.NewTicker(domain.AggTradeWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case <-subscriber.Done():
+			return status.Errorf(codes.ResourceExhausted, "too slow, %d deals dropped", subscriber.Dropped())
+		case <-subscriber.Gaps():
+			// the deals around a gap are not aggregated together
+			subscriber.TakeGap()
+			if err := send(aggs.Flush()...); err != nil {
+				return err
+			}
+		case d := <-subscriber.Deals():
+			trade, err := aggs.Add(d)
 			if err != nil {
-				logger.FromContext(server.Context()).Errorf("can't send deals %v", err)
+				log.Errorf("can't aggregate deal %v", err)
+				continue
+			}
+			if trade != nil {
+				if err := send(trade); err != nil {
+					return err
+				}
+			}
+		case now := <-ticker.C:
+			if err := send(aggs.Expire(now)...); err != nil {
+				return err
 			}
 		}
 	}
 }
+
+func makeAggTrade(t *domain.AggTrade) *ohlcv.AggTrade {
+	return &ohlcv.AggTrade{
+		Symbol:       t.Symbol,
+		Price:        t.Price.String(),
+		Qty:          decimal128.String(t.Qty),
+		FirstId:      t.FirstId,
+		LastId:       t.LastId,
+		FirstTime:    timestamppb.New(t.FirstTime),
+		LastTime:     timestamppb.New(t.LastTime),
+		IsBuyerMaker: t.IsBuyerMaker,
+		Count:        int32(t.Count),
+	}
+}
+
+func makeDealResponse(d *model.Deal) *ohlcv.SubscribeDealsResponse {
+	return &ohlcv.SubscribeDealsResponse{
+		Time:         timestamppb.New(d.T.Time()),
+		DealId:       d.Data.DealId,
+		Price:        d.Data.Price.String(),
+		Volume:       d.Data.Volume.String(),
+		Symbol:       d.Data.Market,
+		IsBuyerMaker: d.Data.IsBuyerMaker,
+	}
+}
+
+func makeDealsGapResponse(gap model.DealsGap) *ohlcv.SubscribeDealsResponse {
+	rsp := &ohlcv.SubscribeDealsResponse{
+		Gap: &ohlcv.DealsGap{
+			To:      timestamppb.New(gap.To),
+			Reason:  gap.Reason,
+			Dropped: gap.Dropped,
+		},
+	}
+	if !gap.From.IsZero() {
+		rsp.Gap.From = timestamppb.New(gap.From)
+	}
+	return rsp
+}
+
+// SubscribeCandles sends a snapshot of the current candles and then streams
+// their updates and close events.
+func (h Ohlcv) SubscribeCandles(r *ohlcv.SubscribeCandlesRequest, server ohlcv.OHLCVService_SubscribeCandlesServer) error {
+	log := logger.FromContext(server.Context())
+	marketIDs, marketNames, err := h.resolveMarkets(r.Markets)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resolutions := make([]model.Resolution, 0, len(r.Resolutions))
+	for _, res := range r.Resolutions {
+		resolution := model.Resolution(res)
+		if resolution.IsNotExist() {
+			return status.Errorf(codes.InvalidArgument, "unknown resolution %s", res)
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	id, err := uuid.NewUUID()
+	if err != nil {
+		log.Errorf("can't create uuid %v", err)
+		return err
+	}
+	// subscribe before taking the snapshot to not miss updates in between
+	ch := make(chan domain.Candle, candleSubscriberBufferSize)
+	h.candleConsumer.Subscribe(id.String(), consumer.NewCandleFilter(marketNames, resolutions), ch)
+	defer func() {
+		h.candleConsumer.UnSubscribe(id.String())
+	}()
+	for _, c := range h.currentCandles.Snapshot(marketIDs, resolutions) {
+		c.Symbol = h.marketName(c.Symbol)
+		if err := server.Send(makeCandleResponse(ohlcv.CandleEvent_CANDLE_SNAPSHOT, c)); err != nil {
+			log.Errorf("can't send candles snapshot %v", err)
+			return err
+		}
+	}
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case c := <-ch:
+			event := ohlcv.CandleEvent_CANDLE_UPDATE
+			if c.Closed {
+				event = ohlcv.CandleEvent_CANDLE_CLOSED
+			}
+			if err := server.Send(makeCandleResponse(event, c)); err != nil {
+				log.Errorf("can't send candles %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// resolveMarkets returns ids and names of the requested markets, the ids are
+// nil when all markets are requested.
+func (h Ohlcv) resolveMarkets(markets []string) ([]string, []string, error) {
+	if len(markets) == 0 {
+		return nil, nil, nil
+	}
+	ids := make([]string, 0, len(markets))
+	names := make([]string, 0, len(markets))
+	for _, m := range markets {
+		resolved, ok := h.markets.Resolve(m)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown market %s", m)
+		}
+		ids = append(ids, resolved.ID)
+		names = append(names, resolved.Name)
+	}
+	return ids, names, nil
+}
+
+// resolveSymbol returns the market name of the symbol in any accepted format,
+// an empty symbol stays empty.
+func (h Ohlcv) resolveSymbol(symbol string) (string, error) {
+	if symbol == "" {
+		return "", nil
+	}
+	name, ok := h.markets.ResolveName(symbol)
+	if !ok {
+		return "", status.Errorf(codes.InvalidArgument, "unknown market %s", symbol)
+	}
+	return name, nil
+}
+
+func (h Ohlcv) marketName(id string) string {
+	if name, ok := h.markets.Name(id); ok {
+		return name
+	}
+	return id
+}
+
+func makeCandleResponse(event ohlcv.CandleEvent, c domain.Candle) *ohlcv.SubscribeCandlesResponse {
+	return &ohlcv.SubscribeCandlesResponse{
+		Event:      event,
+		Resolution: string(c.Resolution),
+		Candle: &ohlcv.Candle{
+			OpenTime: timestamppb.New(c.OpenTime),
+			Open:     c.Open.String(),
+			High:     c.High.String(),
+			Low:      c.Low.String(),
+			Close:    c.Close.String(),
+			Volume:   c.Volume.String(),
+			Symbol:   c.Symbol,
+		},
+		CloseTime: timestamppb.New(c.CloseTime),
+	}
+}
+
+func makeKline(k *model.Kline) (*ohlcv.Kline, error) {
+	takerBuyAssets, err := k.TakerBuyAssets()
+	if err != nil {
+		return nil, err
+	}
+	takerBuyQuotes, err := k.TakerBuyQuotes()
+	if err != nil {
+		return nil, err
+	}
+	return &ohlcv.Kline{
+		Open:           k.Open.String(),
+		High:           k.High.String(),
+		Low:            k.Low.String(),
+		Close:          k.Close.String(),
+		Symbol:         k.Symbol,
+		Volume:         k.Volume.String(),
+		Quotes:         k.Quotes.String(),
+		OpenTime:       timestamppb.New(k.OpenTime),
+		CloseTime:      timestamppb.New(k.CloseTime),
+		Trades:         int32(k.Trades),
+		TakerQuotes:    k.TakerQuotes.String(),
+		TakerAssets:    k.TakerAssets.String(),
+		TakerBuyAssets: takerBuyAssets.String(),
+		TakerBuyQuotes: takerBuyQuotes.String(),
+		First:          timestamppb.New(k.First),
+		Last:           timestamppb.New(k.Last),
+	}, nil
+}
+
+func makeTrade(d *model.Deal) (*ohlcv.Trade, error) {
+	quoteQty, err := d.QuoteQty()
+	if err != nil {
+		return nil, err
+	}
+	return &ohlcv.Trade{
+		Id:           d.Data.DealId,
+		Price:        d.Data.Price.String(),
+		Qty:          d.Data.Volume.String(),
+		QuoteQty:     decimal128.String(quoteQty),
+		Time:         d.T.Time().UnixNano(),
+		IsBuyerMaker: d.Data.IsBuyerMaker,
+	}, nil
+}
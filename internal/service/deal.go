@@ -3,6 +3,8 @@ package service
 import (
 	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
@@ -14,28 +16,124 @@ import (
 	"bitbucket.org/novatechnologies/interfaces/matcher"
 	"bitbucket.org/novatechnologies/ohlcv/candle"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+	"bitbucket.org/novatechnologies/ohlcv/infra/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 )
 
 type Deal struct {
 	under       *repository.Deal
 	tickerCache *consumer.Ticker
-	marketsMap  map[string]string
+	markets     *domain.MarketRegistry
 	dealChanel  chan *model.Deal
+	// lastConsumed is the unix nano time of the last consumed deal, or of
+	// the consumption start.
+	lastConsumed int64
+	// liveOnly skips saving the consumed deals.
+	liveOnly bool
+	// owns returns true for the ids of the markets with the live state kept
+	// by the instance, nil means all of them.
+	owns func(market string) bool
+	// held are the live deals by the ids of the markets whose state is
+	// loaded, they're applied on release.
+	heldMu sync.Mutex
+	held   map[string][]*matcher.Deal
 }
 
 func NewDeal(
 	dealRepository *repository.Deal,
 	tickerCache *consumer.Ticker,
-	marketsMap map[string]string,
+	markets *domain.MarketRegistry,
 	dealChanel chan *model.Deal,
 ) *Deal {
 	return &Deal{
 		under:       dealRepository,
 		tickerCache: tickerCache,
-		marketsMap:  marketsMap,
+		markets:     markets,
 		dealChanel:  dealChanel,
+		held:        map[string][]*matcher.Deal{},
+	}
+}
+
+// KeepLiveOnly stops saving the consumed deals, they still update the current
+// candles, the tickers and the deal streams. It's the mode of the API
+// replicas and of the sharded ingesting instances, the deals are saved by
+// RunSaving of an ingesting instance.
+func (s *Deal) KeepLiveOnly() {
+	s.liveOnly = true
+}
+
+// OwnMarkets limits the live state, the current candles and the tickers, to
+// the markets owned by the instance. The deals of all of them are still
+// streamed.
+func (s *Deal) OwnMarkets(owns func(market string) bool) {
+	s.owns = owns
+}
+
+// Hold buffers the live deals of the market ids until they're released, so
+// the deals consumed while the state of the markets is loaded aren't lost.
+func (s *Deal) Hold(markets ...string) {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+	for _, market := range markets {
+		if _, ok := s.held[market]; !ok {
+			s.held[market] = nil
+		}
+	}
+}
+
+// Release applies the held live deals of the market ids and stops holding
+// them. The deals covered by the loaded state are skipped by the current
+// candles and the tickers.
+func (s *Deal) Release(ctx context.Context, currentCandles candle.CurrentCandles, markets ...string) {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+	for _, market := range markets {
+		for _, deal := range s.held[market] {
+			s.applyLive(ctx, deal, currentCandles, s.tickerCache.AddLiveDeal)
+		}
+		delete(s.held, market)
+	}
+}
+
+// consumeLive applies the consumed deal to the live state unless the market
+// is held or isn't owned.
+func (s *Deal) consumeLive(ctx context.Context, dealMessage *matcher.Deal, currentCandles candle.CurrentCandles) {
+	s.heldMu.Lock()
+	if held, ok := s.held[dealMessage.Market]; ok {
+		s.held[dealMessage.Market] = append(held, dealMessage)
+		s.heldMu.Unlock()
+		return
+	}
+	s.heldMu.Unlock()
+	if s.owns != nil && !s.owns(dealMessage.Market) {
+		return
+	}
+	s.applyLive(ctx, dealMessage, currentCandles, func(deal *matcher.Deal) error {
+		s.tickerCache.UpdateWithNewDeal(ctx, deal)
+		return nil
+	})
+}
+
+func (s *Deal) applyLive(
+	ctx context.Context,
+	dealMessage *matcher.Deal,
+	currentCandles candle.CurrentCandles,
+	addTickerDeal func(*matcher.Deal) error,
+) {
+	if err := currentCandles.AddDeal(ctx, dealMessage); err != nil {
+		logger.FromContext(ctx).
+			WithField("method", "currentCandles.AddDeal in consuming").
+			Errorf(err)
+	}
+	if err := addTickerDeal(dealMessage); err != nil {
+		logger.FromContext(ctx).
+			WithField("dealMessage", dealMessage).
+			Errorf("can't add deal to ticker %v", err)
 	}
 }
 
@@ -44,8 +142,30 @@ func (s *Deal) SaveDeal(ctx context.Context, dealMessage *matcher.Deal) (*model.
 		logger.FromContext(ctx).Infof("The deal have empty TakerOrderId or MakerOrderId field. Skip. Dont save to mongo.")
 		return nil, nil
 	}
+	deal, err := s.newDeal(dealMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !s.liveOnly {
+		if err := s.storeDeal(ctx, deal); err != nil {
+			return nil, err
+		}
+	}
+	select {
+	case s.dealChanel <- deal:
+	default:
+		metrics.ChannelDropped.WithLabelValues("deal").Inc()
+		logger.FromContext(ctx).Errorf("deal channel overloaded")
+	}
+	return deal, nil
+}
+
+func (s *Deal) newDeal(dealMessage *matcher.Deal) (*model.Deal, error) {
 	t := time.Unix(0, dealMessage.CreatedAt)
-	marketName := s.marketsMap[dealMessage.Market]
+	marketName, ok := s.markets.Name(dealMessage.Market)
+	if !ok {
+		return nil, errors.Errorf("unknown market %s", dealMessage.Market)
+	}
 	deal := &model.Deal{
 		T: primitive.NewDateTimeFromTime(t),
 		Data: model.DealData{
@@ -59,16 +179,19 @@ func (s *Deal) SaveDeal(ctx context.Context, dealMessage *matcher.Deal) (*model.
 	if err := deal.Validate(); err != nil {
 		return nil, err
 	}
+	return deal, nil
+}
+
+func (s *Deal) storeDeal(ctx context.Context, deal *model.Deal) error {
+	ctx, span := tracing.Tracer().Start(ctx, "Deal.SaveDeal")
+	start := time.Now()
 	err := s.under.Save(ctx, deal)
+	metrics.ObserveSince(metrics.SaveDealDuration, start)
+	tracing.EndSpan(span, err)
 	if err != nil {
-		return nil, err
+		metrics.SaveDealErrors.Inc()
 	}
-	select {
-	case s.dealChanel <- deal:
-	default:
-		logger.FromContext(ctx).Errorf("deal channel overloaded")
-	}
-	return deal, nil
+	return err
 }
 
 func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string) ([]*domain.TickerPriceChangeStatistics, error) {
@@ -87,7 +210,7 @@ func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string
 		return []*domain.TickerPriceChangeStatistics{resp}, nil
 	}
 
-	for _, m := range s.marketsMap {
+	for _, m := range s.markets.Names() {
 		resp, ok := s.tickerCache.Get(m)
 		if !ok {
 			logger.
@@ -104,6 +227,12 @@ func (s *Deal) GetTickerPriceChangeStatistics(ctx context.Context, market string
 	return result, nil
 }
 
+// GetLastPosition returns the position of the last stored deal of the market
+// name.
+func (s *Deal) GetLastPosition(ctx context.Context, market string) (model.DealPosition, error) {
+	return s.under.GetLastPosition(ctx, market)
+}
+
 func (s *Deal) GetAvgPrice(ctx context.Context, duration time.Duration, market string) (string, error) {
 	return s.under.GetAvgPrice(ctx, duration, market)
 }
@@ -112,8 +241,226 @@ func (s *Deal) GetLastTrades(ctx context.Context, symbol string, limit int32) ([
 	return s.under.GetLastTrades(ctx, symbol, limit)
 }
 
-func (s *Deal) RunConsuming(ctx context.Context, consumer pubsub.Subscriber, topic string, currentCandles candle.CurrentCandles) {
+// ErrTradeNotFound is returned if there is no trade to start from.
+var ErrTradeNotFound = errors.New("trade not found")
+
+// GetTrades returns the deals selected by the query oldest first, fromId sets
+// the query From position to the deal with the id.
+func (s *Deal) GetTrades(ctx context.Context, q model.TradesQuery, fromId string) ([]*model.Deal, error) {
+	if err := s.setFromId(ctx, &q, fromId); err != nil {
+		return nil, err
+	}
+	return s.under.GetTrades(ctx, q)
+}
+
+func (s *Deal) setFromId(ctx context.Context, q *model.TradesQuery, fromId string) error {
+	if fromId == "" {
+		return nil
+	}
+	deal, err := s.under.GetDealByID(ctx, fromId)
+	if err != nil {
+		return err
+	}
+	if deal == nil || deal.Data.Market != q.Market {
+		return ErrTradeNotFound
+	}
+	from := deal.Cursor()
+	q.From = &from
+	return nil
+}
+
+// aggTradesBatchSize is the number of deals read at once to aggregate them.
+const aggTradesBatchSize = 1000
+
+// GetAggTrades returns up to the query limit aggregate trades made of the
+// deals selected by the query, fromId sets the position of the first deal.
+// The deals are read until the aggregate trades are closed, except the
+// latest ones.
+func (s *Deal) GetAggTrades(ctx context.Context, q model.TradesQuery, fromId string) ([]*domain.AggTrade, error) {
+	if err := s.setFromId(ctx, &q, fromId); err != nil {
+		return nil, err
+	}
+	limit := int(q.Limit)
+	latest := q.Latest()
+	if !latest {
+		q.Limit = aggTradesBatchSize
+	}
+	aggs := domain.NewAggTrades(domain.AggTradeWindow)
+	result := make([]*domain.AggTrade, 0)
+	for {
+		deals, err := s.under.GetTrades(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deals {
+			trade, err := aggs.Add(d)
+			if err != nil {
+				return nil, err
+			}
+			if trade != nil {
+				result = append(result, trade)
+				if len(result) == limit {
+					return result, nil
+				}
+			}
+		}
+		if latest || len(deals) < aggTradesBatchSize {
+			break
+		}
+		last := deals[len(deals)-1].Cursor()
+		q.From, q.After = nil, &last
+	}
+	result = append(result, aggs.Flush()...)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+const (
+	replayBatchSize = 1000
+	// MaxReplayWindow limits how far back a deals stream can be resumed.
+	MaxReplayWindow = 24 * time.Hour
+)
+
+// ReplayDeals sends stored deals of the markets made after the resume point,
+// oldest first. Periods which can't be replayed are reported to onGap. Deals
+// made in the same millisecond as the resume deal may be sent again, so
+// receivers should deduplicate by deal id.
+func (s *Deal) ReplayDeals(
+	ctx context.Context,
+	markets []string,
+	resume model.DealsResume,
+	onGap func(model.DealsGap) error,
+	onDeal func(*model.Deal) error,
+) error {
+	if resume.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	from := resume.Since
+	sent := make(map[string]struct{})
+	if resume.DealId != "" {
+		deal, err := s.under.GetDealByID(ctx, resume.DealId)
+		if err != nil {
+			return err
+		}
+		if deal != nil {
+			from = deal.T.Time()
+			sent[deal.Data.DealId] = struct{}{}
+		} else if from.IsZero() {
+			return onGap(model.DealsGap{To: now, Reason: "resume deal not found"})
+		}
+	}
+	if oldest := now.Add(-MaxReplayWindow); from.Before(oldest) {
+		if err := onGap(model.DealsGap{From: from, To: oldest, Reason: "resume point is too old"}); err != nil {
+			return err
+		}
+		from = oldest
+	}
+	return s.replayDealsSince(ctx, markets, from, sent, onGap, onDeal)
+}
+
+// replayDealsSince sends stored deals made at or after from except the sent
+// ones.
+func (s *Deal) replayDealsSince(
+	ctx context.Context,
+	markets []string,
+	from time.Time,
+	sent map[string]struct{},
+	onGap func(model.DealsGap) error,
+	onDeal func(*model.Deal) error,
+) error {
+	for {
+		deals, err := s.under.GetDealsSince(ctx, markets, from, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		last := from
+		for _, deal := range deals {
+			if _, ok := sent[deal.Data.DealId]; ok {
+				continue
+			}
+			if t := deal.T.Time(); t.After(last) {
+				// only the deals of the last millisecond can be fetched again
+				last = t
+				sent = make(map[string]struct{})
+			}
+			sent[deal.Data.DealId] = struct{}{}
+			if err := onDeal(deal); err != nil {
+				return err
+			}
+		}
+		if len(deals) < replayBatchSize {
+			return nil
+		}
+		if last.Equal(from) {
+			// the whole batch is made in one millisecond, skip the rest of it
+			next := from.Add(time.Millisecond)
+			if err := onGap(model.DealsGap{From: from, To: next, Reason: "too many deals to replay"}); err != nil {
+				return err
+			}
+			last = next
+		}
+		from = last
+	}
+}
+
+// RunSaving saves the deals of the topic until the context is done, the
+// returned channel is closed when the saving is finished. It's run in the
+// consumer group shared by the ingesting instances when the live state is
+// consumed apart, each deal is saved by one of them.
+func (s *Deal) RunSaving(ctx context.Context, consumer pubsub.Subscriber, topic string) <-chan struct{} {
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
+		err := consumer.Consume(
+			ctx,
+			topic,
+			func(ctx context.Context, metadata map[string]string, msg []byte) (err error) {
+				ctx, span := tracing.Tracer().Start(
+					tracing.Extract(ctx, metadata),
+					"deals.save",
+					trace.WithSpanKind(trace.SpanKindConsumer),
+					trace.WithAttributes(
+						semconv.MessagingSystemKey.String("kafka"),
+						semconv.MessagingDestinationKey.String(topic),
+					),
+				)
+				defer func() {
+					tracing.EndSpan(span, err)
+				}()
+				dealMessage := &matcher.Deal{}
+				if err := proto.Unmarshal(msg, dealMessage); err != nil {
+					return errors.Wrap(err, "unmarshal error with protobuf deals msg")
+				}
+				if dealMessage.TakerOrderId == "" || dealMessage.MakerOrderId == "" {
+					return nil
+				}
+				deal, err := s.newDeal(dealMessage)
+				if err != nil {
+					return err
+				}
+				return errors.Wrapf(s.storeDeal(ctx, deal), "while saving deal %v into DB", deal)
+			},
+		)
+		if err != nil {
+			logger.FromContext(ctx).
+				WithField("err", err).
+				WithField("svc", "Deal").
+				Errorf("Saving session was finished with error")
+		}
+	}()
+	return done
+}
+
+// RunConsuming consumes the deals until the context is done, the returned
+// channel is closed when the consumption is finished.
+func (s *Deal) RunConsuming(ctx context.Context, consumer pubsub.Subscriber, topic string, currentCandles candle.CurrentCandles) <-chan struct{} {
+	atomic.StoreInt64(&s.lastConsumed, time.Now().UnixNano())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
 		err := func() error {
 			return consumer.Consume(
 				ctx,
@@ -122,7 +469,19 @@ func (s *Deal) RunConsuming(ctx context.Context, consumer pubsub.Subscriber, top
 					ctx context.Context,
 					metadata map[string]string,
 					msg []byte,
-				) error {
+				) (err error) {
+					ctx, span := tracing.Tracer().Start(
+						tracing.Extract(ctx, metadata),
+						"deals.consume",
+						trace.WithSpanKind(trace.SpanKindConsumer),
+						trace.WithAttributes(
+							semconv.MessagingSystemKey.String("kafka"),
+							semconv.MessagingDestinationKey.String(topic),
+						),
+					)
+					defer func() {
+						tracing.EndSpan(span, err)
+					}()
 					dealMessage := &matcher.Deal{}
 					if err := proto.Unmarshal(msg, dealMessage); err != nil {
 						logger.FromContext(ctx).
@@ -134,13 +493,12 @@ func (s *Deal) RunConsuming(ctx context.Context, consumer pubsub.Subscriber, top
 							"unmarshal error with protobuf deals msg",
 						)
 					}
-					err := currentCandles.AddDeal(dealMessage)
-					if err != nil {
-						logger.FromContext(ctx).
-							WithField("method", "currentCandles.AddDeal in consuming").
-							Errorf(err)
-					}
-					s.tickerCache.UpdateWithNewDeal(ctx, dealMessage.GetMarket(), dealMessage)
+					span.SetAttributes(
+						attribute.String("deal.id", dealMessage.Id),
+						attribute.String("deal.market", dealMessage.Market),
+					)
+					s.observeConsumed(topic, dealMessage)
+					s.consumeLive(ctx, dealMessage, currentCandles)
 					if deal, err := s.SaveDeal(ctx, dealMessage); err != nil {
 						return errors.Wrapf(err, "while saving deal %v into DB", deal)
 					}
@@ -155,28 +513,72 @@ func (s *Deal) RunConsuming(ctx context.Context, consumer pubsub.Subscriber, top
 				Errorf("Consuming session was finished with error", err)
 		}
 	}()
+	return done
 }
 
-func (s *Deal) LoadCache(ctx context.Context) {
-	const op = "cacheService_LoadCache"
-	ticker := time.NewTicker(time.Second)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			currentTickers, err := s.under.GetTickerPriceChangeStatistics(ctx, "")
-			if err != nil {
-				logger.FromContext(ctx).
-					WithField("err", err).
-					WithField("op", op).
-					Errorf("loading cache with error", err)
+// LastConsumedAt returns the time of the last consumed deal, it's the start of
+// the consumption until a deal is consumed.
+func (s *Deal) LastConsumedAt() time.Time {
+	if at := atomic.LoadInt64(&s.lastConsumed); at != 0 {
+		return time.Unix(0, at)
+	}
+	return time.Time{}
+}
 
-				continue
-			}
-			for _, ct := range currentTickers {
-				s.tickerCache.Set(ct.Symbol, ct)
-			}
+func (s *Deal) observeConsumed(topic string, dealMessage *matcher.Deal) {
+	atomic.StoreInt64(&s.lastConsumed, time.Now().UnixNano())
+	metrics.ConsumerLag.WithLabelValues(topic).Set(time.Since(time.Unix(0, dealMessage.CreatedAt)).Seconds())
+	market, ok := s.markets.Name(dealMessage.Market)
+	if !ok {
+		market = "unknown"
+	}
+	metrics.DealsConsumed.WithLabelValues(market).Inc()
+}
+
+// LoadTickers fills the ticker cache with the deals of the ticker window of
+// the market names, empty names mean all of them. The live deals of the
+// markets should be held during the load. The loaded deals end at the last
+// stored one, so only the deals covered by its position are skipped then,
+// the ones which aren't stored yet are added.
+func (s *Deal) LoadTickers(ctx context.Context, markets ...string) error {
+	from := time.Now().Add(-domain.TickerWindow)
+	names := markets
+	if len(names) == 0 {
+		names = s.markets.Names()
+	}
+	for _, market := range names {
+		deal, err := s.under.GetLastDealBefore(ctx, market, from)
+		if err != nil {
+			return err
+		}
+		if deal != nil {
+			s.addTickerDeal(ctx, deal)
 		}
 	}
+	defer s.tickerCache.Loaded(names...)
+	return s.replayDealsSince(
+		ctx,
+		markets,
+		from,
+		make(map[string]struct{}),
+		func(gap model.DealsGap) error {
+			logger.FromContext(ctx).
+				WithField("from", gap.From).
+				WithField("to", gap.To).
+				Errorf("can't load tickers: %s", gap.Reason)
+			return nil
+		},
+		func(deal *model.Deal) error {
+			s.addTickerDeal(ctx, deal)
+			return nil
+		},
+	)
+}
+
+func (s *Deal) addTickerDeal(ctx context.Context, deal *model.Deal) {
+	if err := s.tickerCache.AddDeal(deal); err != nil {
+		logger.FromContext(ctx).
+			WithField("market", deal.Data.Market).
+			Errorf("can't add deal to ticker %v", err)
+	}
 }
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func storedDeal(id string, t time.Time) bson.D {
+	return bson.D{
+		{"_id", primitive.NewObjectID()},
+		{"t", primitive.NewDateTimeFromTime(t)},
+		{"data", bson.D{{"dealid", id}, {"market", "ETH_BTC"}}},
+	}
+}
+
+// fullBatch returns a replay batch of deals with the ids from first on made
+// at t.
+func fullBatch(first int, t time.Time) []bson.D {
+	deals := make([]bson.D, 0, replayBatchSize)
+	for i := 0; i < replayBatchSize; i++ {
+		deals = append(deals, storedDeal(strconv.Itoa(first+i), t))
+	}
+	return deals
+}
+
+func TestDeal_replayDealsSince(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	from := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	ms := time.Millisecond
+
+	for _, tt := range []struct {
+		name    string
+		sent    []string
+		batches [][]bson.D
+		// wantFrom are the times the batches are fetched from.
+		wantFrom []time.Time
+		wantIDs  []string
+		wantGaps []model.DealsGap
+	}{
+		{
+			name:     "empty range",
+			batches:  [][]bson.D{nil},
+			wantFrom: []time.Time{from},
+		},
+		{
+			name: "sent deal of the resume millisecond",
+			sent: []string{"1"},
+			batches: [][]bson.D{{
+				storedDeal("1", from),
+				storedDeal("2", from),
+				storedDeal("3", from.Add(ms)),
+			}},
+			wantFrom: []time.Time{from},
+			wantIDs:  []string{"2", "3"},
+		},
+		{
+			name: "duplicate ids at the boundary millisecond",
+			batches: [][]bson.D{
+				append(fullBatch(0, from)[:replayBatchSize-2], storedDeal("a", from.Add(ms)), storedDeal("b", from.Add(ms))),
+				{storedDeal("a", from.Add(ms)), storedDeal("b", from.Add(ms)), storedDeal("c", from.Add(2*ms))},
+			},
+			wantFrom: []time.Time{from, from.Add(ms)},
+			wantIDs:  append(idRange(0, replayBatchSize-2), "a", "b", "c"),
+		},
+		{
+			name: "gap of a full millisecond",
+			batches: [][]bson.D{
+				fullBatch(0, from),
+				{storedDeal("a", from.Add(ms))},
+			},
+			wantFrom: []time.Time{from, from.Add(ms)},
+			wantIDs:  append(idRange(0, replayBatchSize), "a"),
+			wantGaps: []model.DealsGap{{From: from, To: from.Add(ms), Reason: "too many deals to replay"}},
+		},
+	} {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+			for _, batch := range tt.batches {
+				mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, batch...))
+			}
+			sent := make(map[string]struct{})
+			for _, id := range tt.sent {
+				sent[id] = struct{}{}
+			}
+
+			var ids []string
+			var gaps []model.DealsGap
+			err := s.replayDealsSince(
+				context.Background(),
+				[]string{"ETH_BTC"},
+				from,
+				sent,
+				func(gap model.DealsGap) error {
+					gaps = append(gaps, gap)
+					return nil
+				},
+				func(deal *model.Deal) error {
+					ids = append(ids, deal.Data.DealId)
+					return nil
+				},
+			)
+			require.NoError(mt, err)
+			assert.Equal(mt, tt.wantIDs, ids)
+			assert.Equal(mt, tt.wantGaps, gaps)
+
+			for _, want := range tt.wantFrom {
+				evt := mt.GetStartedEvent()
+				require.NotNil(mt, evt)
+				got := evt.Command.Lookup("filter", "t", "$gte").Time().UTC()
+				assert.Equal(mt, want, got)
+			}
+			assert.Nil(mt, mt.GetStartedEvent())
+		})
+	}
+}
+
+func idRange(first, n int) []string {
+	ids := make([]string, 0, n)
+	for i := first; i < first+n; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	return ids
+}
+
+func storedTrade(id primitive.ObjectID, dealId string, t time.Time, price string, buyerMaker bool) bson.D {
+	return bson.D{
+		{"_id", id},
+		{"t", primitive.NewDateTimeFromTime(t)},
+		{"data", bson.D{
+			{"dealid", dealId},
+			{"market", "ETH_BTC"},
+			{"price", model.MustParseDecimal(price)},
+			{"volume", model.MustParseDecimal("0.5")},
+			{"isbuyermaker", buyerMaker},
+		}},
+	}
+}
+
+func TestDeal_GetAggTrades(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	ms := time.Millisecond
+
+	mt.Run("consecutive deals of the same price", func(mt *mtest.T) {
+		s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			storedTrade(primitive.NewObjectID(), "1", start, "10", false),
+			storedTrade(primitive.NewObjectID(), "2", start.Add(10*ms), "10", false),
+			storedTrade(primitive.NewObjectID(), "3", start.Add(20*ms), "10.0", false),
+			storedTrade(primitive.NewObjectID(), "4", start.Add(30*ms), "11", false),
+			storedTrade(primitive.NewObjectID(), "5", start.Add(40*ms), "11", true),
+			storedTrade(primitive.NewObjectID(), "6", start.Add(time.Second), "11", true),
+		))
+
+		trades, err := s.GetAggTrades(context.Background(), model.TradesQuery{
+			Market:    "ETH_BTC",
+			StartTime: start,
+			EndTime:   end,
+			Limit:     10,
+		}, "")
+		require.NoError(mt, err)
+		require.Len(mt, trades, 4)
+		for i, want := range []struct {
+			first, last string
+			count       int
+			qty         string
+		}{
+			{"1", "3", 3, "1.5"},
+			{"4", "4", 1, "0.5"},
+			{"5", "5", 1, "0.5"},
+			{"6", "6", 1, "0.5"},
+		} {
+			assert.Equal(mt, want.first, trades[i].FirstId, i)
+			assert.Equal(mt, want.last, trades[i].LastId, i)
+			assert.Equal(mt, want.count, trades[i].Count, i)
+			assert.Equal(mt, want.qty, trades[i].Qty.String(), i)
+		}
+
+		cmd := mt.GetStartedEvent().Command
+		period := cmd.Lookup("filter", "t").Document()
+		assert.Equal(mt, start, period.Lookup("$gte").Time().UTC())
+		assert.Equal(mt, end, period.Lookup("$lte").Time().UTC())
+		assert.Equal(mt, int64(aggTradesBatchSize), cmd.Lookup("limit").Int64())
+		assert.Equal(mt, int32(1), cmd.Lookup("sort", "t").Int32())
+	})
+	mt.Run("from id", func(mt *mtest.T) {
+		s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+		from := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+				storedTrade(from, "7", start, "10", false),
+			),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+				storedTrade(from, "7", start, "10", false),
+				storedTrade(primitive.NewObjectID(), "8", start.Add(ms), "10", false),
+			),
+		)
+
+		trades, err := s.GetAggTrades(context.Background(), model.TradesQuery{Market: "ETH_BTC", Limit: 10}, "7")
+		require.NoError(mt, err)
+		require.Len(mt, trades, 1)
+		assert.Equal(mt, "7", trades[0].FirstId)
+		assert.Equal(mt, "8", trades[0].LastId)
+
+		assert.Equal(mt, "7", mt.GetStartedEvent().Command.Lookup("filter", "data.dealid").StringValue())
+		cmd := mt.GetStartedEvent().Command
+		or, err := cmd.Lookup("filter", "$or").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, or, 2)
+		assert.Equal(mt, from, or[1].Document().Lookup("_id", "$gte").ObjectID())
+		assert.Equal(mt, int32(1), cmd.Lookup("sort", "t").Int32())
+	})
+	mt.Run("from id of another market", func(mt *mtest.T) {
+		s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			storedTrade(primitive.NewObjectID(), "7", start, "10", false),
+		))
+
+		_, err := s.GetAggTrades(context.Background(), model.TradesQuery{Market: "BTC_USDT", Limit: 10}, "7")
+		assert.ErrorIs(mt, err, ErrTradeNotFound)
+	})
+	mt.Run("pages after the last deal of a batch", func(mt *mtest.T) {
+		s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+		batch := make([]bson.D, 0, aggTradesBatchSize)
+		var last primitive.ObjectID
+		for i := 0; i < aggTradesBatchSize; i++ {
+			last = primitive.NewObjectID()
+			batch = append(batch, storedTrade(last, strconv.Itoa(i), start.Add(time.Duration(i)*ms), strconv.Itoa(10+i%2), false))
+		}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, batch...),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+				storedTrade(primitive.NewObjectID(), "a", start.Add(aggTradesBatchSize*ms), "11", false),
+			),
+		)
+
+		trades, err := s.GetAggTrades(context.Background(), model.TradesQuery{
+			Market:    "ETH_BTC",
+			StartTime: start,
+			Limit:     aggTradesBatchSize,
+		}, "")
+		require.NoError(mt, err)
+		require.Len(mt, trades, aggTradesBatchSize)
+		// the last deal of the first batch is continued by the next one
+		assert.Equal(mt, strconv.Itoa(aggTradesBatchSize-1), trades[aggTradesBatchSize-1].FirstId)
+		assert.Equal(mt, "a", trades[aggTradesBatchSize-1].LastId)
+
+		mt.GetStartedEvent()
+		or, err := mt.GetStartedEvent().Command.Lookup("filter", "$or").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, or, 2)
+		assert.Equal(mt, last, or[1].Document().Lookup("_id", "$gt").ObjectID())
+	})
+	mt.Run("limit reached in the first batch", func(mt *mtest.T) {
+		s := &Deal{under: &repository.Deal{DbCollection: mt.Coll}}
+		batch := make([]bson.D, 0, aggTradesBatchSize)
+		for i := 0; i < aggTradesBatchSize; i++ {
+			batch = append(batch, storedTrade(primitive.NewObjectID(), strconv.Itoa(i), start.Add(time.Duration(i)*ms), strconv.Itoa(10+i%2), false))
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, batch...))
+
+		trades, err := s.GetAggTrades(context.Background(), model.TradesQuery{
+			Market:    "ETH_BTC",
+			StartTime: start,
+			Limit:     3,
+		}, "")
+		require.NoError(mt, err)
+		require.Len(mt, trades, 3)
+		assert.Equal(mt, "2", trades[2].FirstId)
+
+		mt.GetStartedEvent()
+		assert.Nil(mt, mt.GetStartedEvent())
+	})
+}
@@ -1,22 +1,240 @@
 package service
 
 import (
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
 	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
 	"context"
+	"fmt"
+	"sort"
 	"time"
 )
 
+const (
+	// klineMaterializeLag is how long the deals of a minute may be late.
+	klineMaterializeLag      = time.Minute
+	klineMaterializeInterval = time.Minute
+	klineMaterializeChunk    = time.Hour
+)
+
 type Kline struct {
-	klineRps *repository.Kline
+	klineRps        *repository.Kline
+	materializedRps *repository.MaterializedKline
+	tickerCache     *consumer.Ticker
 }
 
 // NewKline instance of kline service
-func NewKline(repository *repository.Kline) *Kline {
-	return &Kline{klineRps: repository}
+func NewKline(
+	repository *repository.Kline,
+	materializedRepository *repository.MaterializedKline,
+	tickerCache *consumer.Ticker,
+) *Kline {
+	return &Kline{
+		klineRps:        repository,
+		materializedRps: materializedRepository,
+		tickerCache:     tickerCache,
+	}
 }
 
 // Get the klines via specific parameters
 func (s *Kline) Get(ctx context.Context, from, to time.Time) ([]*model.Kline, error) {
 	return s.klineRps.Get(ctx, from, to)
 }
+
+// GetKlines returns up to limit klines of the market at the resolution which
+// are opened in [start, end]. If start is zero, the latest klines opened
+// until end are returned, end is now if zero.
+func (s *Kline) GetKlines(
+	ctx context.Context,
+	market string,
+	resolution model.Resolution,
+	start, end time.Time,
+	limit int,
+) ([]*model.Kline, error) {
+	if limit <= 0 || limit > domain.KlinesMaxLimit {
+		return nil, fmt.Errorf("limit %d is out of range", limit)
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	aggregator := candle.Aggregator{}
+	openTime := func(t time.Time) time.Time {
+		return time.Unix(aggregator.GetResolutionStartTimestampByTime(resolution, t), 0).UTC()
+	}
+	lastOpen := openTime(end)
+	to := model.CalculateCloseTime(lastOpen, resolution).Add(time.Nanosecond)
+	var from time.Time
+	if start.IsZero() {
+		switch unit, size := model.GetResolution(resolution); unit {
+		case model.MonthUnit:
+			from = lastOpen.AddDate(0, -size*(limit-1), 0)
+		case model.WeekUnit:
+			from = lastOpen.AddDate(0, 0, -7*size*(limit-1))
+		default:
+			from = lastOpen.Add(-time.Duration(limit-1) * resolution.ToDuration(lastOpen.Month(), lastOpen.Year()))
+		}
+	} else {
+		from = openTime(start)
+		if from.Before(start) {
+			from = model.CalculateCloseTime(from, resolution).Add(time.Nanosecond)
+		}
+	}
+	if !from.Before(to) {
+		return []*model.Kline{}, nil
+	}
+	return s.klineRps.GetByResolution(ctx, market, resolution, from, to, int64(limit))
+}
+
+// RunMaterializer stores the klines of the closed minutes and hours every
+// minute until the context is done.
+func (s *Kline) RunMaterializer(ctx context.Context) {
+	ticker := time.NewTicker(klineMaterializeInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.Materialize(ctx, time.Now()); err != nil {
+			logger.FromContext(ctx).
+				WithField("err", err).
+				WithField("svc", "Kline").
+				Errorf("klines materialization error")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Materialize stores the klines of the minutes closed before now since the
+// watermark, or since the longest ticker window at the first run.
+func (s *Kline) Materialize(ctx context.Context, now time.Time) error {
+	until := now.Add(-klineMaterializeLag).Truncate(time.Minute)
+	from, err := s.materializedRps.Watermark(ctx)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		from = until.Add(-domain.MaxTickerWindow - time.Hour).Truncate(time.Hour)
+	}
+	for from.Before(until) {
+		to := from.Add(klineMaterializeChunk)
+		if to.After(until) {
+			to = until
+		}
+		klines, err := s.klineRps.GetByMarkets(ctx, nil, from, to)
+		if err != nil {
+			return err
+		}
+		if err = s.materializedRps.Save(ctx, model.MinuteUnit, klines); err != nil {
+			return err
+		}
+		if hFrom, hTo := from.Truncate(time.Hour), to.Truncate(time.Hour); hFrom.Before(hTo) {
+			if err = s.materializedRps.RollupHours(ctx, hFrom, hTo); err != nil {
+				return err
+			}
+		}
+		if err = s.materializedRps.SetWatermark(ctx, to); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// GetWindowTicker returns the tickers of the market or of all the markets if
+// the market is empty for the window until now. The materialized hours and
+// minutes are merged with the minutes aggregated from the latest deals.
+func (s *Kline) GetWindowTicker(
+	ctx context.Context,
+	market string,
+	window time.Duration,
+) ([]*domain.TickerPriceChangeStatistics, error) {
+	if window <= 0 || window > domain.MaxTickerWindow {
+		return nil, fmt.Errorf("window size %s is out of range", window)
+	}
+	var markets []string
+	if market != "" {
+		markets = []string{market}
+	}
+	now := time.Now()
+	start := now.Add(-window).Truncate(time.Minute)
+	watermark, err := s.materializedRps.Watermark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]*model.Kline, 0)
+	find := func(unit string, from, to time.Time) error {
+		if !from.Before(to) {
+			return nil
+		}
+		found, err := s.materializedRps.Find(ctx, unit, markets, from, to)
+		klines = append(klines, found...)
+		return err
+	}
+	tail := start
+	if watermark.After(start) {
+		tail = watermark
+		hFrom, hTo := start.Truncate(time.Hour), watermark.Truncate(time.Hour)
+		if hFrom.Before(start) {
+			hFrom = hFrom.Add(time.Hour)
+		}
+		if hFrom.Before(hTo) {
+			err = find(model.HourUnit, hFrom, hTo)
+			if err == nil {
+				err = find(model.MinuteUnit, start, hFrom)
+			}
+			if err == nil {
+				err = find(model.MinuteUnit, hTo, watermark)
+			}
+		} else {
+			err = find(model.MinuteUnit, start, watermark)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	recent, err := s.klineRps.GetByMarkets(ctx, markets, tail, now.Add(time.Minute).Truncate(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	klines = append(klines, recent...)
+	prevCloses, err := s.materializedRps.FindLastBefore(ctx, markets, start)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := map[string]*domain.RollingTicker{}
+	tickerOf := func(symbol string) *domain.RollingTicker {
+		t, ok := tickers[symbol]
+		if !ok {
+			t = domain.NewRollingTicker(symbol, window, s.tickerCache.Precision(symbol))
+			tickers[symbol] = t
+		}
+		return t
+	}
+	for _, k := range prevCloses {
+		tickerOf(k.Symbol).SetPrevClose(k.Last, k.Close)
+	}
+	for _, k := range klines {
+		if err = tickerOf(k.Symbol).AddKline(k); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*domain.TickerPriceChangeStatistics, 0, len(tickers))
+	for _, t := range tickers {
+		stat, ok := t.Statistics(now)
+		if !ok {
+			continue
+		}
+		result = append(result, stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func newTestKline(mt *mtest.T) *Kline {
+	return NewKline(
+		repository.NewKline(mt.DB.Collection("deals")),
+		repository.NewMaterializedKline(
+			mt.DB.Collection("minutes"),
+			mt.DB.Collection("hours"),
+			mt.DB.Collection("state"),
+		),
+		nil,
+	)
+}
+
+func watermarkResponse(until time.Time) bson.D {
+	return mtest.CreateCursorResponse(0, "foo.state", mtest.FirstBatch, bson.D{{"_id", "klines"}, {"until", until}})
+}
+
+func emptyCursor() bson.D {
+	return mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)
+}
+
+// command is a started command with the collection and the period it reads.
+type command struct {
+	name, collection string
+	from, to         time.Time
+}
+
+func startedCommands(mt *mtest.T) []command {
+	var commands []command
+	for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+		c := command{name: evt.CommandName}
+		c.collection, _ = evt.Command.Lookup(evt.CommandName).StringValueOK()
+		var period bson.RawValue
+		switch evt.CommandName {
+		case "find":
+			period = evt.Command.Lookup("filter", "openTime")
+		case "aggregate":
+			stages, err := evt.Command.Lookup("pipeline").Array().Values()
+			require.NoError(mt, err)
+			match := stages[0].Document().Lookup("$match").Document()
+			if period = match.Lookup("t"); period.Type == 0 {
+				period = match.Lookup("openTime")
+			}
+		}
+		if doc, ok := period.DocumentOK(); ok {
+			if gte, ok := doc.Lookup("$gte").TimeOK(); ok {
+				c.from = gte.UTC()
+			}
+			if lt, ok := doc.Lookup("$lt").TimeOK(); ok {
+				c.to = lt.UTC()
+			}
+		}
+		commands = append(commands, c)
+	}
+	return commands
+}
+
+func TestKline_Materialize(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	now := time.Date(2022, 5, 1, 12, 30, 20, 0, time.UTC)
+	until := time.Date(2022, 5, 1, 12, 29, 0, 0, time.UTC)
+
+	mt.Run("resumes from the watermark", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		watermark := time.Date(2022, 5, 1, 11, 50, 0, 0, time.UTC)
+		mt.AddMockResponses(
+			watermarkResponse(watermark),
+			emptyCursor(),
+			emptyCursor(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		require.NoError(mt, s.Materialize(context.Background(), now))
+
+		hour := time.Date(2022, 5, 1, 11, 0, 0, 0, time.UTC)
+		assert.Equal(mt, []command{
+			{name: "find", collection: "state"},
+			{name: "aggregate", collection: "deals", from: watermark, to: until},
+			{name: "aggregate", collection: "minutes", from: hour, to: hour.Add(time.Hour)},
+			{name: "update", collection: "state"},
+		}, startedCommands(mt))
+	})
+	mt.Run("up to date", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		mt.AddMockResponses(watermarkResponse(until))
+
+		require.NoError(mt, s.Materialize(context.Background(), now))
+
+		assert.Equal(mt, []command{{name: "find", collection: "state"}}, startedCommands(mt))
+	})
+}
+
+func TestKline_RunMaterializer(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("materializes before waiting", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		mt.AddMockResponses(watermarkResponse(time.Now().Add(time.Hour)))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.RunMaterializer(ctx)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			mt.Fatal("the materializer isn't stopped")
+		}
+		assert.Equal(mt, []command{{name: "find", collection: "state"}}, startedCommands(mt))
+	})
+}
+
+func TestKline_GetWindowTicker(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	window := 24 * time.Hour
+	mt.Run("window out of range", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		for _, w := range []time.Duration{0, domain.MaxTickerWindow + time.Minute} {
+			_, err := s.GetWindowTicker(context.Background(), "ETH_BTC", w)
+			assert.Error(mt, err, w)
+		}
+		assert.Empty(mt, startedCommands(mt))
+	})
+	mt.Run("nothing materialized", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		mt.AddMockResponses(emptyCursor(), emptyCursor(), emptyCursor())
+
+		before := time.Now()
+		tickers, err := s.GetWindowTicker(context.Background(), "ETH_BTC", window)
+		after := time.Now()
+		require.NoError(mt, err)
+		assert.Empty(mt, tickers)
+
+		commands := startedCommands(mt)
+		require.Len(mt, commands, 3)
+		start := commands[2].to
+		assert.False(mt, start.Before(before.Add(-window).Truncate(time.Minute)))
+		assert.False(mt, start.After(after.Add(-window).Truncate(time.Minute)))
+		assert.Equal(mt, command{name: "aggregate", collection: "deals", from: start, to: after.UTC().Add(time.Minute).Truncate(time.Minute)}, commands[1])
+		assert.Equal(mt, command{name: "aggregate", collection: "minutes", to: start}, commands[2])
+	})
+	mt.Run("hours between the minutes", func(mt *mtest.T) {
+		s := newTestKline(mt)
+		watermark := time.Now().UTC().Truncate(time.Minute).Add(-5 * time.Minute)
+		mt.AddMockResponses(watermarkResponse(watermark))
+		for i := 0; i < 5; i++ {
+			mt.AddMockResponses(emptyCursor())
+		}
+
+		tickers, err := s.GetWindowTicker(context.Background(), "ETH_BTC", window)
+		require.NoError(mt, err)
+		assert.Empty(mt, tickers)
+
+		commands := startedCommands(mt)
+		last := commands[len(commands)-1]
+		require.Equal(mt, "minutes", last.collection)
+		start := last.to
+		hFrom, hTo := start.Truncate(time.Hour), watermark.Truncate(time.Hour)
+		if hFrom.Before(start) {
+			hFrom = hFrom.Add(time.Hour)
+		}
+		want := []command{
+			{name: "find", collection: "state"},
+			{name: "find", collection: "hours", from: hFrom, to: hTo},
+		}
+		if start.Before(hFrom) {
+			want = append(want, command{name: "find", collection: "minutes", from: start, to: hFrom})
+		}
+		want = append(want, command{name: "find", collection: "minutes", from: hTo, to: watermark})
+		require.Len(mt, commands, len(want)+2)
+		assert.Equal(mt, want, commands[:len(want)])
+		recent := commands[len(want)]
+		assert.Equal(mt, "deals", recent.collection)
+		assert.Equal(mt, watermark, recent.from)
+	})
+}
+
+func TestKline_GetKlines(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2022, month, day, hour, min, 0, 0, time.UTC)
+	}
+	for _, tt := range []struct {
+		name       string
+		interval   string
+		start, end time.Time
+		limit      int
+		wantErr    bool
+		// wantFrom and wantTo are zero if nothing is fetched.
+		wantFrom, wantTo time.Time
+		wantUnit         string
+		wantBinSize      int32
+	}{
+		{
+			name:     "start and end inside the klines",
+			interval: "30m",
+			start:    at(5, 1, 10, 7),
+			end:      at(5, 1, 12, 45),
+			limit:    500,
+			wantFrom: at(5, 1, 10, 30),
+			wantTo:   at(5, 1, 13, 0),
+			wantUnit: "minute", wantBinSize: 30,
+		},
+		{
+			name:     "start at an open time",
+			interval: "30m",
+			start:    at(5, 1, 10, 0),
+			end:      at(5, 1, 12, 30),
+			limit:    500,
+			wantFrom: at(5, 1, 10, 0),
+			wantTo:   at(5, 1, 13, 0),
+			wantUnit: "minute", wantBinSize: 30,
+		},
+		{
+			name:     "latest klines until end",
+			interval: "30m",
+			end:      at(5, 1, 12, 45),
+			limit:    3,
+			wantFrom: at(5, 1, 11, 30),
+			wantTo:   at(5, 1, 13, 0),
+			wantUnit: "minute", wantBinSize: 30,
+		},
+		{
+			name:     "latest months",
+			interval: "1M",
+			end:      at(5, 15, 12, 0),
+			limit:    3,
+			wantFrom: at(3, 1, 0, 0),
+			wantTo:   at(6, 1, 0, 0),
+			wantUnit: "month", wantBinSize: 1,
+		},
+		{
+			name:     "start in the last kline",
+			interval: "30m",
+			start:    at(5, 1, 12, 50),
+			end:      at(5, 1, 12, 55),
+			limit:    500,
+		},
+		{name: "zero limit", interval: "30m", limit: 0, wantErr: true},
+		{name: "limit above the max", interval: "30m", limit: 1001, wantErr: true},
+	} {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			s := newTestKline(mt)
+			mt.AddMockResponses(emptyCursor())
+			resolution, err := domain.ParseKlineInterval(tt.interval)
+			require.NoError(mt, err)
+
+			klines, err := s.GetKlines(context.Background(), "ETH_BTC", resolution, tt.start, tt.end, tt.limit)
+			if tt.wantErr {
+				assert.Error(mt, err)
+				assert.Nil(mt, mt.GetStartedEvent())
+				return
+			}
+			require.NoError(mt, err)
+			assert.Empty(mt, klines)
+
+			evt := mt.GetStartedEvent()
+			if tt.wantTo.IsZero() {
+				assert.Nil(mt, evt)
+				return
+			}
+			require.NotNil(mt, evt)
+			stages, err := evt.Command.Lookup("pipeline").Array().Values()
+			require.NoError(mt, err)
+			period := stages[0].Document().Lookup("$match", "t").Document()
+			assert.Equal(mt, tt.wantFrom, period.Lookup("$gte").Time().UTC())
+			assert.Equal(mt, tt.wantTo, period.Lookup("$lt").Time().UTC())
+			assert.Equal(mt, "ETH_BTC", stages[0].Document().Lookup("$match", "data.market").StringValue())
+
+			trunc := stages[2].Document().Lookup("$group", "_id", "openTime", "$dateTrunc").Document()
+			assert.Equal(mt, tt.wantUnit, trunc.Lookup("unit").StringValue())
+			assert.Equal(mt, tt.wantBinSize, trunc.Lookup("binSize").Int32())
+
+			limit := stages[len(stages)-1].Document().Lookup("$limit")
+			assert.Equal(mt, int64(tt.limit), limit.Int64())
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/interfaces/matcher"
+
+	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/decimal128"
+	"bitbucket.org/novatechnologies/ohlcv/internal/livestate"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+)
+
+// errReplayGap fails the restore of a market whose deals can't be replayed.
+var errReplayGap = errors.New("deals can't be replayed")
+
+// LiveState saves the current candles and the tickers into the snapshot and
+// restores them on start, so only the deals made since the snapshot are
+// replayed instead of rebuilding the live state from the storage.
+type LiveState struct {
+	snapshot livestate.Snapshot
+	deals    *Deal
+	markets  *domain.MarketRegistry
+	maxAge   time.Duration
+}
+
+// NewLiveState returns the live state of the markets, the states older than
+// the max age aren't restored.
+func NewLiveState(snapshot livestate.Snapshot, deals *Deal, markets *domain.MarketRegistry, maxAge time.Duration) *LiveState {
+	return &LiveState{snapshot: snapshot, deals: deals, markets: markets, maxAge: maxAge}
+}
+
+// Run saves the live state every interval until the context is done.
+func (s *LiveState) Run(ctx context.Context, candles candle.CurrentCandles, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Save(ctx, candles); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Errorf("can't save live state")
+		}
+	}
+}
+
+// Save saves the current candles and the tickers of the markets kept by the
+// instance.
+func (s *LiveState) Save(ctx context.Context, candles candle.CurrentCandles) error {
+	savedAt := time.Now()
+	markets := s.markets.Markets()
+	states := make([]livestate.MarketState, 0, len(markets))
+	for _, m := range markets {
+		state := livestate.MarketState{Market: m.ID, SavedAt: savedAt}
+		if marketCandles, ok := candles.State(m.ID); ok {
+			state.Candles = &marketCandles
+			if state.Candles.Position.T.IsZero() {
+				// no deals are applied, the later ones are replayed
+				state.Candles.Position.T = savedAt.Truncate(time.Millisecond)
+			}
+		}
+		if marketTicker, ok := s.deals.tickerCache.State(m.Name); ok {
+			state.Ticker = &marketTicker
+			if state.Ticker.Position.T.IsZero() {
+				state.Ticker.Position.T = savedAt.Truncate(time.Millisecond)
+			}
+		}
+		if state.Candles != nil || state.Ticker != nil {
+			states = append(states, state)
+		}
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	if err := s.snapshot.Save(ctx, states); err != nil {
+		return fmt.Errorf("can't save live state snapshot: %w", err)
+	}
+	return nil
+}
+
+// RestoreTickers restores the tickers of the market names and adds the deals
+// made since their positions. It returns the names of the markets which
+// aren't restored, their tickers should be loaded from the storage.
+func (s *LiveState) RestoreTickers(ctx context.Context, names []string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if id, ok := s.markets.ID(name); ok {
+			ids = append(ids, id)
+		}
+	}
+	states, err := s.load(ctx, ids)
+	if err != nil {
+		return names, err
+	}
+	var rest []string
+	for _, name := range names {
+		id, _ := s.markets.ID(name)
+		state, ok := states[id]
+		if !ok || state.Ticker == nil {
+			rest = append(rest, name)
+			continue
+		}
+		if err := s.restoreTicker(ctx, name, *state.Ticker); err != nil {
+			logger.FromContext(ctx).WithField("err", err).WithField("market", name).Errorf("can't restore ticker")
+			s.deals.tickerCache.RemoveMarket(name)
+			rest = append(rest, name)
+		}
+	}
+	return rest, nil
+}
+
+func (s *LiveState) restoreTicker(ctx context.Context, name string, state consumer.MarketTicker) error {
+	if err := s.deals.tickerCache.Restore(name, state); err != nil {
+		return err
+	}
+	return s.replay(ctx, name, state.Position, func(deal *model.Deal) error {
+		s.deals.addTickerDeal(ctx, deal)
+		return nil
+	})
+}
+
+// RestoreCandles restores the current candles of the markets and applies the
+// deals made since their positions. It returns the markets which aren't
+// restored, their candles should be built from the storage.
+func (s *LiveState) RestoreCandles(ctx context.Context, candles candle.CurrentCandles, markets []market.Market) ([]market.Market, error) {
+	ids := make([]string, 0, len(markets))
+	for _, m := range markets {
+		ids = append(ids, m.ID)
+	}
+	states, err := s.load(ctx, ids)
+	if err != nil {
+		return markets, err
+	}
+	var rest []market.Market
+	for _, m := range markets {
+		state, ok := states[m.ID]
+		if !ok || state.Candles == nil {
+			rest = append(rest, m)
+			continue
+		}
+		candles.Restore(m.ID, *state.Candles)
+		err := s.replay(ctx, m.Name, state.Candles.Position, func(deal *model.Deal) error {
+			return candles.AddDeal(ctx, toMatcherDeal(deal, m.ID))
+		})
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).WithField("market", m.Name).Errorf("can't restore current candles")
+			candles.RemoveMarket(m.ID)
+			rest = append(rest, m)
+		}
+	}
+	return rest, nil
+}
+
+// load returns the states of the market ids which aren't older than the max
+// age.
+func (s *LiveState) load(ctx context.Context, ids []string) (map[string]livestate.MarketState, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	loaded, err := s.snapshot.Load(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("can't load live state snapshot: %w", err)
+	}
+	now := time.Now()
+	states := make(map[string]livestate.MarketState, len(loaded))
+	for _, state := range loaded {
+		if now.Sub(state.SavedAt) > s.maxAge {
+			continue
+		}
+		states[state.Market] = state
+	}
+	return states, nil
+}
+
+// replay sends the stored deals of the market name made since the position.
+func (s *LiveState) replay(ctx context.Context, name string, position model.DealPosition, onDeal func(*model.Deal) error) error {
+	sent := make(map[string]struct{}, len(position.IDs))
+	for _, id := range position.IDs {
+		sent[id] = struct{}{}
+	}
+	return s.deals.replayDealsSince(
+		ctx,
+		[]string{name},
+		position.T,
+		sent,
+		func(gap model.DealsGap) error {
+			return fmt.Errorf("%w from %s to %s: %s", errReplayGap, gap.From, gap.To, gap.Reason)
+		},
+		onDeal,
+	)
+}
+
+func toMatcherDeal(deal *model.Deal, market string) *matcher.Deal {
+	return &matcher.Deal{
+		Id:           deal.Data.DealId,
+		Market:       market,
+		Price:        decimal128.String(deal.Data.Price),
+		Amount:       decimal128.String(deal.Data.Volume),
+		CreatedAt:    deal.T.Time().UnixNano(),
+		IsBuyerMaker: deal.Data.IsBuyerMaker,
+	}
+}
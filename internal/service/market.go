@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"bitbucket.org/novatechnologies/common/infra/logger"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
+	"bitbucket.org/novatechnologies/ohlcv/domain"
+	"bitbucket.org/novatechnologies/ohlcv/infra/metrics"
+)
+
+// MarketSyncInterval is how often the market registry is synchronized with
+// the market client.
+const MarketSyncInterval = 30 * time.Second
+
+// RunMarketSync refreshes the market cache and updates the registry from it
+// every interval until the context is done. The registry keeps the last good
+// list while the refreshes fail. Only the leader requests the markets, the
+// others follow the snapshot it saves.
+func RunMarketSync(
+	ctx context.Context,
+	registry *domain.MarketRegistry,
+	cache *market.Cache,
+	interval time.Duration,
+	leading func() bool,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		metrics.MarketsRefreshedAt.Set(float64(cache.RefreshedAt().Unix()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		refresh := cache.Follow
+		if leading() {
+			refresh = cache.Refresh
+		}
+		if err := refresh(ctx); err != nil {
+			logger.FromContext(ctx).
+				WithField("err", err).
+				WithField("age", cache.Age(time.Now()).String()).
+				WithField("svc", "MarketSync").
+				Errorf("can't refresh markets")
+		}
+		markets, err := cache.List(ctx)
+		if err != nil {
+			continue
+		}
+		for _, e := range registry.Update(markets) {
+			logger.FromContext(ctx).
+				WithField("market", e.Market.Name).
+				WithField("id", e.Market.ID).
+				WithField("oldName", e.OldName).
+				Infof("market %s", e.Type)
+		}
+	}
+}
@@ -21,10 +21,73 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type CandleEvent int32
+
+const (
+	// current state of the candle sent right after subscribing
+	CandleEvent_CANDLE_SNAPSHOT CandleEvent = 0
+	// the candle got a new deal or was opened
+	CandleEvent_CANDLE_UPDATE CandleEvent = 1
+	// the candle period is over, it won't be changed anymore
+	CandleEvent_CANDLE_CLOSED CandleEvent = 2
+)
+
+// Enum value maps for CandleEvent.
+var (
+	CandleEvent_name = map[int32]string{
+		0: "CANDLE_SNAPSHOT",
+		1: "CANDLE_UPDATE",
+		2: "CANDLE_CLOSED",
+	}
+	CandleEvent_value = map[string]int32{
+		"CANDLE_SNAPSHOT": 0,
+		"CANDLE_UPDATE":   1,
+		"CANDLE_CLOSED":   2,
+	}
+)
+
+func (x CandleEvent) Enum() *CandleEvent {
+	p := new(CandleEvent)
+	*p = x
+	return p
+}
+
+func (x CandleEvent) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CandleEvent) Descriptor() protoreflect.EnumDescriptor {
+	return file_ohlcv_proto_enumTypes[0].Descriptor()
+}
+
+func (CandleEvent) Type() protoreflect.EnumType {
+	return &file_ohlcv_proto_enumTypes[0]
+}
+
+func (x CandleEvent) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CandleEvent.Descriptor instead.
+func (CandleEvent) EnumDescriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{0}
+}
+
+// SubscribeDealsRequest selects live deals by market names (e.g. BTC_USDT),
+// empty list means "all". When lastDealId or since is set, the deals made
+// after it are replayed from storage before the live ones.
+// backpressure overrides the server policy for a slow subscriber: drop-newest,
+// drop-oldest or disconnect (after too many drops), block is set by the server
+// only.
 type SubscribeDealsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Markets      []string               `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
+	LastDealId   string                 `protobuf:"bytes,2,opt,name=lastDealId,proto3" json:"lastDealId,omitempty"`
+	Since        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Backpressure string                 `protobuf:"bytes,4,opt,name=backpressure,proto3" json:"backpressure,omitempty"`
 }
 
 func (x *SubscribeDealsRequest) Reset() {
@@ -59,6 +122,34 @@ func (*SubscribeDealsRequest) Descriptor() ([]byte, []int) {
 	return file_ohlcv_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *SubscribeDealsRequest) GetMarkets() []string {
+	if x != nil {
+		return x.Markets
+	}
+	return nil
+}
+
+func (x *SubscribeDealsRequest) GetLastDealId() string {
+	if x != nil {
+		return x.LastDealId
+	}
+	return ""
+}
+
+func (x *SubscribeDealsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *SubscribeDealsRequest) GetBackpressure() string {
+	if x != nil {
+		return x.Backpressure
+	}
+	return ""
+}
+
 type SubscribeDealsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -70,6 +161,9 @@ type SubscribeDealsResponse struct {
 	Symbol       string                 `protobuf:"bytes,4,opt,name=symbol,proto3" json:"symbol,omitempty"`
 	DealId       string                 `protobuf:"bytes,5,opt,name=dealId,proto3" json:"dealId,omitempty"`
 	IsBuyerMaker bool                   `protobuf:"varint,6,opt,name=isBuyerMaker,proto3" json:"isBuyerMaker,omitempty"`
+	// gap is set instead of the deal fields when deals of the period can't be
+	// delivered to the subscriber
+	Gap *DealsGap `protobuf:"bytes,7,opt,name=gap,proto3" json:"gap,omitempty"`
 }
 
 func (x *SubscribeDealsResponse) Reset() {
@@ -146,6 +240,85 @@ func (x *SubscribeDealsResponse) GetIsBuyerMaker() bool {
 	return false
 }
 
+func (x *SubscribeDealsResponse) GetGap() *DealsGap {
+	if x != nil {
+		return x.Gap
+	}
+	return nil
+}
+
+type DealsGap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Reason string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// dropped is a number of deals lost in the period, if known
+	Dropped uint64 `protobuf:"varint,4,opt,name=dropped,proto3" json:"dropped,omitempty"`
+}
+
+func (x *DealsGap) Reset() {
+	*x = DealsGap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DealsGap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealsGap) ProtoMessage() {}
+
+func (x *DealsGap) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealsGap.ProtoReflect.Descriptor instead.
+func (*DealsGap) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DealsGap) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *DealsGap) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *DealsGap) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DealsGap) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
 type GenerateMinuteCandlesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -158,7 +331,7 @@ type GenerateMinuteCandlesRequest struct {
 func (x *GenerateMinuteCandlesRequest) Reset() {
 	*x = GenerateMinuteCandlesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[2]
+		mi := &file_ohlcv_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -171,7 +344,7 @@ func (x *GenerateMinuteCandlesRequest) String() string {
 func (*GenerateMinuteCandlesRequest) ProtoMessage() {}
 
 func (x *GenerateMinuteCandlesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[2]
+	mi := &file_ohlcv_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -184,7 +357,7 @@ func (x *GenerateMinuteCandlesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GenerateMinuteCandlesRequest.ProtoReflect.Descriptor instead.
 func (*GenerateMinuteCandlesRequest) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{2}
+	return file_ohlcv_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GenerateMinuteCandlesRequest) GetFrom() *timestamppb.Timestamp {
@@ -218,7 +391,7 @@ type Candle struct {
 func (x *Candle) Reset() {
 	*x = Candle{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[3]
+		mi := &file_ohlcv_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -231,7 +404,7 @@ func (x *Candle) String() string {
 func (*Candle) ProtoMessage() {}
 
 func (x *Candle) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[3]
+	mi := &file_ohlcv_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,7 +417,7 @@ func (x *Candle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Candle.ProtoReflect.Descriptor instead.
 func (*Candle) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{3}
+	return file_ohlcv_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Candle) GetOpenTime() *timestamppb.Timestamp {
@@ -307,7 +480,7 @@ type GenerateMinuteCandlesResponse struct {
 func (x *GenerateMinuteCandlesResponse) Reset() {
 	*x = GenerateMinuteCandlesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[4]
+		mi := &file_ohlcv_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -320,7 +493,7 @@ func (x *GenerateMinuteCandlesResponse) String() string {
 func (*GenerateMinuteCandlesResponse) ProtoMessage() {}
 
 func (x *GenerateMinuteCandlesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[4]
+	mi := &file_ohlcv_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -333,7 +506,7 @@ func (x *GenerateMinuteCandlesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GenerateMinuteCandlesResponse.ProtoReflect.Descriptor instead.
 func (*GenerateMinuteCandlesResponse) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{4}
+	return file_ohlcv_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GenerateMinuteCandlesResponse) GetCandles() []*Candle {
@@ -355,7 +528,7 @@ type GenerateMinuteKlinesRequest struct {
 func (x *GenerateMinuteKlinesRequest) Reset() {
 	*x = GenerateMinuteKlinesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[5]
+		mi := &file_ohlcv_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -368,7 +541,7 @@ func (x *GenerateMinuteKlinesRequest) String() string {
 func (*GenerateMinuteKlinesRequest) ProtoMessage() {}
 
 func (x *GenerateMinuteKlinesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[5]
+	mi := &file_ohlcv_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +554,7 @@ func (x *GenerateMinuteKlinesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GenerateMinuteKlinesRequest.ProtoReflect.Descriptor instead.
 func (*GenerateMinuteKlinesRequest) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{5}
+	return file_ohlcv_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GenerateMinuteKlinesRequest) GetFrom() *timestamppb.Timestamp {
@@ -417,12 +590,16 @@ type Kline struct {
 	Symbol      string                 `protobuf:"bytes,12,opt,name=symbol,proto3" json:"symbol,omitempty"`
 	First       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=first,proto3" json:"first,omitempty"`
 	Last        *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=last,proto3" json:"last,omitempty"`
+	// takerBuyAssets and takerBuyQuotes are the volumes of the deals whose
+	// buyer is the taker, takerAssets and takerQuotes are of the other ones.
+	TakerBuyAssets string `protobuf:"bytes,15,opt,name=takerBuyAssets,proto3" json:"takerBuyAssets,omitempty"`
+	TakerBuyQuotes string `protobuf:"bytes,16,opt,name=takerBuyQuotes,proto3" json:"takerBuyQuotes,omitempty"`
 }
 
 func (x *Kline) Reset() {
 	*x = Kline{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[6]
+		mi := &file_ohlcv_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -435,7 +612,7 @@ func (x *Kline) String() string {
 func (*Kline) ProtoMessage() {}
 
 func (x *Kline) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[6]
+	mi := &file_ohlcv_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -448,7 +625,7 @@ func (x *Kline) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Kline.ProtoReflect.Descriptor instead.
 func (*Kline) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{6}
+	return file_ohlcv_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Kline) GetOpenTime() *timestamppb.Timestamp {
@@ -549,6 +726,20 @@ func (x *Kline) GetLast() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Kline) GetTakerBuyAssets() string {
+	if x != nil {
+		return x.TakerBuyAssets
+	}
+	return ""
+}
+
+func (x *Kline) GetTakerBuyQuotes() string {
+	if x != nil {
+		return x.TakerBuyQuotes
+	}
+	return ""
+}
+
 type GenerateMinuteKlinesResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -560,7 +751,7 @@ type GenerateMinuteKlinesResponse struct {
 func (x *GenerateMinuteKlinesResponse) Reset() {
 	*x = GenerateMinuteKlinesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[7]
+		mi := &file_ohlcv_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -573,7 +764,7 @@ func (x *GenerateMinuteKlinesResponse) String() string {
 func (*GenerateMinuteKlinesResponse) ProtoMessage() {}
 
 func (x *GenerateMinuteKlinesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[7]
+	mi := &file_ohlcv_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -586,7 +777,7 @@ func (x *GenerateMinuteKlinesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GenerateMinuteKlinesResponse.ProtoReflect.Descriptor instead.
 func (*GenerateMinuteKlinesResponse) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{7}
+	return file_ohlcv_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *GenerateMinuteKlinesResponse) GetKlines() []*Kline {
@@ -608,7 +799,7 @@ type GetLastTradesRequest struct {
 func (x *GetLastTradesRequest) Reset() {
 	*x = GetLastTradesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[8]
+		mi := &file_ohlcv_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -621,7 +812,7 @@ func (x *GetLastTradesRequest) String() string {
 func (*GetLastTradesRequest) ProtoMessage() {}
 
 func (x *GetLastTradesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[8]
+	mi := &file_ohlcv_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -634,7 +825,7 @@ func (x *GetLastTradesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLastTradesRequest.ProtoReflect.Descriptor instead.
 func (*GetLastTradesRequest) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{8}
+	return file_ohlcv_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetLastTradesRequest) GetSymbol() string {
@@ -668,7 +859,7 @@ type Trade struct {
 func (x *Trade) Reset() {
 	*x = Trade{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[9]
+		mi := &file_ohlcv_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -681,7 +872,7 @@ func (x *Trade) String() string {
 func (*Trade) ProtoMessage() {}
 
 func (x *Trade) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[9]
+	mi := &file_ohlcv_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -694,7 +885,7 @@ func (x *Trade) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Trade.ProtoReflect.Descriptor instead.
 func (*Trade) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{9}
+	return file_ohlcv_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Trade) GetId() string {
@@ -757,7 +948,7 @@ type GetLastTradesResponse struct {
 func (x *GetLastTradesResponse) Reset() {
 	*x = GetLastTradesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[10]
+		mi := &file_ohlcv_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -770,7 +961,7 @@ func (x *GetLastTradesResponse) String() string {
 func (*GetLastTradesResponse) ProtoMessage() {}
 
 func (x *GetLastTradesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[10]
+	mi := &file_ohlcv_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -783,7 +974,7 @@ func (x *GetLastTradesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLastTradesResponse.ProtoReflect.Descriptor instead.
 func (*GetLastTradesResponse) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{10}
+	return file_ohlcv_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetLastTradesResponse) GetTrades() []*Trade {
@@ -793,51 +984,40 @@ func (x *GetLastTradesResponse) GetTrades() []*Trade {
 	return nil
 }
 
-type Ticker struct {
+// GetHistoricalTradesRequest asks for the trades of the symbol oldest first
+// from the fromId trade, or after the cursor of the previous page, made in
+// [startTime, endTime]. The latest trades are returned if none of fromId,
+// cursor and startTime is set. limit is 500 by default and 1000 at most.
+type GetHistoricalTradesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Symbol             string `protobuf:"bytes,1,opt,name=Symbol,proto3" json:"Symbol,omitempty"`
-	PriceChange        string `protobuf:"bytes,2,opt,name=PriceChange,proto3" json:"PriceChange,omitempty"`
-	PriceChangePercent string `protobuf:"bytes,3,opt,name=PriceChangePercent,proto3" json:"PriceChangePercent,omitempty"`
-	WeightedAvgPrice   string `protobuf:"bytes,4,opt,name=WeightedAvgPrice,proto3" json:"WeightedAvgPrice,omitempty"`
-	PrevClosePrice     string `protobuf:"bytes,5,opt,name=PrevClosePrice,proto3" json:"PrevClosePrice,omitempty"`
-	LastPrice          string `protobuf:"bytes,6,opt,name=LastPrice,proto3" json:"LastPrice,omitempty"`
-	LastQty            string `protobuf:"bytes,7,opt,name=LastQty,proto3" json:"LastQty,omitempty"`
-	BidPrice           string `protobuf:"bytes,8,opt,name=BidPrice,proto3" json:"BidPrice,omitempty"`
-	BidQty             string `protobuf:"bytes,9,opt,name=BidQty,proto3" json:"BidQty,omitempty"`
-	AskPrice           string `protobuf:"bytes,10,opt,name=AskPrice,proto3" json:"AskPrice,omitempty"`
-	AskQty             string `protobuf:"bytes,11,opt,name=AskQty,proto3" json:"AskQty,omitempty"`
-	OpenPrice          string `protobuf:"bytes,12,opt,name=OpenPrice,proto3" json:"OpenPrice,omitempty"`
-	HighPrice          string `protobuf:"bytes,13,opt,name=HighPrice,proto3" json:"HighPrice,omitempty"`
-	LowPrice           string `protobuf:"bytes,14,opt,name=LowPrice,proto3" json:"LowPrice,omitempty"`
-	Volume             string `protobuf:"bytes,15,opt,name=Volume,proto3" json:"Volume,omitempty"`
-	QuoteVolume        string `protobuf:"bytes,16,opt,name=QuoteVolume,proto3" json:"QuoteVolume,omitempty"`
-	OpenTime           int64  `protobuf:"varint,17,opt,name=OpenTime,proto3" json:"OpenTime,omitempty"`
-	CloseTime          int64  `protobuf:"varint,18,opt,name=CloseTime,proto3" json:"CloseTime,omitempty"`
-	FirstId            string `protobuf:"bytes,19,opt,name=FirstId,proto3" json:"FirstId,omitempty"`
-	LastId             string `protobuf:"bytes,20,opt,name=LastId,proto3" json:"LastId,omitempty"`
-	Count              int32  `protobuf:"varint,21,opt,name=Count,proto3" json:"Count,omitempty"`
+	Symbol    string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Limit     int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	FromId    string                 `protobuf:"bytes,3,opt,name=fromId,proto3" json:"fromId,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	Cursor    string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
 }
 
-func (x *Ticker) Reset() {
-	*x = Ticker{}
+func (x *GetHistoricalTradesRequest) Reset() {
+	*x = GetHistoricalTradesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[11]
+		mi := &file_ohlcv_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Ticker) String() string {
+func (x *GetHistoricalTradesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ticker) ProtoMessage() {}
+func (*GetHistoricalTradesRequest) ProtoMessage() {}
 
-func (x *Ticker) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[11]
+func (x *GetHistoricalTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -848,183 +1028,917 @@ func (x *Ticker) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ticker.ProtoReflect.Descriptor instead.
-func (*Ticker) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use GetHistoricalTradesRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoricalTradesRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *Ticker) GetSymbol() string {
+func (x *GetHistoricalTradesRequest) GetSymbol() string {
 	if x != nil {
 		return x.Symbol
 	}
 	return ""
 }
 
-func (x *Ticker) GetPriceChange() string {
+func (x *GetHistoricalTradesRequest) GetLimit() int32 {
 	if x != nil {
-		return x.PriceChange
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-func (x *Ticker) GetPriceChangePercent() string {
+func (x *GetHistoricalTradesRequest) GetFromId() string {
 	if x != nil {
-		return x.PriceChangePercent
+		return x.FromId
 	}
 	return ""
 }
 
-func (x *Ticker) GetWeightedAvgPrice() string {
+func (x *GetHistoricalTradesRequest) GetStartTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.WeightedAvgPrice
+		return x.StartTime
 	}
-	return ""
+	return nil
 }
 
-func (x *Ticker) GetPrevClosePrice() string {
+func (x *GetHistoricalTradesRequest) GetEndTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.PrevClosePrice
+		return x.EndTime
 	}
-	return ""
+	return nil
 }
 
-func (x *Ticker) GetLastPrice() string {
+func (x *GetHistoricalTradesRequest) GetCursor() string {
 	if x != nil {
-		return x.LastPrice
+		return x.Cursor
 	}
 	return ""
 }
 
-func (x *Ticker) GetLastQty() string {
-	if x != nil {
-		return x.LastQty
-	}
-	return ""
+// GetHistoricalTradesResponse is a page of trades, nextCursor is as same as
+// the request one if the page is empty.
+type GetHistoricalTradesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Trades     []*Trade `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	NextCursor string   `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"`
 }
 
-func (x *Ticker) GetBidPrice() string {
-	if x != nil {
-		return x.BidPrice
+func (x *GetHistoricalTradesResponse) Reset() {
+	*x = GetHistoricalTradesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *Ticker) GetBidQty() string {
-	if x != nil {
-		return x.BidQty
-	}
-	return ""
+func (x *GetHistoricalTradesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Ticker) GetAskPrice() string {
-	if x != nil {
-		return x.AskPrice
+func (*GetHistoricalTradesResponse) ProtoMessage() {}
+
+func (x *GetHistoricalTradesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Ticker) GetAskQty() string {
-	if x != nil {
+// Deprecated: Use GetHistoricalTradesResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoricalTradesResponse) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetHistoricalTradesResponse) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+func (x *GetHistoricalTradesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type Ticker struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Symbol             string `protobuf:"bytes,1,opt,name=Symbol,proto3" json:"Symbol,omitempty"`
+	PriceChange        string `protobuf:"bytes,2,opt,name=PriceChange,proto3" json:"PriceChange,omitempty"`
+	PriceChangePercent string `protobuf:"bytes,3,opt,name=PriceChangePercent,proto3" json:"PriceChangePercent,omitempty"`
+	WeightedAvgPrice   string `protobuf:"bytes,4,opt,name=WeightedAvgPrice,proto3" json:"WeightedAvgPrice,omitempty"`
+	PrevClosePrice     string `protobuf:"bytes,5,opt,name=PrevClosePrice,proto3" json:"PrevClosePrice,omitempty"`
+	LastPrice          string `protobuf:"bytes,6,opt,name=LastPrice,proto3" json:"LastPrice,omitempty"`
+	LastQty            string `protobuf:"bytes,7,opt,name=LastQty,proto3" json:"LastQty,omitempty"`
+	BidPrice           string `protobuf:"bytes,8,opt,name=BidPrice,proto3" json:"BidPrice,omitempty"`
+	BidQty             string `protobuf:"bytes,9,opt,name=BidQty,proto3" json:"BidQty,omitempty"`
+	AskPrice           string `protobuf:"bytes,10,opt,name=AskPrice,proto3" json:"AskPrice,omitempty"`
+	AskQty             string `protobuf:"bytes,11,opt,name=AskQty,proto3" json:"AskQty,omitempty"`
+	OpenPrice          string `protobuf:"bytes,12,opt,name=OpenPrice,proto3" json:"OpenPrice,omitempty"`
+	HighPrice          string `protobuf:"bytes,13,opt,name=HighPrice,proto3" json:"HighPrice,omitempty"`
+	LowPrice           string `protobuf:"bytes,14,opt,name=LowPrice,proto3" json:"LowPrice,omitempty"`
+	Volume             string `protobuf:"bytes,15,opt,name=Volume,proto3" json:"Volume,omitempty"`
+	QuoteVolume        string `protobuf:"bytes,16,opt,name=QuoteVolume,proto3" json:"QuoteVolume,omitempty"`
+	OpenTime           int64  `protobuf:"varint,17,opt,name=OpenTime,proto3" json:"OpenTime,omitempty"`
+	CloseTime          int64  `protobuf:"varint,18,opt,name=CloseTime,proto3" json:"CloseTime,omitempty"`
+	FirstId            string `protobuf:"bytes,19,opt,name=FirstId,proto3" json:"FirstId,omitempty"`
+	LastId             string `protobuf:"bytes,20,opt,name=LastId,proto3" json:"LastId,omitempty"`
+	Count              int32  `protobuf:"varint,21,opt,name=Count,proto3" json:"Count,omitempty"`
+}
+
+func (x *Ticker) Reset() {
+	*x = Ticker{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ticker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticker) ProtoMessage() {}
+
+func (x *Ticker) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticker.ProtoReflect.Descriptor instead.
+func (*Ticker) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Ticker) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Ticker) GetPriceChange() string {
+	if x != nil {
+		return x.PriceChange
+	}
+	return ""
+}
+
+func (x *Ticker) GetPriceChangePercent() string {
+	if x != nil {
+		return x.PriceChangePercent
+	}
+	return ""
+}
+
+func (x *Ticker) GetWeightedAvgPrice() string {
+	if x != nil {
+		return x.WeightedAvgPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetPrevClosePrice() string {
+	if x != nil {
+		return x.PrevClosePrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetLastPrice() string {
+	if x != nil {
+		return x.LastPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetLastQty() string {
+	if x != nil {
+		return x.LastQty
+	}
+	return ""
+}
+
+func (x *Ticker) GetBidPrice() string {
+	if x != nil {
+		return x.BidPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetBidQty() string {
+	if x != nil {
+		return x.BidQty
+	}
+	return ""
+}
+
+func (x *Ticker) GetAskPrice() string {
+	if x != nil {
+		return x.AskPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetAskQty() string {
+	if x != nil {
 		return x.AskQty
 	}
-	return ""
+	return ""
+}
+
+func (x *Ticker) GetOpenPrice() string {
+	if x != nil {
+		return x.OpenPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetHighPrice() string {
+	if x != nil {
+		return x.HighPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetLowPrice() string {
+	if x != nil {
+		return x.LowPrice
+	}
+	return ""
+}
+
+func (x *Ticker) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *Ticker) GetQuoteVolume() string {
+	if x != nil {
+		return x.QuoteVolume
+	}
+	return ""
+}
+
+func (x *Ticker) GetOpenTime() int64 {
+	if x != nil {
+		return x.OpenTime
+	}
+	return 0
+}
+
+func (x *Ticker) GetCloseTime() int64 {
+	if x != nil {
+		return x.CloseTime
+	}
+	return 0
+}
+
+func (x *Ticker) GetFirstId() string {
+	if x != nil {
+		return x.FirstId
+	}
+	return ""
+}
+
+func (x *Ticker) GetLastId() string {
+	if x != nil {
+		return x.LastId
+	}
+	return ""
+}
+
+func (x *Ticker) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetTickerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tickers []*Ticker `protobuf:"bytes,1,rep,name=tickers,proto3" json:"tickers,omitempty"`
+}
+
+func (x *GetTickerResponse) Reset() {
+	*x = GetTickerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTickerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTickerResponse) ProtoMessage() {}
+
+func (x *GetTickerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTickerResponse.ProtoReflect.Descriptor instead.
+func (*GetTickerResponse) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetTickerResponse) GetTickers() []*Ticker {
+	if x != nil {
+		return x.Tickers
+	}
+	return nil
+}
+
+type GetTickerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+}
+
+func (x *GetTickerRequest) Reset() {
+	*x = GetTickerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTickerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTickerRequest) ProtoMessage() {}
+
+func (x *GetTickerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTickerRequest.ProtoReflect.Descriptor instead.
+func (*GetTickerRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetTickerRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+// GetWindowTickerRequest asks for the tickers of the window ending now,
+// windowSize is 1m..59m, 1h..23h or 1d..7d, 1d by default.
+type GetWindowTickerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Symbol     string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	WindowSize string `protobuf:"bytes,2,opt,name=windowSize,proto3" json:"windowSize,omitempty"`
+}
+
+func (x *GetWindowTickerRequest) Reset() {
+	*x = GetWindowTickerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWindowTickerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWindowTickerRequest) ProtoMessage() {}
+
+func (x *GetWindowTickerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWindowTickerRequest.ProtoReflect.Descriptor instead.
+func (*GetWindowTickerRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetWindowTickerRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetWindowTickerRequest) GetWindowSize() string {
+	if x != nil {
+		return x.WindowSize
+	}
+	return ""
+}
+
+// SubscribeAggTradesRequest selects live aggregate trades by market names
+// (e.g. BTC_USDT), empty list means "all".
+type SubscribeAggTradesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Markets []string `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
+}
+
+func (x *SubscribeAggTradesRequest) Reset() {
+	*x = SubscribeAggTradesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeAggTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeAggTradesRequest) ProtoMessage() {}
+
+func (x *SubscribeAggTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeAggTradesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeAggTradesRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubscribeAggTradesRequest) GetMarkets() []string {
+	if x != nil {
+		return x.Markets
+	}
+	return nil
+}
+
+// AggTrade is a sequence of the deals of a market at the same price with the
+// same taker side made within 100ms. It is sent when the next deal doesn't
+// continue it or the time is over.
+type AggTrade struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Symbol       string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price        string                 `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	Qty          string                 `protobuf:"bytes,3,opt,name=qty,proto3" json:"qty,omitempty"`
+	FirstId      string                 `protobuf:"bytes,4,opt,name=firstId,proto3" json:"firstId,omitempty"`
+	LastId       string                 `protobuf:"bytes,5,opt,name=lastId,proto3" json:"lastId,omitempty"`
+	FirstTime    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=firstTime,proto3" json:"firstTime,omitempty"`
+	LastTime     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=lastTime,proto3" json:"lastTime,omitempty"`
+	IsBuyerMaker bool                   `protobuf:"varint,8,opt,name=isBuyerMaker,proto3" json:"isBuyerMaker,omitempty"`
+	Count        int32                  `protobuf:"varint,9,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *AggTrade) Reset() {
+	*x = AggTrade{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AggTrade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggTrade) ProtoMessage() {}
+
+func (x *AggTrade) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggTrade.ProtoReflect.Descriptor instead.
+func (*AggTrade) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AggTrade) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *AggTrade) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *AggTrade) GetQty() string {
+	if x != nil {
+		return x.Qty
+	}
+	return ""
+}
+
+func (x *AggTrade) GetFirstId() string {
+	if x != nil {
+		return x.FirstId
+	}
+	return ""
+}
+
+func (x *AggTrade) GetLastId() string {
+	if x != nil {
+		return x.LastId
+	}
+	return ""
+}
+
+func (x *AggTrade) GetFirstTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstTime
+	}
+	return nil
+}
+
+func (x *AggTrade) GetLastTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastTime
+	}
+	return nil
+}
+
+func (x *AggTrade) GetIsBuyerMaker() bool {
+	if x != nil {
+		return x.IsBuyerMaker
+	}
+	return false
+}
+
+func (x *AggTrade) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// GetKlinesRequest asks for the klines of the symbol opened in
+// [startTime, endTime], or the latest ones until endTime if startTime is not
+// set. interval is 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 1w or 1M,
+// limit is 500 by default and 1000 at most.
+type GetKlinesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Symbol    string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Interval  string                 `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	Limit     int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *GetKlinesRequest) Reset() {
+	*x = GetKlinesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetKlinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKlinesRequest) ProtoMessage() {}
+
+func (x *GetKlinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKlinesRequest.ProtoReflect.Descriptor instead.
+func (*GetKlinesRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetKlinesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetKlinesRequest) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *GetKlinesRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetKlinesRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetKlinesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetKlinesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Klines []*Kline `protobuf:"bytes,1,rep,name=klines,proto3" json:"klines,omitempty"`
+}
+
+func (x *GetKlinesResponse) Reset() {
+	*x = GetKlinesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetKlinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKlinesResponse) ProtoMessage() {}
+
+func (x *GetKlinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKlinesResponse.ProtoReflect.Descriptor instead.
+func (*GetKlinesResponse) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetKlinesResponse) GetKlines() []*Kline {
+	if x != nil {
+		return x.Klines
+	}
+	return nil
+}
+
+// SubscribeCandlesRequest selects live candles by market names (e.g. BTC_USDT)
+// and resolutions (e.g. 1, 60, 1D). Empty lists mean "all".
+type SubscribeCandlesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Markets     []string `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
+	Resolutions []string `protobuf:"bytes,2,rep,name=resolutions,proto3" json:"resolutions,omitempty"`
+}
+
+func (x *SubscribeCandlesRequest) Reset() {
+	*x = SubscribeCandlesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *Ticker) GetOpenPrice() string {
-	if x != nil {
-		return x.OpenPrice
-	}
-	return ""
+func (x *SubscribeCandlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Ticker) GetHighPrice() string {
-	if x != nil {
-		return x.HighPrice
+func (*SubscribeCandlesRequest) ProtoMessage() {}
+
+func (x *SubscribeCandlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Ticker) GetLowPrice() string {
+// Deprecated: Use SubscribeCandlesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeCandlesRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SubscribeCandlesRequest) GetMarkets() []string {
 	if x != nil {
-		return x.LowPrice
+		return x.Markets
 	}
-	return ""
+	return nil
 }
 
-func (x *Ticker) GetVolume() string {
+func (x *SubscribeCandlesRequest) GetResolutions() []string {
 	if x != nil {
-		return x.Volume
+		return x.Resolutions
 	}
-	return ""
+	return nil
 }
 
-func (x *Ticker) GetQuoteVolume() string {
-	if x != nil {
-		return x.QuoteVolume
+type SubscribeCandlesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event      CandleEvent            `protobuf:"varint,1,opt,name=event,proto3,enum=ohlcv.CandleEvent" json:"event,omitempty"`
+	Resolution string                 `protobuf:"bytes,2,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	Candle     *Candle                `protobuf:"bytes,3,opt,name=candle,proto3" json:"candle,omitempty"`
+	CloseTime  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=closeTime,proto3" json:"closeTime,omitempty"`
+}
+
+func (x *SubscribeCandlesResponse) Reset() {
+	*x = SubscribeCandlesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ohlcv_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *Ticker) GetOpenTime() int64 {
-	if x != nil {
-		return x.OpenTime
+func (x *SubscribeCandlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeCandlesResponse) ProtoMessage() {}
+
+func (x *SubscribeCandlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *Ticker) GetCloseTime() int64 {
+// Deprecated: Use SubscribeCandlesResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeCandlesResponse) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SubscribeCandlesResponse) GetEvent() CandleEvent {
 	if x != nil {
-		return x.CloseTime
+		return x.Event
 	}
-	return 0
+	return CandleEvent_CANDLE_SNAPSHOT
 }
 
-func (x *Ticker) GetFirstId() string {
+func (x *SubscribeCandlesResponse) GetResolution() string {
 	if x != nil {
-		return x.FirstId
+		return x.Resolution
 	}
 	return ""
 }
 
-func (x *Ticker) GetLastId() string {
+func (x *SubscribeCandlesResponse) GetCandle() *Candle {
 	if x != nil {
-		return x.LastId
+		return x.Candle
 	}
-	return ""
+	return nil
 }
 
-func (x *Ticker) GetCount() int32 {
+func (x *SubscribeCandlesResponse) GetCloseTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Count
+		return x.CloseTime
 	}
-	return 0
+	return nil
 }
 
-type GetTickerResponse struct {
+// SubscribeTickersRequest selects 24h tickers by market names (e.g. BTC_USDT),
+// empty list means "all". The current tickers are sent first, then their
+// updates at most once per 250ms for a market.
+type SubscribeTickersRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tickers []*Ticker `protobuf:"bytes,1,rep,name=tickers,proto3" json:"tickers,omitempty"`
+	Markets []string `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
 }
 
-func (x *GetTickerResponse) Reset() {
-	*x = GetTickerResponse{}
+func (x *SubscribeTickersRequest) Reset() {
+	*x = SubscribeTickersRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[12]
+		mi := &file_ohlcv_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetTickerResponse) String() string {
+func (x *SubscribeTickersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTickerResponse) ProtoMessage() {}
+func (*SubscribeTickersRequest) ProtoMessage() {}
 
-func (x *GetTickerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[12]
+func (x *SubscribeTickersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1035,43 +1949,43 @@ func (x *GetTickerResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTickerResponse.ProtoReflect.Descriptor instead.
-func (*GetTickerResponse) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use SubscribeTickersRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeTickersRequest) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *GetTickerResponse) GetTickers() []*Ticker {
+func (x *SubscribeTickersRequest) GetMarkets() []string {
 	if x != nil {
-		return x.Tickers
+		return x.Markets
 	}
 	return nil
 }
 
-type GetTickerRequest struct {
+type SubscribeTickersResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Ticker *Ticker `protobuf:"bytes,1,opt,name=ticker,proto3" json:"ticker,omitempty"`
 }
 
-func (x *GetTickerRequest) Reset() {
-	*x = GetTickerRequest{}
+func (x *SubscribeTickersResponse) Reset() {
+	*x = SubscribeTickersResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_ohlcv_proto_msgTypes[13]
+		mi := &file_ohlcv_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetTickerRequest) String() string {
+func (x *SubscribeTickersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTickerRequest) ProtoMessage() {}
+func (*SubscribeTickersResponse) ProtoMessage() {}
 
-func (x *GetTickerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ohlcv_proto_msgTypes[13]
+func (x *SubscribeTickersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ohlcv_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1082,16 +1996,16 @@ func (x *GetTickerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTickerRequest.ProtoReflect.Descriptor instead.
-func (*GetTickerRequest) Descriptor() ([]byte, []int) {
-	return file_ohlcv_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use SubscribeTickersResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeTickersResponse) Descriptor() ([]byte, []int) {
+	return file_ohlcv_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *GetTickerRequest) GetSymbol() string {
+func (x *SubscribeTickersResponse) GetTicker() *Ticker {
 	if x != nil {
-		return x.Symbol
+		return x.Ticker
 	}
-	return ""
+	return nil
 }
 
 var File_ohlcv_proto protoreflect.FileDescriptor
@@ -1100,153 +2014,274 @@ var file_ohlcv_proto_rawDesc = []byte{
 	0x0a, 0x0b, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x6f,
 	0x68, 0x6c, 0x63, 0x76, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x17, 0x0a, 0x15, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
-	0x62, 0x65, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xca,
-	0x01, 0x0a, 0x16, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x44, 0x65, 0x61, 0x6c,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69,
-	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f,
-	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12,
-	0x16, 0x0a, 0x06, 0x64, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x64, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79,
-	0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69,
-	0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x22, 0x7a, 0x0a, 0x1c, 0x47,
-	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x43, 0x61, 0x6e,
-	0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66,
-	0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74,
-	0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0xc0, 0x01, 0x0a, 0x06, 0x43, 0x61, 0x6e, 0x64,
-	0x6c, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70,
-	0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12, 0x12,
-	0x0a, 0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x69,
-	0x67, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6c, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f,
-	0x6c, 0x75, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75,
-	0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x22, 0x48, 0x0a, 0x1d, 0x47, 0x65,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa7, 0x01, 0x0a, 0x15, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6c, 0x61, 0x73,
+	0x74, 0x44, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6c,
+	0x61, 0x73, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x62,
+	0x61, 0x63, 0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x62, 0x61, 0x63, 0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x22,
+	0xed, 0x01, 0x0a, 0x16, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x44, 0x65, 0x61,
+	0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x64, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x69, 0x73, 0x42, 0x75,
+	0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x69, 0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x03,
+	0x67, 0x61, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6f, 0x68, 0x6c, 0x63,
+	0x76, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x47, 0x61, 0x70, 0x52, 0x03, 0x67, 0x61, 0x70, 0x22,
+	0x98, 0x01, 0x0a, 0x08, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x47, 0x61, 0x70, 0x12, 0x2e, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02,
+	0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x22, 0x7a, 0x0a, 0x1c, 0x47, 0x65,
 	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x43, 0x61, 0x6e, 0x64,
-	0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x63,
-	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f,
-	0x68, 0x6c, 0x63, 0x76, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x07, 0x63, 0x61, 0x6e,
-	0x64, 0x6c, 0x65, 0x73, 0x22, 0x79, 0x0a, 0x1b, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
-	0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66,
-	0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22,
-	0xcf, 0x03, 0x0a, 0x05, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x6f, 0x70, 0x65,
-	0x6e, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0xc0, 0x01, 0x0a, 0x06, 0x43, 0x61, 0x6e, 0x64, 0x6c,
+	0x65, 0x12, 0x36, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x69, 0x67,
+	0x68, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6c, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x22, 0x48, 0x0a, 0x1d, 0x47, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x43, 0x61, 0x6e, 0x64, 0x6c,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x63, 0x61,
+	0x6e, 0x64, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f, 0x68,
+	0x6c, 0x63, 0x76, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x07, 0x63, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x73, 0x22, 0x79, 0x0a, 0x1b, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d,
+	0x69, 0x6e, 0x75, 0x74, 0x65, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x9f,
+	0x04, 0x0a, 0x05, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e,
+	0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6f, 0x70, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x68, 0x69, 0x67, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x77, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6c, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c,
+	0x6f, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x6c, 0x6f, 0x73,
+	0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
 	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x69, 0x67, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x77,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6c, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x63,
-	0x6c, 0x6f, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x6c, 0x6f,
-	0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x54,
-	0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74,
-	0x61, 0x6b, 0x65, 0x72, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0b, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x12, 0x20, 0x0a,
-	0x0b, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0b, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12,
-	0x16, 0x0a, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f,
-	0x6c, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12,
-	0x30, 0x0a, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x61,
+	0x6b, 0x65, 0x72, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x0b,
+	0x74, 0x61, 0x6b, 0x65, 0x72, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x30,
+	0x0a, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x61, 0x73, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73,
-	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x61, 0x73, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x6c, 0x61, 0x73,
-	0x74, 0x22, 0x44, 0x0a, 0x1c, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e,
-	0x75, 0x74, 0x65, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x24, 0x0a, 0x06, 0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x52,
-	0x06, 0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4c, 0x61,
-	0x73, 0x74, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xb5, 0x01,
-	0x0a, 0x05, 0x54, 0x72, 0x61, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x10, 0x0a,
-	0x03, 0x71, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x71, 0x74, 0x79, 0x12,
-	0x1a, 0x0a, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x51, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x51, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74,
-	0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12,
-	0x22, 0x0a, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61,
-	0x6b, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x73, 0x42, 0x65, 0x73, 0x74, 0x4d, 0x61, 0x74,
-	0x63, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x42, 0x65, 0x73, 0x74,
-	0x4d, 0x61, 0x74, 0x63, 0x68, 0x22, 0x3d, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73, 0x74,
-	0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24,
-	0x0a, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
-	0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x54, 0x72, 0x61, 0x64, 0x65, 0x52, 0x06, 0x74, 0x72,
-	0x61, 0x64, 0x65, 0x73, 0x22, 0xfa, 0x04, 0x0a, 0x06, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x12,
-	0x16, 0x0a, 0x06, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x50, 0x72, 0x69, 0x63, 0x65,
-	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x50, 0x72,
-	0x69, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x50, 0x72, 0x69,
-	0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x50, 0x72, 0x69, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e,
-	0x67, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x10, 0x57, 0x65, 0x69,
-	0x67, 0x68, 0x74, 0x65, 0x64, 0x41, 0x76, 0x67, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x10, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x65, 0x64, 0x41, 0x76, 0x67,
-	0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x50, 0x72, 0x65, 0x76, 0x43, 0x6c, 0x6f,
-	0x73, 0x65, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x50,
-	0x72, 0x65, 0x76, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1c, 0x0a,
-	0x09, 0x4c, 0x61, 0x73, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x4c, 0x61, 0x73, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x4c,
-	0x61, 0x73, 0x74, 0x51, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4c, 0x61,
-	0x73, 0x74, 0x51, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x42, 0x69, 0x64, 0x50, 0x72, 0x69, 0x63,
-	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x42, 0x69, 0x64, 0x50, 0x72, 0x69, 0x63,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x42, 0x69, 0x64, 0x51, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x42, 0x69, 0x64, 0x51, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x41, 0x73, 0x6b,
-	0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x41, 0x73, 0x6b,
-	0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x73, 0x6b, 0x51, 0x74, 0x79, 0x18,
-	0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x73, 0x6b, 0x51, 0x74, 0x79, 0x12, 0x1c, 0x0a,
-	0x09, 0x4f, 0x70, 0x65, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x4f, 0x70, 0x65, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x48,
-	0x69, 0x67, 0x68, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x48, 0x69, 0x67, 0x68, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x6f, 0x77,
-	0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x6f, 0x77,
-	0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18,
-	0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x20, 0x0a,
-	0x0b, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x10, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0b, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12,
-	0x1a, 0x0a, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x43,
-	0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
-	0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x46, 0x69, 0x72,
-	0x73, 0x74, 0x49, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x46, 0x69, 0x72, 0x73,
-	0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x18, 0x14, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x43,
-	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x43, 0x6f, 0x75, 0x6e,
-	0x74, 0x22, 0x3c, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x72,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e,
-	0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x73, 0x22,
-	0x2a, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x32, 0xb1, 0x03, 0x0a, 0x0c,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x6c, 0x61, 0x73, 0x74,
+	0x12, 0x26, 0x0a, 0x0e, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x42, 0x75, 0x79, 0x41, 0x73, 0x73, 0x65,
+	0x74, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x42,
+	0x75, 0x79, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x74, 0x61, 0x6b, 0x65,
+	0x72, 0x42, 0x75, 0x79, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x74, 0x61, 0x6b, 0x65, 0x72, 0x42, 0x75, 0x79, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x73,
+	0x22, 0x44, 0x0a, 0x1c, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e, 0x75,
+	0x74, 0x65, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x24, 0x0a, 0x06, 0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x06,
+	0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73,
+	0x74, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xb5, 0x01, 0x0a,
+	0x05, 0x54, 0x72, 0x61, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x71, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x71, 0x74, 0x79, 0x12, 0x1a,
+	0x0a, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x51, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x51, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x22,
+	0x0a, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b,
+	0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x73, 0x42, 0x65, 0x73, 0x74, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x42, 0x65, 0x73, 0x74, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x22, 0x3d, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73, 0x74, 0x54,
+	0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a,
+	0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x54, 0x72, 0x61, 0x64, 0x65, 0x52, 0x06, 0x74, 0x72, 0x61,
+	0x64, 0x65, 0x73, 0x22, 0xea, 0x01, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x69, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x66, 0x72, 0x6f, 0x6d, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x66, 0x72, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x22, 0x63, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61,
+	0x6c, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x24, 0x0a, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x54, 0x72, 0x61, 0x64, 0x65, 0x52, 0x06, 0x74,
+	0x72, 0x61, 0x64, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43,
+	0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0xfa, 0x04, 0x0a, 0x06, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x50, 0x72, 0x69, 0x63,
+	0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x50,
+	0x72, 0x69, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x50, 0x72,
+	0x69, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x50, 0x72, 0x69, 0x63, 0x65, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x10, 0x57, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x65, 0x64, 0x41, 0x76, 0x67, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x65, 0x64, 0x41, 0x76,
+	0x67, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x50, 0x72, 0x65, 0x76, 0x43, 0x6c,
+	0x6f, 0x73, 0x65, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x50, 0x72, 0x65, 0x76, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x4c, 0x61, 0x73, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x4c, 0x61, 0x73, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x4c, 0x61, 0x73, 0x74, 0x51, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4c,
+	0x61, 0x73, 0x74, 0x51, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x42, 0x69, 0x64, 0x50, 0x72, 0x69,
+	0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x42, 0x69, 0x64, 0x50, 0x72, 0x69,
+	0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x42, 0x69, 0x64, 0x51, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x42, 0x69, 0x64, 0x51, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x41, 0x73,
+	0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x41, 0x73,
+	0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x73, 0x6b, 0x51, 0x74, 0x79,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x73, 0x6b, 0x51, 0x74, 0x79, 0x12, 0x1c,
+	0x0a, 0x09, 0x4f, 0x70, 0x65, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x4f, 0x70, 0x65, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x48, 0x69, 0x67, 0x68, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x48, 0x69, 0x67, 0x68, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x6f,
+	0x77, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x6f,
+	0x77, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x46, 0x69,
+	0x72, 0x73, 0x74, 0x49, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x46, 0x69, 0x72,
+	0x73, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x18, 0x14,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x22, 0x3c, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76,
+	0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x73,
+	0x22, 0x2a, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x22, 0x50, 0x0a, 0x16,
+	0x47, 0x65, 0x74, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x1e,
+	0x0a, 0x0a, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x35,
+	0x0a, 0x19, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x41, 0x67, 0x67, 0x54, 0x72,
+	0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61,
+	0x72, 0x6b, 0x65, 0x74, 0x73, 0x22, 0xa8, 0x02, 0x0a, 0x08, 0x41, 0x67, 0x67, 0x54, 0x72, 0x61,
+	0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x71, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x71,
+	0x74, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x66, 0x69, 0x72, 0x73, 0x74, 0x49, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x66, 0x69, 0x72, 0x73, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x6c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61,
+	0x73, 0x74, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x36,
+	0x0a, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c, 0x61,
+	0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x69, 0x73, 0x42, 0x75, 0x79, 0x65,
+	0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x73,
+	0x42, 0x75, 0x79, 0x65, 0x72, 0x4d, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x22, 0xcc, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x1a, 0x0a,
+	0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x38, 0x0a, 0x09, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0x39, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06, 0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x4b, 0x6c, 0x69,
+	0x6e, 0x65, 0x52, 0x06, 0x6b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x22, 0x55, 0x0a, 0x17, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x12,
+	0x20, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0xc5, 0x01, 0x0a, 0x18, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28,
+	0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e,
+	0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f,
+	0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65,
+	0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x06, 0x63, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76,
+	0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x06, 0x63, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12,
+	0x38, 0x0a, 0x09, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x33, 0x0a, 0x17, 0x53, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x73, 0x22, 0x41,
+	0x0a, 0x18, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65,
+	0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x06, 0x74, 0x69,
+	0x63, 0x6b, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6f, 0x68, 0x6c,
+	0x63, 0x76, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x72, 0x2a, 0x48, 0x0a, 0x0b, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x13, 0x0a, 0x0f, 0x43, 0x41, 0x4e, 0x44, 0x4c, 0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53,
+	0x48, 0x4f, 0x54, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x41, 0x4e, 0x44, 0x4c, 0x45, 0x5f,
+	0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x41, 0x4e, 0x44,
+	0x4c, 0x45, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x44, 0x10, 0x02, 0x32, 0x94, 0x07, 0x0a, 0x0c,
 	0x4f, 0x48, 0x4c, 0x43, 0x56, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x62, 0x0a, 0x15,
 	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x43,
 	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x23, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65,
@@ -1273,9 +2308,39 @@ var file_ohlcv_proto_rawDesc = []byte{
 	0x3e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x6f,
 	0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65,
-	0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x09, 0x5a, 0x07, 0x2e, 0x2f, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x55, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x55, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x1e, 0x2e, 0x6f, 0x68, 0x6c,
+	0x63, 0x76, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x69, 0x63, 0x6b,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6f, 0x68, 0x6c,
+	0x63, 0x76, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x69, 0x63, 0x6b,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4a, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72,
+	0x12, 0x1d, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x69, 0x6e, 0x64,
+	0x6f, 0x77, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x09, 0x47, 0x65, 0x74,
+	0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x17, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47,
+	0x65, 0x74, 0x4b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x4b, 0x6c, 0x69, 0x6e, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5c, 0x0a, 0x13, 0x47, 0x65, 0x74,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73,
+	0x12, 0x21, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x47, 0x65, 0x74, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x12, 0x53, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x41, 0x67, 0x67, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x12, 0x20, 0x2e,
+	0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x41,
+	0x67, 0x67, 0x54, 0x72, 0x61, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0f, 0x2e, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x2e, 0x41, 0x67, 0x67, 0x54, 0x72, 0x61, 0x64, 0x65,
+	0x30, 0x01, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x2f, 0x6f, 0x68, 0x6c, 0x63, 0x76, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1290,54 +2355,96 @@ func file_ohlcv_proto_rawDescGZIP() []byte {
 	return file_ohlcv_proto_rawDescData
 }
 
-var file_ohlcv_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_ohlcv_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ohlcv_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_ohlcv_proto_goTypes = []interface{}{
-	(*SubscribeDealsRequest)(nil),         // 0: ohlcv.SubscribeDealsRequest
-	(*SubscribeDealsResponse)(nil),        // 1: ohlcv.SubscribeDealsResponse
-	(*GenerateMinuteCandlesRequest)(nil),  // 2: ohlcv.GenerateMinuteCandlesRequest
-	(*Candle)(nil),                        // 3: ohlcv.Candle
-	(*GenerateMinuteCandlesResponse)(nil), // 4: ohlcv.GenerateMinuteCandlesResponse
-	(*GenerateMinuteKlinesRequest)(nil),   // 5: ohlcv.GenerateMinuteKlinesRequest
-	(*Kline)(nil),                         // 6: ohlcv.Kline
-	(*GenerateMinuteKlinesResponse)(nil),  // 7: ohlcv.GenerateMinuteKlinesResponse
-	(*GetLastTradesRequest)(nil),          // 8: ohlcv.GetLastTradesRequest
-	(*Trade)(nil),                         // 9: ohlcv.Trade
-	(*GetLastTradesResponse)(nil),         // 10: ohlcv.GetLastTradesResponse
-	(*Ticker)(nil),                        // 11: ohlcv.Ticker
-	(*GetTickerResponse)(nil),             // 12: ohlcv.GetTickerResponse
-	(*GetTickerRequest)(nil),              // 13: ohlcv.GetTickerRequest
-	(*timestamppb.Timestamp)(nil),         // 14: google.protobuf.Timestamp
+	(CandleEvent)(0),                      // 0: ohlcv.CandleEvent
+	(*SubscribeDealsRequest)(nil),         // 1: ohlcv.SubscribeDealsRequest
+	(*SubscribeDealsResponse)(nil),        // 2: ohlcv.SubscribeDealsResponse
+	(*DealsGap)(nil),                      // 3: ohlcv.DealsGap
+	(*GenerateMinuteCandlesRequest)(nil),  // 4: ohlcv.GenerateMinuteCandlesRequest
+	(*Candle)(nil),                        // 5: ohlcv.Candle
+	(*GenerateMinuteCandlesResponse)(nil), // 6: ohlcv.GenerateMinuteCandlesResponse
+	(*GenerateMinuteKlinesRequest)(nil),   // 7: ohlcv.GenerateMinuteKlinesRequest
+	(*Kline)(nil),                         // 8: ohlcv.Kline
+	(*GenerateMinuteKlinesResponse)(nil),  // 9: ohlcv.GenerateMinuteKlinesResponse
+	(*GetLastTradesRequest)(nil),          // 10: ohlcv.GetLastTradesRequest
+	(*Trade)(nil),                         // 11: ohlcv.Trade
+	(*GetLastTradesResponse)(nil),         // 12: ohlcv.GetLastTradesResponse
+	(*GetHistoricalTradesRequest)(nil),    // 13: ohlcv.GetHistoricalTradesRequest
+	(*GetHistoricalTradesResponse)(nil),   // 14: ohlcv.GetHistoricalTradesResponse
+	(*Ticker)(nil),                        // 15: ohlcv.Ticker
+	(*GetTickerResponse)(nil),             // 16: ohlcv.GetTickerResponse
+	(*GetTickerRequest)(nil),              // 17: ohlcv.GetTickerRequest
+	(*GetWindowTickerRequest)(nil),        // 18: ohlcv.GetWindowTickerRequest
+	(*SubscribeAggTradesRequest)(nil),     // 19: ohlcv.SubscribeAggTradesRequest
+	(*AggTrade)(nil),                      // 20: ohlcv.AggTrade
+	(*GetKlinesRequest)(nil),              // 21: ohlcv.GetKlinesRequest
+	(*GetKlinesResponse)(nil),             // 22: ohlcv.GetKlinesResponse
+	(*SubscribeCandlesRequest)(nil),       // 23: ohlcv.SubscribeCandlesRequest
+	(*SubscribeCandlesResponse)(nil),      // 24: ohlcv.SubscribeCandlesResponse
+	(*SubscribeTickersRequest)(nil),       // 25: ohlcv.SubscribeTickersRequest
+	(*SubscribeTickersResponse)(nil),      // 26: ohlcv.SubscribeTickersResponse
+	(*timestamppb.Timestamp)(nil),         // 27: google.protobuf.Timestamp
 }
 var file_ohlcv_proto_depIdxs = []int32{
-	14, // 0: ohlcv.SubscribeDealsResponse.time:type_name -> google.protobuf.Timestamp
-	14, // 1: ohlcv.GenerateMinuteCandlesRequest.from:type_name -> google.protobuf.Timestamp
-	14, // 2: ohlcv.GenerateMinuteCandlesRequest.to:type_name -> google.protobuf.Timestamp
-	14, // 3: ohlcv.Candle.openTime:type_name -> google.protobuf.Timestamp
-	3,  // 4: ohlcv.GenerateMinuteCandlesResponse.candles:type_name -> ohlcv.Candle
-	14, // 5: ohlcv.GenerateMinuteKlinesRequest.from:type_name -> google.protobuf.Timestamp
-	14, // 6: ohlcv.GenerateMinuteKlinesRequest.to:type_name -> google.protobuf.Timestamp
-	14, // 7: ohlcv.Kline.openTime:type_name -> google.protobuf.Timestamp
-	14, // 8: ohlcv.Kline.closeTime:type_name -> google.protobuf.Timestamp
-	14, // 9: ohlcv.Kline.first:type_name -> google.protobuf.Timestamp
-	14, // 10: ohlcv.Kline.last:type_name -> google.protobuf.Timestamp
-	6,  // 11: ohlcv.GenerateMinuteKlinesResponse.klines:type_name -> ohlcv.Kline
-	9,  // 12: ohlcv.GetLastTradesResponse.trades:type_name -> ohlcv.Trade
-	11, // 13: ohlcv.GetTickerResponse.tickers:type_name -> ohlcv.Ticker
-	2,  // 14: ohlcv.OHLCVService.GenerateMinutesCandle:input_type -> ohlcv.GenerateMinuteCandlesRequest
-	5,  // 15: ohlcv.OHLCVService.GenerateMinutesKlines:input_type -> ohlcv.GenerateMinuteKlinesRequest
-	0,  // 16: ohlcv.OHLCVService.SubscribeDeals:input_type -> ohlcv.SubscribeDealsRequest
-	8,  // 17: ohlcv.OHLCVService.GetLastTrades:input_type -> ohlcv.GetLastTradesRequest
-	13, // 18: ohlcv.OHLCVService.GetTicker:input_type -> ohlcv.GetTickerRequest
-	4,  // 19: ohlcv.OHLCVService.GenerateMinutesCandle:output_type -> ohlcv.GenerateMinuteCandlesResponse
-	7,  // 20: ohlcv.OHLCVService.GenerateMinutesKlines:output_type -> ohlcv.GenerateMinuteKlinesResponse
-	1,  // 21: ohlcv.OHLCVService.SubscribeDeals:output_type -> ohlcv.SubscribeDealsResponse
-	10, // 22: ohlcv.OHLCVService.GetLastTrades:output_type -> ohlcv.GetLastTradesResponse
-	12, // 23: ohlcv.OHLCVService.GetTicker:output_type -> ohlcv.GetTickerResponse
-	19, // [19:24] is the sub-list for method output_type
-	14, // [14:19] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	27, // 0: ohlcv.SubscribeDealsRequest.since:type_name -> google.protobuf.Timestamp
+	27, // 1: ohlcv.SubscribeDealsResponse.time:type_name -> google.protobuf.Timestamp
+	3,  // 2: ohlcv.SubscribeDealsResponse.gap:type_name -> ohlcv.DealsGap
+	27, // 3: ohlcv.DealsGap.from:type_name -> google.protobuf.Timestamp
+	27, // 4: ohlcv.DealsGap.to:type_name -> google.protobuf.Timestamp
+	27, // 5: ohlcv.GenerateMinuteCandlesRequest.from:type_name -> google.protobuf.Timestamp
+	27, // 6: ohlcv.GenerateMinuteCandlesRequest.to:type_name -> google.protobuf.Timestamp
+	27, // 7: ohlcv.Candle.openTime:type_name -> google.protobuf.Timestamp
+	5,  // 8: ohlcv.GenerateMinuteCandlesResponse.candles:type_name -> ohlcv.Candle
+	27, // 9: ohlcv.GenerateMinuteKlinesRequest.from:type_name -> google.protobuf.Timestamp
+	27, // 10: ohlcv.GenerateMinuteKlinesRequest.to:type_name -> google.protobuf.Timestamp
+	27, // 11: ohlcv.Kline.openTime:type_name -> google.protobuf.Timestamp
+	27, // 12: ohlcv.Kline.closeTime:type_name -> google.protobuf.Timestamp
+	27, // 13: ohlcv.Kline.first:type_name -> google.protobuf.Timestamp
+	27, // 14: ohlcv.Kline.last:type_name -> google.protobuf.Timestamp
+	8,  // 15: ohlcv.GenerateMinuteKlinesResponse.klines:type_name -> ohlcv.Kline
+	11, // 16: ohlcv.GetLastTradesResponse.trades:type_name -> ohlcv.Trade
+	27, // 17: ohlcv.GetHistoricalTradesRequest.startTime:type_name -> google.protobuf.Timestamp
+	27, // 18: ohlcv.GetHistoricalTradesRequest.endTime:type_name -> google.protobuf.Timestamp
+	11, // 19: ohlcv.GetHistoricalTradesResponse.trades:type_name -> ohlcv.Trade
+	15, // 20: ohlcv.GetTickerResponse.tickers:type_name -> ohlcv.Ticker
+	27, // 21: ohlcv.AggTrade.firstTime:type_name -> google.protobuf.Timestamp
+	27, // 22: ohlcv.AggTrade.lastTime:type_name -> google.protobuf.Timestamp
+	27, // 23: ohlcv.GetKlinesRequest.startTime:type_name -> google.protobuf.Timestamp
+	27, // 24: ohlcv.GetKlinesRequest.endTime:type_name -> google.protobuf.Timestamp
+	8,  // 25: ohlcv.GetKlinesResponse.klines:type_name -> ohlcv.Kline
+	0,  // 26: ohlcv.SubscribeCandlesResponse.event:type_name -> ohlcv.CandleEvent
+	5,  // 27: ohlcv.SubscribeCandlesResponse.candle:type_name -> ohlcv.Candle
+	27, // 28: ohlcv.SubscribeCandlesResponse.closeTime:type_name -> google.protobuf.Timestamp
+	15, // 29: ohlcv.SubscribeTickersResponse.ticker:type_name -> ohlcv.Ticker
+	4,  // 30: ohlcv.OHLCVService.GenerateMinutesCandle:input_type -> ohlcv.GenerateMinuteCandlesRequest
+	7,  // 31: ohlcv.OHLCVService.GenerateMinutesKlines:input_type -> ohlcv.GenerateMinuteKlinesRequest
+	1,  // 32: ohlcv.OHLCVService.SubscribeDeals:input_type -> ohlcv.SubscribeDealsRequest
+	10, // 33: ohlcv.OHLCVService.GetLastTrades:input_type -> ohlcv.GetLastTradesRequest
+	17, // 34: ohlcv.OHLCVService.GetTicker:input_type -> ohlcv.GetTickerRequest
+	23, // 35: ohlcv.OHLCVService.SubscribeCandles:input_type -> ohlcv.SubscribeCandlesRequest
+	25, // 36: ohlcv.OHLCVService.SubscribeTickers:input_type -> ohlcv.SubscribeTickersRequest
+	18, // 37: ohlcv.OHLCVService.GetWindowTicker:input_type -> ohlcv.GetWindowTickerRequest
+	21, // 38: ohlcv.OHLCVService.GetKlines:input_type -> ohlcv.GetKlinesRequest
+	13, // 39: ohlcv.OHLCVService.GetHistoricalTrades:input_type -> ohlcv.GetHistoricalTradesRequest
+	19, // 40: ohlcv.OHLCVService.SubscribeAggTrades:input_type -> ohlcv.SubscribeAggTradesRequest
+	6,  // 41: ohlcv.OHLCVService.GenerateMinutesCandle:output_type -> ohlcv.GenerateMinuteCandlesResponse
+	9,  // 42: ohlcv.OHLCVService.GenerateMinutesKlines:output_type -> ohlcv.GenerateMinuteKlinesResponse
+	2,  // 43: ohlcv.OHLCVService.SubscribeDeals:output_type -> ohlcv.SubscribeDealsResponse
+	12, // 44: ohlcv.OHLCVService.GetLastTrades:output_type -> ohlcv.GetLastTradesResponse
+	16, // 45: ohlcv.OHLCVService.GetTicker:output_type -> ohlcv.GetTickerResponse
+	24, // 46: ohlcv.OHLCVService.SubscribeCandles:output_type -> ohlcv.SubscribeCandlesResponse
+	26, // 47: ohlcv.OHLCVService.SubscribeTickers:output_type -> ohlcv.SubscribeTickersResponse
+	16, // 48: ohlcv.OHLCVService.GetWindowTicker:output_type -> ohlcv.GetTickerResponse
+	22, // 49: ohlcv.OHLCVService.GetKlines:output_type -> ohlcv.GetKlinesResponse
+	14, // 50: ohlcv.OHLCVService.GetHistoricalTrades:output_type -> ohlcv.GetHistoricalTradesResponse
+	20, // 51: ohlcv.OHLCVService.SubscribeAggTrades:output_type -> ohlcv.AggTrade
+	41, // [41:52] is the sub-list for method output_type
+	30, // [30:41] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
 }
 
 func init() { file_ohlcv_proto_init() }
@@ -1371,7 +2478,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateMinuteCandlesRequest); i {
+			switch v := v.(*DealsGap); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1383,7 +2490,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Candle); i {
+			switch v := v.(*GenerateMinuteCandlesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1395,7 +2502,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateMinuteCandlesResponse); i {
+			switch v := v.(*Candle); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1407,7 +2514,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateMinuteKlinesRequest); i {
+			switch v := v.(*GenerateMinuteCandlesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1419,7 +2526,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Kline); i {
+			switch v := v.(*GenerateMinuteKlinesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1431,7 +2538,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateMinuteKlinesResponse); i {
+			switch v := v.(*Kline); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1443,7 +2550,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetLastTradesRequest); i {
+			switch v := v.(*GenerateMinuteKlinesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1455,7 +2562,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Trade); i {
+			switch v := v.(*GetLastTradesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1467,7 +2574,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetLastTradesResponse); i {
+			switch v := v.(*Trade); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1479,7 +2586,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Ticker); i {
+			switch v := v.(*GetLastTradesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1491,7 +2598,7 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetTickerResponse); i {
+			switch v := v.(*GetHistoricalTradesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1503,6 +2610,42 @@ func file_ohlcv_proto_init() {
 			}
 		}
 		file_ohlcv_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetHistoricalTradesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ticker); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTickerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetTickerRequest); i {
 			case 0:
 				return &v.state
@@ -1514,19 +2657,128 @@ func file_ohlcv_proto_init() {
 				return nil
 			}
 		}
+		file_ohlcv_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWindowTickerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeAggTradesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AggTrade); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetKlinesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetKlinesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeCandlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeCandlesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeTickersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ohlcv_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeTickersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_ohlcv_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   14,
+			NumEnums:      1,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_ohlcv_proto_goTypes,
 		DependencyIndexes: file_ohlcv_proto_depIdxs,
+		EnumInfos:         file_ohlcv_proto_enumTypes,
 		MessageInfos:      file_ohlcv_proto_msgTypes,
 	}.Build()
 	File_ohlcv_proto = out.File
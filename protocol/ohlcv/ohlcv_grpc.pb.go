@@ -27,6 +27,12 @@ type OHLCVServiceClient interface {
 	SubscribeDeals(ctx context.Context, in *SubscribeDealsRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeDealsClient, error)
 	GetLastTrades(ctx context.Context, in *GetLastTradesRequest, opts ...grpc.CallOption) (*GetLastTradesResponse, error)
 	GetTicker(ctx context.Context, in *GetTickerRequest, opts ...grpc.CallOption) (*GetTickerResponse, error)
+	SubscribeCandles(ctx context.Context, in *SubscribeCandlesRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeCandlesClient, error)
+	SubscribeTickers(ctx context.Context, in *SubscribeTickersRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeTickersClient, error)
+	GetWindowTicker(ctx context.Context, in *GetWindowTickerRequest, opts ...grpc.CallOption) (*GetTickerResponse, error)
+	GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error)
+	GetHistoricalTrades(ctx context.Context, in *GetHistoricalTradesRequest, opts ...grpc.CallOption) (*GetHistoricalTradesResponse, error)
+	SubscribeAggTrades(ctx context.Context, in *SubscribeAggTradesRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeAggTradesClient, error)
 }
 
 type oHLCVServiceClient struct {
@@ -105,6 +111,129 @@ func (c *oHLCVServiceClient) GetTicker(ctx context.Context, in *GetTickerRequest
 	return out, nil
 }
 
+func (c *oHLCVServiceClient) SubscribeCandles(ctx context.Context, in *SubscribeCandlesRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeCandlesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OHLCVService_ServiceDesc.Streams[1], "/ohlcv.OHLCVService/SubscribeCandles", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oHLCVServiceSubscribeCandlesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OHLCVService_SubscribeCandlesClient interface {
+	Recv() (*SubscribeCandlesResponse, error)
+	grpc.ClientStream
+}
+
+type oHLCVServiceSubscribeCandlesClient struct {
+	grpc.ClientStream
+}
+
+func (x *oHLCVServiceSubscribeCandlesClient) Recv() (*SubscribeCandlesResponse, error) {
+	m := new(SubscribeCandlesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oHLCVServiceClient) SubscribeTickers(ctx context.Context, in *SubscribeTickersRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeTickersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OHLCVService_ServiceDesc.Streams[2], "/ohlcv.OHLCVService/SubscribeTickers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oHLCVServiceSubscribeTickersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OHLCVService_SubscribeTickersClient interface {
+	Recv() (*SubscribeTickersResponse, error)
+	grpc.ClientStream
+}
+
+type oHLCVServiceSubscribeTickersClient struct {
+	grpc.ClientStream
+}
+
+func (x *oHLCVServiceSubscribeTickersClient) Recv() (*SubscribeTickersResponse, error) {
+	m := new(SubscribeTickersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oHLCVServiceClient) GetWindowTicker(ctx context.Context, in *GetWindowTickerRequest, opts ...grpc.CallOption) (*GetTickerResponse, error) {
+	out := new(GetTickerResponse)
+	err := c.cc.Invoke(ctx, "/ohlcv.OHLCVService/GetWindowTicker", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oHLCVServiceClient) GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error) {
+	out := new(GetKlinesResponse)
+	err := c.cc.Invoke(ctx, "/ohlcv.OHLCVService/GetKlines", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oHLCVServiceClient) GetHistoricalTrades(ctx context.Context, in *GetHistoricalTradesRequest, opts ...grpc.CallOption) (*GetHistoricalTradesResponse, error) {
+	out := new(GetHistoricalTradesResponse)
+	err := c.cc.Invoke(ctx, "/ohlcv.OHLCVService/GetHistoricalTrades", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oHLCVServiceClient) SubscribeAggTrades(ctx context.Context, in *SubscribeAggTradesRequest, opts ...grpc.CallOption) (OHLCVService_SubscribeAggTradesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OHLCVService_ServiceDesc.Streams[3], "/ohlcv.OHLCVService/SubscribeAggTrades", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oHLCVServiceSubscribeAggTradesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OHLCVService_SubscribeAggTradesClient interface {
+	Recv() (*AggTrade, error)
+	grpc.ClientStream
+}
+
+type oHLCVServiceSubscribeAggTradesClient struct {
+	grpc.ClientStream
+}
+
+func (x *oHLCVServiceSubscribeAggTradesClient) Recv() (*AggTrade, error) {
+	m := new(AggTrade)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // OHLCVServiceServer is the server API for OHLCVService service.
 // All implementations must embed UnimplementedOHLCVServiceServer
 // for forward compatibility
@@ -114,6 +243,12 @@ type OHLCVServiceServer interface {
 	SubscribeDeals(*SubscribeDealsRequest, OHLCVService_SubscribeDealsServer) error
 	GetLastTrades(context.Context, *GetLastTradesRequest) (*GetLastTradesResponse, error)
 	GetTicker(context.Context, *GetTickerRequest) (*GetTickerResponse, error)
+	SubscribeCandles(*SubscribeCandlesRequest, OHLCVService_SubscribeCandlesServer) error
+	SubscribeTickers(*SubscribeTickersRequest, OHLCVService_SubscribeTickersServer) error
+	GetWindowTicker(context.Context, *GetWindowTickerRequest) (*GetTickerResponse, error)
+	GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error)
+	GetHistoricalTrades(context.Context, *GetHistoricalTradesRequest) (*GetHistoricalTradesResponse, error)
+	SubscribeAggTrades(*SubscribeAggTradesRequest, OHLCVService_SubscribeAggTradesServer) error
 	mustEmbedUnimplementedOHLCVServiceServer()
 }
 
@@ -136,6 +271,24 @@ func (UnimplementedOHLCVServiceServer) GetLastTrades(context.Context, *GetLastTr
 func (UnimplementedOHLCVServiceServer) GetTicker(context.Context, *GetTickerRequest) (*GetTickerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTicker not implemented")
 }
+func (UnimplementedOHLCVServiceServer) SubscribeCandles(*SubscribeCandlesRequest, OHLCVService_SubscribeCandlesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeCandles not implemented")
+}
+func (UnimplementedOHLCVServiceServer) SubscribeTickers(*SubscribeTickersRequest, OHLCVService_SubscribeTickersServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTickers not implemented")
+}
+func (UnimplementedOHLCVServiceServer) GetWindowTicker(context.Context, *GetWindowTickerRequest) (*GetTickerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWindowTicker not implemented")
+}
+func (UnimplementedOHLCVServiceServer) GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKlines not implemented")
+}
+func (UnimplementedOHLCVServiceServer) GetHistoricalTrades(context.Context, *GetHistoricalTradesRequest) (*GetHistoricalTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistoricalTrades not implemented")
+}
+func (UnimplementedOHLCVServiceServer) SubscribeAggTrades(*SubscribeAggTradesRequest, OHLCVService_SubscribeAggTradesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAggTrades not implemented")
+}
 func (UnimplementedOHLCVServiceServer) mustEmbedUnimplementedOHLCVServiceServer() {}
 
 // UnsafeOHLCVServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -242,6 +395,123 @@ func _OHLCVService_GetTicker_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OHLCVService_SubscribeCandles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeCandlesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OHLCVServiceServer).SubscribeCandles(m, &oHLCVServiceSubscribeCandlesServer{stream})
+}
+
+type OHLCVService_SubscribeCandlesServer interface {
+	Send(*SubscribeCandlesResponse) error
+	grpc.ServerStream
+}
+
+type oHLCVServiceSubscribeCandlesServer struct {
+	grpc.ServerStream
+}
+
+func (x *oHLCVServiceSubscribeCandlesServer) Send(m *SubscribeCandlesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OHLCVService_SubscribeTickers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTickersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OHLCVServiceServer).SubscribeTickers(m, &oHLCVServiceSubscribeTickersServer{stream})
+}
+
+type OHLCVService_SubscribeTickersServer interface {
+	Send(*SubscribeTickersResponse) error
+	grpc.ServerStream
+}
+
+type oHLCVServiceSubscribeTickersServer struct {
+	grpc.ServerStream
+}
+
+func (x *oHLCVServiceSubscribeTickersServer) Send(m *SubscribeTickersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OHLCVService_GetWindowTicker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWindowTickerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OHLCVServiceServer).GetWindowTicker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ohlcv.OHLCVService/GetWindowTicker",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OHLCVServiceServer).GetWindowTicker(ctx, req.(*GetWindowTickerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OHLCVService_GetKlines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKlinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OHLCVServiceServer).GetKlines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ohlcv.OHLCVService/GetKlines",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OHLCVServiceServer).GetKlines(ctx, req.(*GetKlinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OHLCVService_GetHistoricalTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoricalTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OHLCVServiceServer).GetHistoricalTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ohlcv.OHLCVService/GetHistoricalTrades",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OHLCVServiceServer).GetHistoricalTrades(ctx, req.(*GetHistoricalTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OHLCVService_SubscribeAggTrades_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeAggTradesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OHLCVServiceServer).SubscribeAggTrades(m, &oHLCVServiceSubscribeAggTradesServer{stream})
+}
+
+type OHLCVService_SubscribeAggTradesServer interface {
+	Send(*AggTrade) error
+	grpc.ServerStream
+}
+
+type oHLCVServiceSubscribeAggTradesServer struct {
+	grpc.ServerStream
+}
+
+func (x *oHLCVServiceSubscribeAggTradesServer) Send(m *AggTrade) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // OHLCVService_ServiceDesc is the grpc.ServiceDesc for OHLCVService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -265,6 +535,18 @@ var OHLCVService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTicker",
 			Handler:    _OHLCVService_GetTicker_Handler,
 		},
+		{
+			MethodName: "GetWindowTicker",
+			Handler:    _OHLCVService_GetWindowTicker_Handler,
+		},
+		{
+			MethodName: "GetKlines",
+			Handler:    _OHLCVService_GetKlines_Handler,
+		},
+		{
+			MethodName: "GetHistoricalTrades",
+			Handler:    _OHLCVService_GetHistoricalTrades_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -272,6 +554,21 @@ var OHLCVService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _OHLCVService_SubscribeDeals_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeCandles",
+			Handler:       _OHLCVService_SubscribeCandles_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTickers",
+			Handler:       _OHLCVService_SubscribeTickers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeAggTrades",
+			Handler:       _OHLCVService_SubscribeAggTrades_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "ohlcv.proto",
 }
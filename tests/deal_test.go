@@ -3,8 +3,6 @@ package tests
 import (
 	"bitbucket.org/novatechnologies/ohlcv/client/market"
 	"bitbucket.org/novatechnologies/ohlcv/internal/model"
-	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
-	"bitbucket.org/novatechnologies/ohlcv/internal/service"
 	"context"
 	"fmt"
 	"log"
@@ -21,6 +19,7 @@ import (
 	"bitbucket.org/novatechnologies/ohlcv/domain"
 	"bitbucket.org/novatechnologies/ohlcv/infra"
 	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
+	"bitbucket.org/novatechnologies/ohlcv/infra/health"
 	"bitbucket.org/novatechnologies/ohlcv/infra/mongo"
 )
 
@@ -66,7 +65,7 @@ func TestSaveDeal(t *testing.T) {
 		conf.MongoDbConfig.DealCollectionName,
 	)
 
-	dealService := service.NewDeal(repository.NewDeal(dealCollection, getTestMarkets(), nil), getTestMarkets(), make(chan *model.Deal))
+	dealService := InitDealService(dealCollection, getTestMarkets())
 	market := "BTC-USDT"
 
 	d1 := &matcher.Deal{
@@ -119,6 +118,7 @@ func TestSaveDeal(t *testing.T) {
 		model.Candle5MResolution,
 		from,
 		to,
+		domain.ChartOptions{},
 	)
 	currentChart, _ := candleService.GetCurrentCandle(
 		ctx,
@@ -142,23 +142,23 @@ func TestDealGenerator(t *testing.T) {
 		conf.MongoDbConfig,
 		conf.MongoDbConfig.DealCollectionName,
 	)
-	dealService := service.NewDeal(repository.NewDeal(dealCollection, GetAvailableMarkets(), nil), GetAvailableMarkets(), make(chan *model.Deal))
+	markets := GetMarketRegistry()
+	dealService := InitDealService(dealCollection, markets)
 	candleService := InitCandleService(conf, dealCollection, eventsBroker)
+	klineService := InitKlineService(ctx, conf, mongoDbClient, dealCollection, markets)
 
-	server := http.NewServer(candleService, dealService, conf)
+	server := http.NewServer(candleService, dealService, klineService, markets, health.NewChecker(conf.HealthConfig.CheckTimeout), conf)
 	server.Start(ctx)
 
 	// shutdown
-	signalCh := make(chan os.Signal)
+	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt)
 
 	_ = <-signalCh
 }
 
-func getTestMarkets() map[string]string {
-	return map[string]string{
-		"string_with_something_id": "BTC/USDT",
-	}
+func getTestMarkets() *domain.MarketRegistry {
+	return domain.NewMarketRegistry([]market.Market{{ID: "string_with_something_id", Name: "BTC/USDT"}})
 }
 
 func Test_GetTickerPriceChangeStatistics(t *testing.T) {
@@ -173,7 +173,7 @@ func Test_GetTickerPriceChangeStatistics(t *testing.T) {
 		conf.MongoDbConfig,
 		conf.MongoDbConfig.DealCollectionName,
 	)
-	service := service.NewDeal(repository.NewDeal(dealCollection, getTestMarkets(), nil), getTestMarkets(), make(chan *model.Deal))
+	service := InitDealService(dealCollection, getTestMarkets())
 	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*15)
 	defer cancelFunc()
 	statistics, err := service.GetTickerPriceChangeStatistics(ctx, "")
@@ -197,7 +197,7 @@ func Test_GetLastTrades(t *testing.T) {
 		conf.MongoDbConfig,
 		conf.MongoDbConfig.DealCollectionName,
 	)
-	dealService := service.NewDeal(repository.NewDeal(dealCollection, getTestMarkets(), nil), getTestMarkets(), make(chan *model.Deal))
+	dealService := InitDealService(dealCollection, getTestMarkets())
 	trades, err := dealService.GetLastTrades(ctx, "ETH/LTC", 10)
 	require.NoError(t, err)
 	assert.Len(t, trades, 10)
@@ -234,7 +234,7 @@ func Test_GetAvgPrice(t *testing.T) {
 		conf.MongoDbConfig,
 		conf.MongoDbConfig.DealCollectionName,
 	)
-	dealService := service.NewDeal(repository.NewDeal(dealCollection, getTestMarkets(), buildAvailableMarkets(conf)), getTestMarkets(), make(chan *model.Deal))
+	dealService := InitDealService(dealCollection, domain.NewMarketRegistry(buildAvailableMarkets(conf)))
 	avg, err := dealService.GetAvgPrice(ctx, time.Hour*24*40, "ETH_TRX")
 	require.NoError(t, err)
 	fmt.Println(avg)
@@ -176,7 +176,7 @@ type candlesIntegrationTestSuite struct {
 	mongoCli        *mg.Client
 	dealsCollection *mg.Collection
 
-	kafkaConsumer  pubsub.Subscriber
+	kafkaConsumer  *infra.Consumer
 	kafkaPublisher pubsub.Publisher
 
 	deals      *repository.Deal
@@ -194,7 +194,10 @@ type candlesIntegrationTestSuite struct {
 func (suite *candlesIntegrationTestSuite) setupServicesUnderTests(
 	ctx context.Context, conf infra.Config,
 ) (err error) {
-	suite.kafkaConsumer = infra.NewConsumer(ctx, conf.KafkaConfig)
+	suite.kafkaConsumer, err = infra.NewConsumer(ctx, conf.KafkaConfig)
+	if err != nil {
+		return err
+	}
 	suite.kafkaPublisher, err = infra.NewPublisher(ctx, conf.KafkaConfig)
 	if err != nil {
 		return err
@@ -216,14 +219,14 @@ func (suite *candlesIntegrationTestSuite) setupServicesUnderTests(
 
 	// Deals service setup
 	suite.dealsTopic = conf.KafkaConfig.TopicPrefix + "_" + topics.MatcherMDDeals
-	suite.deals = repository.NewDeal(dealsCollection, GetAvailableMarkets(), nil)
+	suite.deals = repository.NewDeal(dealsCollection, GetMarketRegistry())
 
 	// Candles service setup
 	suite.candles = candle.NewService(&candle.Storage{DealsDbCollection: dealsCollection}, new(candle.Aggregator), eventsBroker)
 
 	// WS publisher and broadcaster of the market data setup
 	suite.wsPub = cfge.NewPublisher(conf.CentrifugeConfig)
-	broadcaster := cfge.NewBroadcaster(suite.wsPub, eventsBroker, nil)
+	broadcaster := cfge.NewBroadcaster(suite.wsPub, eventsBroker, GetMarketRegistry())
 	broadcaster.SubscribeForCharts()
 	suite.broadcaster = broadcaster
 
@@ -1,13 +1,21 @@
 package tests
 
 import (
+	"context"
+
 	mg "go.mongodb.org/mongo-driver/mongo"
 
 	"bitbucket.org/novatechnologies/ohlcv/candle"
+	"bitbucket.org/novatechnologies/ohlcv/client/market"
 	"bitbucket.org/novatechnologies/ohlcv/domain"
 	"bitbucket.org/novatechnologies/ohlcv/infra"
 	"bitbucket.org/novatechnologies/ohlcv/infra/broker"
 	"bitbucket.org/novatechnologies/ohlcv/infra/centrifuge"
+	"bitbucket.org/novatechnologies/ohlcv/infra/mongo"
+	"bitbucket.org/novatechnologies/ohlcv/internal/consumer"
+	"bitbucket.org/novatechnologies/ohlcv/internal/model"
+	"bitbucket.org/novatechnologies/ohlcv/internal/repository"
+	"bitbucket.org/novatechnologies/ohlcv/internal/service"
 )
 
 func InitCandleService(
@@ -15,12 +23,46 @@ func InitCandleService(
 	dealsCollection *mg.Collection,
 	eventsBroker domain.EventsBroker,
 ) *candle.Service {
-	broadcaster := centrifuge.NewBroadcaster(centrifuge.NewPublisher(conf.CentrifugeConfig), eventsBroker, GetAvailableMarkets())
+	broadcaster := centrifuge.NewBroadcaster(centrifuge.NewPublisher(conf.CentrifugeConfig), eventsBroker, GetMarketRegistry())
 	broadcaster.SubscribeForCharts()
 
 	return candle.NewService(&candle.Storage{DealsDbCollection: dealsCollection}, new(candle.Aggregator), broker.NewInMemory())
 }
 
+func InitDealService(dealsCollection *mg.Collection, markets *domain.MarketRegistry) *service.Deal {
+	tickerCache := consumer.NewTicker(markets, broker.NewInMemory())
+	return service.NewDeal(repository.NewDeal(dealsCollection, markets), tickerCache, markets, make(chan *model.Deal))
+}
+
+func InitKlineService(
+	ctx context.Context,
+	conf infra.Config,
+	mongoDbClient *mg.Client,
+	dealsCollection *mg.Collection,
+	markets *domain.MarketRegistry,
+) *service.Kline {
+	collection := func(name string) *mg.Collection {
+		return mongo.GetCollection(ctx, mongoDbClient, conf.MongoDbConfig, name)
+	}
+	return service.NewKline(
+		repository.NewKline(dealsCollection),
+		repository.NewMaterializedKline(
+			collection(conf.MongoDbConfig.MinuteKlineCollectionName),
+			collection(conf.MongoDbConfig.HourKlineCollectionName),
+			collection(conf.MongoDbConfig.KlineStateCollectionName),
+		),
+		consumer.NewTicker(markets, broker.NewInMemory()),
+	)
+}
+
+func GetMarketRegistry() *domain.MarketRegistry {
+	markets := make([]market.Market, 0)
+	for id, name := range GetAvailableMarkets() {
+		markets = append(markets, market.Market{ID: id, Name: name})
+	}
+	return domain.NewMarketRegistry(markets)
+}
+
 func GetAvailableMarkets() map[string]string {
 	return map[string]string{
 		"42e98b73-ec0c-4185-b0db-ffc8610f5741": "BTC_XRP",